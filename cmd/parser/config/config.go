@@ -4,16 +4,57 @@ import "time"
 
 // Config holds application configuration.
 type Config struct {
-	DatabaseURL string        `env:"DATABASE_URL"`
-	BatchSize   uint          `env:"BATCH_SIZE" envDefault:"50"`
-	HTTPTimeout time.Duration `env:"HTTP_TIMEOUT" envDefault:"10s"`
+	DatabaseURL              string        `env:"DATABASE_URL"`
+	BatchSize                uint          `env:"BATCH_SIZE" envDefault:"50"`
+	StorageWorkers           int           `env:"STORAGE_WORKERS" envDefault:"1"`
+	BulkLoading              bool          `env:"BULK_LOADING" envDefault:"false"`
+	RemovalAvailabilities    []string      `env:"REMOVAL_AVAILABILITIES" envDefault:"removed"`
+	MaxDeletionPercent       float64       `env:"MAX_DELETION_PERCENT" envDefault:"0"`
+	MaxFailedProducts        int           `env:"MAX_FAILED_PRODUCTS" envDefault:"0"`
+	MaxFailureRatio          float64       `env:"MAX_FAILURE_RATIO" envDefault:"0"`
+	ParseTimeout             time.Duration `env:"PARSE_TIMEOUT" envDefault:"0"`
+	RunLease                 time.Duration `env:"RUN_LEASE" envDefault:"5m"`
+	RunLeaseRenewal          time.Duration `env:"RUN_LEASE_RENEWAL_INTERVAL" envDefault:"30s"`
+	OutboxInterval           time.Duration `env:"OUTBOX_INTERVAL" envDefault:"1s"`
+	OutboxBatchSize          int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	RetentionInterval        time.Duration `env:"RETENTION_INTERVAL" envDefault:"1h"`
+	RetentionBatchSize       int           `env:"RETENTION_BATCH_SIZE" envDefault:"500"`
+	DeletedProductsRetention time.Duration `env:"DELETED_PRODUCTS_RETENTION" envDefault:"0"`
+	RunsRetention            time.Duration `env:"RUNS_RETENTION" envDefault:"0"`
+	KeptRuns                 int           `env:"KEPT_RUNS" envDefault:"10"`
+	SchedulerEnabled         bool          `env:"SCHEDULER_ENABLED" envDefault:"false"`
+	SchedulerInterval        time.Duration `env:"SCHEDULER_INTERVAL" envDefault:"1m"`
+	SchedulerBatchSize       int           `env:"SCHEDULER_BATCH_SIZE" envDefault:"100"`
+	SchedulerJitter          time.Duration `env:"SCHEDULER_JITTER" envDefault:"0"`
+	APIEnabled               bool          `env:"API_ENABLED" envDefault:"false"`
+	APIAddr                  string        `env:"API_ADDR" envDefault:"127.0.0.1:8080"`
+	APIToken                 string        `env:"API_TOKEN"`
 
+	HTTP     HTTP
 	RabbitMQ RabbitMQ
 }
 
+// HTTP holds configuration of http client used for fetching feed files.
+type HTTP struct {
+	Timeout              time.Duration `env:"HTTP_TIMEOUT" envDefault:"10s"`
+	ConnectTimeout       time.Duration `env:"HTTP_CONNECT_TIMEOUT" envDefault:"5s"`
+	MaxRedirects         int           `env:"HTTP_MAX_REDIRECTS" envDefault:"10"`
+	AllowHTTPSDowngrade  bool          `env:"HTTP_ALLOW_HTTPS_DOWNGRADE" envDefault:"false"`
+	ProxyURL             string        `env:"HTTP_PROXY_URL"`
+	CACertFile           string        `env:"HTTP_CA_CERT_FILE"`
+	ClientCertFile       string        `env:"HTTP_CLIENT_CERT_FILE"`
+	ClientKeyFile        string        `env:"HTTP_CLIENT_KEY_FILE"`
+	BlockPrivateNetworks bool          `env:"HTTP_BLOCK_PRIVATE_NETWORKS" envDefault:"true"`
+	AllowedHosts         []string      `env:"HTTP_ALLOWED_HOSTS"`
+}
+
 // RabbitMQ holds RabbitMQ configuration.
 type RabbitMQ struct {
-	URL      string `env:"RABBITMQ_URL"`
-	Exchange string `env:"RABBITMQ_EXCHANGE" envDefault:"gfp-ex"`
-	Queue    string `env:"RABBITMQ_QUEUE" envDefault:"google-feed-parser.commands"`
+	URL                    string `env:"RABBITMQ_URL"`
+	Exchange               string `env:"RABBITMQ_EXCHANGE" envDefault:"gfp-ex"`
+	Queue                  string `env:"RABBITMQ_QUEUE" envDefault:"google-feed-parser.commands"`
+	CommandsRoutingKey     string `env:"RABBITMQ_COMMANDS_ROUTING_KEY" envDefault:"google-feed-parser.commands"`
+	CancelRoutingKey       string `env:"RABBITMQ_CANCEL_ROUTING_KEY" envDefault:"google-feed-parser.cancel"`
+	EventsRoutingKeyPrefix string `env:"RABBITMQ_EVENTS_ROUTING_KEY_PREFIX" envDefault:"google-feed-parser.events."`
+	RunEventsRoutingKey    string `env:"RABBITMQ_RUN_EVENTS_ROUTING_KEY" envDefault:"google-feed-parser.events.run"`
 }
@@ -3,19 +3,26 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/MichalMitros/google-feed-parser/cmd/parser/config"
+	"github.com/MichalMitros/google-feed-parser/internal/api"
 	"github.com/MichalMitros/google-feed-parser/internal/decoder"
 	"github.com/MichalMitros/google-feed-parser/internal/fetcher"
 	"github.com/MichalMitros/google-feed-parser/internal/handler"
+	"github.com/MichalMitros/google-feed-parser/internal/outbox"
 	"github.com/MichalMitros/google-feed-parser/internal/parser"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/rabbitmq"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/storage"
+	"github.com/MichalMitros/google-feed-parser/internal/retention"
+	"github.com/MichalMitros/google-feed-parser/internal/scheduler"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
 	"github.com/caarlos0/env/v6"
 	_ "github.com/lib/pq"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -25,6 +32,10 @@ import (
 const (
 	// UserAgent is user agent header value used when fetching feed file.
 	UserAgent = "google-feed-parser/0.0.1"
+	// apiReadHeaderTimeout is maximum duration of reading admin API request headers.
+	apiReadHeaderTimeout = 10 * time.Second
+	// apiShutdownTimeout is maximum duration of waiting for admin API requests during shutdown.
+	apiShutdownTimeout = 10 * time.Second
 )
 
 func main() {
@@ -39,6 +50,11 @@ func main() {
 			Msg("can't parse env variables")
 	}
 
+	if cfg.APIEnabled && cfg.APIToken == "" {
+		logger.Fatal().
+			Msg("API_TOKEN is required when admin API is enabled")
+	}
+
 	amqpConnection, err := amqp.Dial(cfg.RabbitMQ.URL)
 	if err != nil {
 		logger.Fatal().
@@ -60,11 +76,43 @@ func main() {
 			Msg("can't open Postgres connection")
 	}
 
+	httpClient, err := fetcher.NewClient(fetcher.ClientConfig{
+		Timeout:              cfg.HTTP.Timeout,
+		ConnectTimeout:       cfg.HTTP.ConnectTimeout,
+		MaxRedirects:         cfg.HTTP.MaxRedirects,
+		AllowHTTPSDowngrade:  cfg.HTTP.AllowHTTPSDowngrade,
+		ProxyURL:             cfg.HTTP.ProxyURL,
+		CACertFile:           cfg.HTTP.CACertFile,
+		ClientCertFile:       cfg.HTTP.ClientCertFile,
+		ClientKeyFile:        cfg.HTTP.ClientKeyFile,
+		BlockPrivateNetworks: cfg.HTTP.BlockPrivateNetworks,
+		AllowedHosts:         cfg.HTTP.AllowedHosts,
+	})
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Msg("can't create http client")
+	}
+
+	storageOps := []storage.Option{storage.WithRunLease(cfg.RunLease)}
+	if cfg.BulkLoading {
+		storageOps = append(storageOps, storage.WithBulkLoading())
+	}
+	pgStorage := storage.NewPostgres(pgDB, storageOps...)
+
+	xmlDecoder := &decoder.Decoder{}
 	par := parser.NewParser(
-		fetcher.NewFetcher(&http.Client{Timeout: cfg.HTTPTimeout}, UserAgent),
-		&decoder.Decoder{},
-		storage.NewPostgres(pgDB),
+		fetcher.NewFetcher(httpClient, UserAgent),
+		xmlDecoder,
+		pgStorage,
 		cfg.BatchSize,
+		parser.WithRemovalAvailabilities(cfg.RemovalAvailabilities...),
+		parser.WithMaxDeletionPercent(cfg.MaxDeletionPercent),
+		parser.WithMaxFailures(cfg.MaxFailedProducts, cfg.MaxFailureRatio),
+		parser.WithLeaseRenewalInterval(cfg.RunLeaseRenewal),
+		parser.WithStorageWorkers(cfg.StorageWorkers),
+		parser.WithParseTimeout(cfg.ParseTimeout),
+		parser.WithDecoder("xml", xmlDecoder),
 	)
 
 	han := handler.NewHandler(conn, par, &logger)
@@ -77,6 +125,103 @@ func main() {
 			Msg("can't start consuming")
 	}
 
+	cancelConn, err := rabbitmq.NewRabbitMQ(amqpConnection, cfg.RabbitMQ.Exchange)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Msg("can't open RabbitMQ connection")
+	}
+
+	// start consuming and handling cancel commands
+	err = han.StartCancelling(ctx, cancelConn, cfg.RabbitMQ.CancelRoutingKey)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Msg("can't start consuming cancel commands")
+	}
+
+	eventsConn, err := rabbitmq.NewRabbitMQ(amqpConnection, cfg.RabbitMQ.Exchange)
+	if err != nil {
+		logger.Fatal().
+			Err(err).
+			Msg("can't open RabbitMQ connection")
+	}
+
+	// start publishing events saved in outbox
+	relay := outbox.NewRelay(
+		pgStorage,
+		eventsConn,
+		&logger,
+		outbox.WithInterval(cfg.OutboxInterval),
+		outbox.WithBatchSize(cfg.OutboxBatchSize),
+		outbox.WithRoutingKeyPrefix(cfg.RabbitMQ.EventsRoutingKeyPrefix),
+		outbox.WithRoutingKey("run.", cfg.RabbitMQ.RunEventsRoutingKey),
+	)
+	go relay.Run(ctx)
+
+	// start purging expired products and runs
+	purger := retention.NewPurger(
+		pgStorage,
+		&logger,
+		retention.WithInterval(cfg.RetentionInterval),
+		retention.WithSweepInterval(cfg.RunLease),
+		retention.WithBatchSize(cfg.RetentionBatchSize),
+		retention.WithDeletedProductsRetention(cfg.DeletedProductsRetention),
+		retention.WithRunsRetention(cfg.RunsRetention, cfg.KeptRuns),
+	)
+	go purger.Run(ctx)
+
+	if cfg.SchedulerEnabled {
+		schedulerConn, err := rabbitmq.NewRabbitMQ(amqpConnection, cfg.RabbitMQ.Exchange)
+		if err != nil {
+			logger.Fatal().
+				Err(err).
+				Msg("can't open RabbitMQ connection")
+		}
+
+		// start sending parse commands of scheduled shops
+		sch := scheduler.NewScheduler(
+			pgStorage,
+			commander.NewParseCommander(commander.NewRabbitMQSender(schedulerConn, cfg.RabbitMQ.CommandsRoutingKey)),
+			&logger,
+			scheduler.WithInterval(cfg.SchedulerInterval),
+			scheduler.WithBatchSize(cfg.SchedulerBatchSize),
+			scheduler.WithJitter(cfg.SchedulerJitter),
+		)
+		go sch.Run(ctx)
+	}
+
+	var apiServer *http.Server
+	if cfg.APIEnabled {
+		apiConn, err := rabbitmq.NewRabbitMQ(amqpConnection, cfg.RabbitMQ.Exchange)
+		if err != nil {
+			logger.Fatal().
+				Err(err).
+				Msg("can't open RabbitMQ connection")
+		}
+
+		// start serving admin API
+		apiServer = &http.Server{
+			Addr: cfg.APIAddr,
+			Handler: api.NewServer(
+				pgStorage,
+				commander.NewParseCommander(commander.NewRabbitMQSender(apiConn, cfg.RabbitMQ.CommandsRoutingKey)),
+				commander.NewCancelCommander(commander.NewRabbitMQSender(apiConn, cfg.RabbitMQ.CancelRoutingKey)),
+				cfg.APIToken,
+				&logger,
+			),
+			ReadHeaderTimeout: apiReadHeaderTimeout,
+		}
+
+		go func() {
+			if err := apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal().
+					Err(err).
+					Msg("can't serve admin API")
+			}
+		}()
+	}
+
 	logger.Info().Msg("feed parser up and running")
 
 	// handle graceful shutdown and context cancellation
@@ -90,6 +235,16 @@ func main() {
 
 	logger.Info().Msg("graceful shutdown start")
 
+	if apiServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), apiShutdownTimeout)
+		if err := apiServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error().
+				Err(err).
+				Msg("can't shutdown admin API")
+		}
+		cancelShutdown()
+	}
+
 	// wait for consumer to finish
 	<-conn.Done()
 
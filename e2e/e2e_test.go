@@ -178,7 +178,9 @@ func (s *E2ETestSuite) TestFeedParsing() {
 	dbProducts = helpers.GetProducts(s.T(), s.db, shopURL)
 
 	s.Equal(int32(20), *secondRun.CreatedProducts, "should return correct number of created products")
-	s.Equal(int32(15), *secondRun.UpdatedProducts, "should return correct number of updated products")
+	// products present in both feeds didn't change, so they only get new version.
+	s.Equal(int32(0), *secondRun.UpdatedProducts, "should return correct number of updated products")
+	s.Equal(int32(15), *secondRun.UnchangedProducts, "should return correct number of unchanged products")
 	s.Equal(int32(10), *secondRun.DeletedProducts, "should return correct number of deleted products")
 	s.Equal(int32(0), *secondRun.FailedProducts, "should return correct number of failed products")
 	assertLogsMessages(s.T(), []string{"parsing started", "parsing finished", "parsing started", "parsing finished"}, logs)
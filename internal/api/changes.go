@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
+)
+
+// getProductChanges lists shop's products created, updated or deleted since products version from `since`
+// query parameter or since products version of the run from `runId` query parameter, all changes are listed
+// if neither is set. Reading is resumed from `cursor` query parameter, which is returned with every page.
+func (s *Server) getProductChanges(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	limit, err := limitFromRequest(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	cursor, ok := s.changesCursorFromRequest(w, r, shop.ID)
+	if !ok {
+		return
+	}
+
+	changes, err := s.storage.GetProductChanges(r.Context(), shop.ID, cursor, limit)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toChangesResponse(changes, limit, cursor))
+}
+
+// changesCursorFromRequest returns changes cursor from `cursor`, `since` or `runId` query parameter,
+// only one of them can be set. If it can't be read, error response is written.
+func (s *Server) changesCursorFromRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	shopID int,
+) (models.ChangesCursor, bool) {
+	query := r.URL.Query()
+	cursor, since, runID := query.Get("cursor"), query.Get("since"), query.Get("runId")
+
+	if len(lo.Compact([]string{cursor, since, runID})) > 1 {
+		s.writeError(w, fmt.Errorf("%w: only one of cursor, since and runId can be set", ErrInvalidRequest))
+		return models.ChangesCursor{}, false
+	}
+
+	switch {
+	case cursor != "":
+		changesCursor, err := parseChangesCursor(cursor)
+		if err != nil {
+			s.writeError(w, err)
+			return models.ChangesCursor{}, false
+		}
+
+		return changesCursor, true
+	case since != "":
+		version, err := strconv.ParseInt(since, 10, 64)
+		if err != nil || version < 0 {
+			s.writeError(w, fmt.Errorf("%w: invalid since %q", ErrInvalidRequest, since))
+			return models.ChangesCursor{}, false
+		}
+
+		return models.ChangesCursor{SinceVersion: version, Version: version}, true
+	case runID != "":
+		id, err := strconv.Atoi(runID)
+		if err != nil || id <= 0 {
+			s.writeError(w, fmt.Errorf("%w: invalid runId %q", ErrInvalidRequest, runID))
+			return models.ChangesCursor{}, false
+		}
+
+		run, err := s.storage.GetRun(r.Context(), id)
+		if err == nil && run.ShopID != shopID {
+			err = platform.ErrRunNotFound
+		}
+
+		if err != nil {
+			s.writeError(w, err)
+			return models.ChangesCursor{}, false
+		}
+
+		return models.ChangesCursor{SinceVersion: run.ProductsVersion, Version: run.ProductsVersion}, true
+	default:
+		return models.ChangesCursor{}, true
+	}
+}
+
+// formatChangesCursor formats cursor as `<since version>.<version>.<product ID>`.
+func formatChangesCursor(cursor models.ChangesCursor) string {
+	return fmt.Sprintf("%d.%d.%d", cursor.SinceVersion, cursor.Version, cursor.ID)
+}
+
+// parseChangesCursor parses cursor formatted with formatChangesCursor.
+func parseChangesCursor(value string) (models.ChangesCursor, error) {
+	invalidErr := fmt.Errorf("%w: invalid cursor %q", ErrInvalidRequest, value)
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return models.ChangesCursor{}, invalidErr
+	}
+
+	since, sinceErr := strconv.ParseInt(parts[0], 10, 64)
+	version, versionErr := strconv.ParseInt(parts[1], 10, 64)
+	id, idErr := strconv.Atoi(parts[2])
+	if sinceErr != nil || versionErr != nil || idErr != nil || since < 0 || version < since || id < 0 {
+		return models.ChangesCursor{}, invalidErr
+	}
+
+	return models.ChangesCursor{SinceVersion: since, Version: version, ID: id}, nil
+}
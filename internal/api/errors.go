@@ -0,0 +1,10 @@
+package api
+
+import "errors"
+
+var (
+	// ErrInvalidRequest is returned when request's parameters or body are invalid.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUnauthorized is returned when request doesn't have valid bearer token.
+	ErrUnauthorized = errors.New("unauthorized")
+)
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+)
+
+// getShopProducts lists shop's products matching filter from query parameters with their shippings,
+// cursor is ID of the last product of previous page. See productFilterFromRequest for supported filters.
+func (s *Server) getShopProducts(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := pageFromRequest(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	filter, err := productFilterFromRequest(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	products, err := s.storage.GetShopProducts(r.Context(), shop.ID, filter, p.cursor, p.limit)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toPageResponse(products, p.limit, toProductResponse, func(product *models.Product) int {
+		return product.ID
+	}))
+}
+
+// productFilterFromRequest reads products filter from `availability`, `brand`, `category`, `minPrice`, `maxPrice`,
+// `updatedSince` (products version) and `deleted` query parameters. Deleted products are included if `deleted=true`.
+func productFilterFromRequest(r *http.Request) (models.ProductFilter, error) {
+	query := r.URL.Query()
+	filter := models.ProductFilter{
+		Availability:   queryString(query, "availability"),
+		Brand:          queryString(query, "brand"),
+		Category:       queryString(query, "category"),
+		IncludeDeleted: query.Get("deleted") == "true",
+	}
+
+	var err error
+	if filter.MinPrice, err = queryPrice(query, "minPrice"); err != nil {
+		return models.ProductFilter{}, err
+	}
+
+	if filter.MaxPrice, err = queryPrice(query, "maxPrice"); err != nil {
+		return models.ProductFilter{}, err
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return models.ProductFilter{}, fmt.Errorf("%w: minPrice can't be greater than maxPrice", ErrInvalidRequest)
+	}
+
+	if updatedSince := query.Get("updatedSince"); updatedSince != "" {
+		value, err := strconv.ParseInt(updatedSince, 10, 64)
+		if err != nil || value < 0 {
+			return models.ProductFilter{}, fmt.Errorf("%w: invalid updatedSince %q", ErrInvalidRequest, updatedSince)
+		}
+
+		filter.UpdatedSince = &value
+	}
+
+	return filter, nil
+}
+
+// queryString returns value of query parameter, nil if it's not set or empty.
+func queryString(query url.Values, name string) *string {
+	value := query.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// queryPrice reads non-negative price amount from query parameter, nil if it's not set.
+func queryPrice(query url.Values, name string) (*float64, error) {
+	price := query.Get(name)
+	if price == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(price, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, fmt.Errorf("%w: invalid %s %q", ErrInvalidRequest, name, price)
+	}
+
+	return &value, nil
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// pageResponse is page of items, NextCursor is set if there may be more items.
+type pageResponse[T any] struct {
+	Items      []T  `json:"items"`
+	NextCursor *int `json:"nextCursor,omitempty"`
+}
+
+// changesResponse is page of product changes. NextCursor is always set, so reading can be resumed from it
+// later, and HasMore is set if there may be more changes.
+type changesResponse struct {
+	Items      []changeResponse `json:"items"`
+	NextCursor string           `json:"nextCursor"`
+	HasMore    bool             `json:"hasMore"`
+}
+
+type changeResponse struct {
+	Type    string          `json:"type"`
+	Version int64           `json:"version"`
+	Product productResponse `json:"product"`
+}
+
+type shopResponse struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	URL       string     `json:"url"`
+	Disabled  bool       `json:"disabled"`
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type productChangeResponse struct {
+	ProductID     string   `json:"productId"`
+	Type          string   `json:"type"`
+	ChangedFields []string `json:"changedFields,omitempty"`
+}
+
+type runResponse struct {
+	ID                int                     `json:"id"`
+	ShopID            int                     `json:"shopId"`
+	CreatedAt         time.Time               `json:"createdAt"`
+	FinishedAt        *time.Time              `json:"finishedAt,omitempty"`
+	Status            string                  `json:"status"`
+	StatusMessage     *string                 `json:"statusMessage,omitempty"`
+	IsIncremental     bool                    `json:"isIncremental"`
+	IsDryRun          bool                    `json:"isDryRun"`
+	ProductsVersion   int64                   `json:"productsVersion"`
+	CreatedProducts   *int32                  `json:"createdProducts,omitempty"`
+	UpdatedProducts   *int32                  `json:"updatedProducts,omitempty"`
+	UnchangedProducts *int32                  `json:"unchangedProducts,omitempty"`
+	DeletedProducts   *int32                  `json:"deletedProducts,omitempty"`
+	FailedProducts    *int32                  `json:"failedProducts,omitempty"`
+	ChangesSample     []productChangeResponse `json:"changesSample,omitempty"`
+}
+
+type shippingResponse struct {
+	Country string `json:"country"`
+	Service string `json:"service"`
+	Price   string `json:"price"`
+}
+
+type productResponse struct {
+	ID                  int                `json:"id"`
+	ProductID           string             `json:"productId"`
+	Version             int64              `json:"version"`
+	CreatedAt           time.Time          `json:"createdAt"`
+	DeletedAt           *time.Time         `json:"deletedAt,omitempty"`
+	Title               string             `json:"title"`
+	Description         string             `json:"description"`
+	URL                 string             `json:"url"`
+	ImageURL            string             `json:"imageUrl"`
+	AdditionalImageURLs []string           `json:"additionalImageUrls,omitempty"`
+	Condition           string             `json:"condition"`
+	Availability        string             `json:"availability"`
+	Price               string             `json:"price"`
+	SalePrice           *string            `json:"salePrice,omitempty"`
+	Brand               *string            `json:"brand,omitempty"`
+	GTIN                *string            `json:"gtin,omitempty"`
+	MPN                 *string            `json:"mpn,omitempty"`
+	ProductCategory     *string            `json:"productCategory,omitempty"`
+	ProductType         *string            `json:"productType,omitempty"`
+	Color               *string            `json:"color,omitempty"`
+	Size                *string            `json:"size,omitempty"`
+	ItemGroupID         *string            `json:"itemGroupId,omitempty"`
+	Gender              *string            `json:"gender,omitempty"`
+	AgeGroup            *string            `json:"ageGroup,omitempty"`
+	Shippings           []shippingResponse `json:"shippings"`
+}
+
+func toShopResponse(shop *models.Shop) shopResponse {
+	return shopResponse{
+		ID:        shop.ID,
+		Name:      shop.Name,
+		URL:       shop.URL,
+		Disabled:  shop.Disabled,
+		CreatedAt: shop.CreatedAt,
+		DeletedAt: shop.DeletedAt,
+	}
+}
+
+func toRunResponse(run *models.Run) runResponse {
+	return runResponse{
+		ID:                run.ID,
+		ShopID:            run.ShopID,
+		CreatedAt:         run.CreatedAt,
+		FinishedAt:        run.FinishedAt,
+		Status:            string(run.Status),
+		StatusMessage:     run.StatusMessage,
+		IsIncremental:     run.IsIncremental,
+		IsDryRun:          run.IsDryRun,
+		ProductsVersion:   run.ProductsVersion,
+		CreatedProducts:   run.CreatedProducts,
+		UpdatedProducts:   run.UpdatedProducts,
+		UnchangedProducts: run.UnchangedProducts,
+		DeletedProducts:   run.DeletedProducts,
+		FailedProducts:    run.FailedProducts,
+		ChangesSample: lo.Map(run.ChangesSample, func(change models.ProductChange, _ int) productChangeResponse {
+			return productChangeResponse{
+				ProductID:     change.ProductID,
+				Type:          string(change.Type),
+				ChangedFields: change.ChangedFields,
+			}
+		}),
+	}
+}
+
+func toProductResponse(product *models.Product) productResponse {
+	return productResponse{
+		ID:                  product.ID,
+		ProductID:           product.ProductID,
+		Version:             product.Version,
+		CreatedAt:           product.CreatedAt,
+		DeletedAt:           product.DeletedAt,
+		Title:               product.Title,
+		Description:         product.Description,
+		URL:                 product.URL,
+		ImageURL:            product.ImageURL,
+		AdditionalImageURLs: product.AdditionalImageURLs,
+		Condition:           product.Condition,
+		Availability:        product.Availability,
+		Price:               product.Price,
+		SalePrice:           product.SalePrice,
+		Brand:               product.Brand,
+		GTIN:                product.GTIN,
+		MPN:                 product.MPN,
+		ProductCategory:     product.ProductCategory,
+		ProductType:         product.ProductType,
+		Color:               product.Color,
+		Size:                product.Size,
+		ItemGroupID:         product.ItemGroupID,
+		Gender:              product.Gender,
+		AgeGroup:            product.AgeGroup,
+		Shippings: lo.Map(product.Shippings, func(shipping models.Shipping, _ int) shippingResponse {
+			return shippingResponse{
+				Country: shipping.Country,
+				Service: shipping.Service,
+				Price:   shipping.Price,
+			}
+		}),
+	}
+}
+
+// toPageResponse converts items into page, next cursor is set to cursor of the last item if the page is full.
+func toPageResponse[M, T any](items []M, limit int, convert func(item *M) T, cursor func(item *M) int) pageResponse[T] {
+	response := pageResponse[T]{Items: make([]T, 0, len(items))}
+	for ix := range items {
+		response.Items = append(response.Items, convert(&items[ix]))
+	}
+
+	if len(items) > 0 && len(items) == limit {
+		response.NextCursor = lo.ToPtr(cursor(&items[len(items)-1]))
+	}
+
+	return response
+}
+
+// toChangesResponse converts changes read from cursor into page, next cursor is cursor of the last change
+// or provided cursor if there are no changes.
+func toChangesResponse(changes []models.ChangedProduct, limit int, cursor models.ChangesCursor) changesResponse {
+	response := changesResponse{
+		Items:   make([]changeResponse, 0, len(changes)),
+		HasMore: len(changes) > 0 && len(changes) == limit,
+	}
+
+	for ix := range changes {
+		response.Items = append(response.Items, changeResponse{
+			Type:    string(changes[ix].Type),
+			Version: changes[ix].Version,
+			Product: toProductResponse(&changes[ix].Product),
+		})
+
+		cursor.Version = changes[ix].Version
+		cursor.ID = changes[ix].Product.ID
+	}
+
+	response.NextCursor = formatChangesCursor(cursor)
+
+	return response
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+)
+
+// getRuns lists shop's runs with their statistics from the newest one, cursor is ID of the last run of previous page.
+func (s *Server) getRuns(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := pageFromRequest(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	runs, err := s.storage.GetRuns(r.Context(), shop.ID, p.cursor, p.limit)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toPageResponse(runs, p.limit, toRunResponse, func(run *models.Run) int {
+		return run.ID
+	}))
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.runFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toRunResponse(run))
+}
+
+// cancelRun sends cancel command of running run. Cancellation is asynchronous,
+// so the run is finished with cancelled status shortly after.
+func (s *Server) cancelRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.runFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if run.Status != models.RunStatusRunning {
+		s.writeError(w, platform.ErrRunNotRunning)
+		return
+	}
+
+	if err := s.cancelCommander.SendCancelCommand(r.Context(), run.ID); err != nil {
+		s.writeError(w, fmt.Errorf("can't send cancel command: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// runFromPath returns run with ID from request's path. If it can't be read, error response is written.
+func (s *Server) runFromPath(w http.ResponseWriter, r *http.Request) (*models.Run, bool) {
+	runID, err := pathID(r, "runID")
+	if err != nil {
+		s.writeError(w, err)
+		return nil, false
+	}
+
+	run, err := s.storage.GetRun(r.Context(), runID)
+	if err != nil {
+		s.writeError(w, err)
+		return nil, false
+	}
+
+	return run, true
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultPageSize is default number of items returned in one page.
+	DefaultPageSize = 50
+	// MaxPageSize is maximum number of items returned in one page.
+	MaxPageSize = 500
+)
+
+//go:generate mockery --name Storage --filename storage.go
+
+// Storage stores shops, their runs and products.
+type Storage interface {
+	// GetShops returns all shops, deleted shops are returned only if includeDeleted is set.
+	GetShops(ctx context.Context, includeDeleted bool) ([]models.Shop, error)
+	// GetShop returns shop with provided ID, including deleted shop.
+	GetShop(ctx context.Context, shopID int) (*models.Shop, error)
+	// CreateShop inserts provided shop and sets its ID and creation time.
+	CreateShop(ctx context.Context, shop *models.Shop) error
+	// UpdateShop updates name, url and disabled flag of the shop.
+	UpdateShop(ctx context.Context, shop models.Shop) error
+	// GetRun returns run with provided ID.
+	GetRun(ctx context.Context, runID int) (*models.Run, error)
+	// GetRuns returns up to limit shop's runs older than the run with beforeID, from the newest one.
+	GetRuns(ctx context.Context, shopID int, beforeID int, limit int) ([]models.Run, error)
+	// GetShopProducts returns up to limit shop's products matching filter with ID greater than afterID.
+	GetShopProducts(
+		ctx context.Context,
+		shopID int,
+		filter models.ProductFilter,
+		afterID int,
+		limit int,
+	) ([]models.Product, error)
+	// GetProductChanges returns up to limit shop's products created, updated or deleted after the cursor.
+	GetProductChanges(
+		ctx context.Context,
+		shopID int,
+		cursor models.ChangesCursor,
+		limit int,
+	) ([]models.ChangedProduct, error)
+}
+
+// Server is HTTP admin API for managing shops, inspecting their runs and products and sending commands
+// to Parser service.
+type Server struct {
+	storage         Storage
+	parseCommander  commander.ParseCommander
+	cancelCommander commander.CancelCommander
+	token           string
+	logger          *zerolog.Logger
+	mux             *http.ServeMux
+}
+
+// NewServer returns new Server reading shops from storage and sending commands with provided commanders.
+// Every request must be authenticated with provided token as bearer token, all requests are rejected if it's empty.
+func NewServer(
+	storage Storage,
+	parseCommander commander.ParseCommander,
+	cancelCommander commander.CancelCommander,
+	token string,
+	logger *zerolog.Logger,
+) *Server {
+	server := &Server{
+		storage:         storage,
+		parseCommander:  parseCommander,
+		cancelCommander: cancelCommander,
+		token:           token,
+		logger:          logger,
+		mux:             http.NewServeMux(),
+	}
+
+	server.mux.HandleFunc("GET /shops", server.getShops)
+	server.mux.HandleFunc("POST /shops", server.createShop)
+	server.mux.HandleFunc("GET /shops/{shopID}", server.getShop)
+	server.mux.HandleFunc("PATCH /shops/{shopID}", server.updateShop)
+	server.mux.HandleFunc("POST /shops/{shopID}/parse", server.parseShop)
+	server.mux.HandleFunc("GET /shops/{shopID}/runs", server.getRuns)
+	server.mux.HandleFunc("GET /shops/{shopID}/products", server.getShopProducts)
+	server.mux.HandleFunc("GET /shops/{shopID}/changes", server.getProductChanges)
+	server.mux.HandleFunc("GET /runs/{runID}", server.getRun)
+	server.mux.HandleFunc("POST /runs/{runID}/cancel", server.cancelRun)
+
+	return server
+}
+
+// ServeHTTP handles API request authenticated with server's bearer token.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		s.writeError(w, ErrUnauthorized)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// authenticated returns true if request's bearer token is server's token.
+func (s *Server) authenticated(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || s.token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// page is requested page of items, items after the cursor are returned.
+type page struct {
+	cursor int
+	limit  int
+}
+
+// pageFromRequest reads page from `cursor` and `limit` query parameters.
+func pageFromRequest(r *http.Request) (page, error) {
+	var p page
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		value, err := strconv.Atoi(cursor)
+		if err != nil || value < 0 {
+			return page{}, fmt.Errorf("%w: invalid cursor %q", ErrInvalidRequest, cursor)
+		}
+
+		p.cursor = value
+	}
+
+	limit, err := limitFromRequest(r)
+	if err != nil {
+		return page{}, err
+	}
+
+	p.limit = limit
+
+	return p, nil
+}
+
+// limitFromRequest reads page size from `limit` query parameter, DefaultPageSize is returned if it's not set.
+func limitFromRequest(r *http.Request) (int, error) {
+	limit := r.URL.Query().Get("limit")
+	if limit == "" {
+		return DefaultPageSize, nil
+	}
+
+	value, err := strconv.Atoi(limit)
+	if err != nil || value <= 0 || value > MaxPageSize {
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxPageSize)
+	}
+
+	return value, nil
+}
+
+// pathID reads positive integer ID from request's path.
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidRequest, name, r.PathValue(name))
+	}
+
+	return id, nil
+}
+
+// decodeBody decodes JSON request body into dst, rejecting unknown fields.
+func decodeBody(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("%w: invalid body: %w", ErrInvalidRequest, err)
+	}
+
+	return nil
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Error().
+			Err(err).
+			Msg("can't write response")
+	}
+}
+
+// writeError writes error response with status matching the error.
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, platform.ErrShopNotFound), errors.Is(err, platform.ErrRunNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, platform.ErrShopURLTaken),
+		errors.Is(err, platform.ErrShopDeleted),
+		errors.Is(err, platform.ErrShopDisabled),
+		errors.Is(err, platform.ErrRunNotRunning):
+		status = http.StatusConflict
+	}
+
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		s.logger.Error().
+			Err(err).
+			Msg("can't handle API request")
+
+		message = http.StatusText(status)
+	}
+
+	s.writeJSON(w, status, errorResponse{Error: message})
+}
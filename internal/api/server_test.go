@@ -0,0 +1,655 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/api"
+	"github.com/MichalMitros/google-feed-parser/internal/api/mocks"
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	cmdmocks "github.com/MichalMitros/google-feed-parser/pkg/v1/commander/mocks"
+)
+
+var createdAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+const token = "secret-token"
+
+type apiTest struct {
+	method string
+	target string
+	body   string
+	// authorization replaces default authorization header with valid token.
+	authorization *string
+	setup         func(storage *mocks.Storage, parseSender, cancelSender *cmdmocks.Sender)
+	wantStatus    int
+	wantBody      string
+}
+
+func runAPITests(t *testing.T, tests map[string]apiTest) {
+	t.Helper()
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			parseSender := cmdmocks.NewSender(t)
+			cancelSender := cmdmocks.NewSender(t)
+			logger := zerolog.Nop()
+
+			if tt.setup != nil {
+				tt.setup(storage, parseSender, cancelSender)
+			}
+
+			server := api.NewServer(
+				storage,
+				commander.NewParseCommander(parseSender),
+				commander.NewCancelCommander(cancelSender),
+				token,
+				&logger,
+			)
+
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body == "" {
+				request = httptest.NewRequest(tt.method, tt.target, http.NoBody)
+			}
+			request.Header.Set("Authorization", lo.FromPtrOr(tt.authorization, "Bearer "+token))
+			recorder := httptest.NewRecorder()
+
+			server.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.wantStatus, recorder.Code, "should return correct status")
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, recorder.Body.String(), "should return correct body")
+			}
+		})
+	}
+}
+
+func TestUnitAuthentication(t *testing.T) {
+	unauthorizedJSON := `{"error":"unauthorized"}`
+
+	runAPITests(t, map[string]apiTest{
+		"valid token": {
+			method: http.MethodGet,
+			target: "/shops",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShops", mock.Anything, false).Return([]models.Shop{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		"missing token": {
+			method:        http.MethodGet,
+			target:        "/shops",
+			authorization: lo.ToPtr(""),
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      unauthorizedJSON,
+		},
+		"invalid token": {
+			method:        http.MethodPost,
+			target:        "/runs/1/cancel",
+			authorization: lo.ToPtr("Bearer other-token"),
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      unauthorizedJSON,
+		},
+		"not bearer token": {
+			method:        http.MethodGet,
+			target:        "/shops",
+			authorization: lo.ToPtr("Basic " + token),
+			wantStatus:    http.StatusUnauthorized,
+			wantBody:      unauthorizedJSON,
+		},
+	})
+
+	logger := zerolog.Nop()
+	server := api.NewServer(
+		mocks.NewStorage(t),
+		commander.NewParseCommander(cmdmocks.NewSender(t)),
+		commander.NewCancelCommander(cmdmocks.NewSender(t)),
+		"",
+		&logger,
+	)
+	request := httptest.NewRequest(http.MethodGet, "/shops", http.NoBody)
+	request.Header.Set("Authorization", "Bearer ")
+	recorder := httptest.NewRecorder()
+
+	server.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "should reject all requests without configured token")
+}
+
+func TestUnitShops(t *testing.T) {
+	shop := models.Shop{ID: 1, Name: "Shop", URL: "https://shop.com/feed.xml", CreatedAt: createdAt}
+	shopJSON := `{"id":1,"name":"Shop","url":"https://shop.com/feed.xml","disabled":false,"createdAt":"2026-10-19T12:00:00Z"}`
+
+	runAPITests(t, map[string]apiTest{
+		"list shops": {
+			method: http.MethodGet,
+			target: "/shops",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShops", mock.Anything, false).Return([]models.Shop{shop}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[" + shopJSON + "]",
+		},
+		"list shops with deleted": {
+			method: http.MethodGet,
+			target: "/shops?deleted=true",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShops", mock.Anything, true).Return(nil, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+		"list shops storage error": {
+			method: http.MethodGet,
+			target: "/shops",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShops", mock.Anything, false).Return(nil, assert.AnError).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error"}`,
+		},
+		"create shop": {
+			method: http.MethodPost,
+			target: "/shops",
+			body:   `{"name":"Shop","url":"https://shop.com/feed.xml"}`,
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("CreateShop", mock.Anything, &models.Shop{Name: "Shop", URL: "https://shop.com/feed.xml"}).
+					Run(func(args mock.Arguments) {
+						created := args.Get(1).(*models.Shop)
+						created.ID = 1
+						created.CreatedAt = createdAt
+					}).
+					Return(nil).
+					Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   shopJSON,
+		},
+		"create shop without url": {
+			method:     http.MethodPost,
+			target:     "/shops",
+			body:       `{"name":"Shop"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"create shop with unknown field": {
+			method:     http.MethodPost,
+			target:     "/shops",
+			body:       `{"url":"https://shop.com/feed.xml","unknown":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"create shop with taken url": {
+			method: http.MethodPost,
+			target: "/shops",
+			body:   `{"url":"https://shop.com/feed.xml"}`,
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("CreateShop", mock.Anything, mock.Anything).Return(platform.ErrShopURLTaken).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"shop url is already used by other shop"}`,
+		},
+		"get shop": {
+			method: http.MethodGet,
+			target: "/shops/1",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   shopJSON,
+		},
+		"get not existing shop": {
+			method: http.MethodGet,
+			target: "/shops/2",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 2).Return(nil, platform.ErrShopNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"get shop with invalid id": {
+			method:     http.MethodGet,
+			target:     "/shops/abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		"update shop": {
+			method: http.MethodPatch,
+			target: "/shops/1",
+			body:   `{"disabled":true}`,
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				updated := shop
+				updated.Disabled = true
+				storage.On("UpdateShop", mock.Anything, updated).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   strings.Replace(shopJSON, `"disabled":false`, `"disabled":true`, 1),
+		},
+		"update deleted shop": {
+			method: http.MethodPatch,
+			target: "/shops/1",
+			body:   `{"name":"New name"}`,
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("UpdateShop", mock.Anything, mock.Anything).Return(platform.ErrShopDeleted).Once()
+			},
+			wantStatus: http.StatusConflict,
+		},
+		"update shop with empty url": {
+			method: http.MethodPatch,
+			target: "/shops/1",
+			body:   `{"url":""}`,
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	})
+}
+
+func TestUnitParseShop(t *testing.T) {
+	shop := models.Shop{ID: 1, URL: "shop1"}
+
+	runAPITests(t, map[string]apiTest{
+		"parse shop": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			setup: func(storage *mocks.Storage, parseSender, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				parseSender.On("Send", mock.Anything, []byte(`{"shopUrl":"shop1"}`)).Return(nil).Once()
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		"parse shop with options": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			body:   `{"incremental":true,"removedProductIds":["p1"],"dryRun":true}`,
+			setup: func(storage *mocks.Storage, parseSender, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				parseSender.On(
+					"Send",
+					mock.Anything,
+					[]byte(`{"shopUrl":"shop1","incremental":true,"removedProductIds":["p1"],"dryRun":true}`),
+				).Return(nil).Once()
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		"parse shop clearing supplemental feeds": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			body:   `{"supplementalFeedUrls":[]}`,
+			setup: func(storage *mocks.Storage, parseSender, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				parseSender.On("Send", mock.Anything, []byte(`{"shopUrl":"shop1","supplementalFeedUrls":[]}`)).
+					Return(nil).
+					Once()
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		"parse disabled shop": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(&models.Shop{ID: 1, Disabled: true}, nil).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"shop is disabled"}`,
+		},
+		"parse deleted shop": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(&models.Shop{ID: 1, DeletedAt: &createdAt}, nil).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"shop is deleted"}`,
+		},
+		"parse not existing shop": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(nil, platform.ErrShopNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"send error": {
+			method: http.MethodPost,
+			target: "/shops/1/parse",
+			setup: func(storage *mocks.Storage, parseSender, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				parseSender.On("Send", mock.Anything, mock.Anything).Return(assert.AnError).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestUnitRuns(t *testing.T) {
+	shop := models.Shop{ID: 1, URL: "shop1"}
+	runs := []models.Run{
+		{
+			ID:              3,
+			ShopID:          1,
+			CreatedAt:       createdAt,
+			Status:          models.RunStatusFailed,
+			StatusMessage:   lo.ToPtr("can't fetch feed"),
+			ProductsVersion: 30,
+		},
+		{
+			ID:              2,
+			ShopID:          1,
+			CreatedAt:       createdAt,
+			FinishedAt:      &createdAt,
+			Status:          models.RunStatusSucceeded,
+			ProductsVersion: 20,
+			CreatedProducts: lo.ToPtr(int32(5)),
+		},
+	}
+
+	runAPITests(t, map[string]apiTest{
+		"list runs": {
+			method: http.MethodGet,
+			target: "/shops/1/runs?limit=2",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetRuns", mock.Anything, 1, 0, 2).Return(runs, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[
+				{"id":3,"shopId":1,"createdAt":"2026-10-19T12:00:00Z","status":"failed",
+					"statusMessage":"can't fetch feed","isIncremental":false,"isDryRun":false,"productsVersion":30},
+				{"id":2,"shopId":1,"createdAt":"2026-10-19T12:00:00Z","finishedAt":"2026-10-19T12:00:00Z",
+					"status":"succeeded","isIncremental":false,"isDryRun":false,"productsVersion":20,"createdProducts":5}
+			],"nextCursor":2}`,
+		},
+		"list last page of runs": {
+			method: http.MethodGet,
+			target: "/shops/1/runs?cursor=2",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetRuns", mock.Anything, 1, 2, api.DefaultPageSize).Return(nil, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[]}`,
+		},
+		"invalid limit": {
+			method: http.MethodGet,
+			target: "/shops/1/runs?limit=1000",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"get run": {
+			method: http.MethodGet,
+			target: "/runs/3",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetRun", mock.Anything, 3).Return(&runs[0], nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"id":3,"shopId":1,"createdAt":"2026-10-19T12:00:00Z","status":"failed",
+				"statusMessage":"can't fetch feed","isIncremental":false,"isDryRun":false,"productsVersion":30}`,
+		},
+		"get not existing run": {
+			method: http.MethodGet,
+			target: "/runs/4",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetRun", mock.Anything, 4).Return(nil, platform.ErrRunNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"cancel run": {
+			method: http.MethodPost,
+			target: "/runs/4/cancel",
+			setup: func(storage *mocks.Storage, _, cancelSender *cmdmocks.Sender) {
+				storage.On("GetRun", mock.Anything, 4).
+					Return(&models.Run{ID: 4, ShopID: 1, Status: models.RunStatusRunning}, nil).
+					Once()
+				cancelSender.On("Send", mock.Anything, []byte(`{"runId":4}`)).Return(nil).Once()
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		"cancel finished run": {
+			method: http.MethodPost,
+			target: "/runs/3/cancel",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetRun", mock.Anything, 3).Return(&runs[0], nil).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"run is not running"}`,
+		},
+	})
+}
+
+func TestUnitShopProducts(t *testing.T) {
+	shop := models.Shop{ID: 1, URL: "shop1"}
+	products := []models.Product{
+		{
+			ID:           10,
+			ProductID:    "p1",
+			Version:      20,
+			CreatedAt:    createdAt,
+			Title:        "Product",
+			Availability: "in stock",
+			Price:        "10.00 PLN",
+			Brand:        lo.ToPtr("Brand"),
+			Shippings:    []models.Shipping{{Country: "PL", Service: "Standard", Price: "5.00 PLN"}},
+		},
+	}
+
+	runAPITests(t, map[string]apiTest{
+		"list products": {
+			method: http.MethodGet,
+			target: "/shops/1/products?cursor=5&limit=1",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetShopProducts", mock.Anything, 1, models.ProductFilter{}, 5, 1).Return(products, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":10,"productId":"p1","version":20,"createdAt":"2026-10-19T12:00:00Z",
+				"title":"Product","description":"","url":"","imageUrl":"","condition":"","availability":"in stock",
+				"price":"10.00 PLN","brand":"Brand",
+				"shippings":[{"country":"PL","service":"Standard","price":"5.00 PLN"}]}],"nextCursor":10}`,
+		},
+		"filtered products": {
+			method: http.MethodGet,
+			target: "/shops/1/products?availability=in+stock&brand=Brand&category=Apparel&minPrice=5&maxPrice=20.5" +
+				"&updatedSince=15&deleted=true",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetShopProducts", mock.Anything, 1, models.ProductFilter{
+					Availability:   lo.ToPtr("in stock"),
+					Brand:          lo.ToPtr("Brand"),
+					Category:       lo.ToPtr("Apparel"),
+					MinPrice:       lo.ToPtr(5.0),
+					MaxPrice:       lo.ToPtr(20.5),
+					UpdatedSince:   lo.ToPtr(int64(15)),
+					IncludeDeleted: true,
+				}, 0, api.DefaultPageSize).Return([]models.Product{}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[]}`,
+		},
+		"invalid price": {
+			method: http.MethodGet,
+			target: "/shops/1/products?minPrice=abc",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"min price greater than max price": {
+			method: http.MethodGet,
+			target: "/shops/1/products?minPrice=20&maxPrice=10",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid updated since": {
+			method: http.MethodGet,
+			target: "/shops/1/products?updatedSince=-1",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid cursor": {
+			method: http.MethodGet,
+			target: "/shops/1/products?cursor=abc",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"storage error": {
+			method: http.MethodGet,
+			target: "/shops/1/products",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetShopProducts", mock.Anything, 1, models.ProductFilter{}, 0, api.DefaultPageSize).
+					Return(nil, context.DeadlineExceeded).
+					Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
+
+func TestUnitProductChanges(t *testing.T) {
+	shop := models.Shop{ID: 1, URL: "shop1"}
+	changes := []models.ChangedProduct{
+		{
+			Type:    models.ChangeCreated,
+			Version: 30,
+			Product: models.Product{ID: 10, ProductID: "p1", Version: 30, CreatedAt: createdAt, Price: "10.00 PLN"},
+		},
+		{
+			Type:    models.ChangeDeleted,
+			Version: 40,
+			Product: models.Product{ID: 7, ProductID: "p2", Version: 25, CreatedAt: createdAt, DeletedAt: &createdAt},
+		},
+	}
+	changesJSON := `[{"type":"created","version":30,"product":{"id":10,"productId":"p1","version":30,
+		"createdAt":"2026-10-19T12:00:00Z","title":"","description":"","url":"","imageUrl":"","condition":"",
+		"availability":"","price":"10.00 PLN","shippings":[]}},
+		{"type":"deleted","version":40,"product":{"id":7,"productId":"p2","version":25,
+		"createdAt":"2026-10-19T12:00:00Z","deletedAt":"2026-10-19T12:00:00Z","title":"","description":"","url":"",
+		"imageUrl":"","condition":"","availability":"","price":"","shippings":[]}}]`
+
+	runAPITests(t, map[string]apiTest{
+		"changes since version": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?since=20&limit=2",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetProductChanges", mock.Anything, 1, models.ChangesCursor{SinceVersion: 20, Version: 20}, 2).
+					Return(changes, nil).
+					Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":` + changesJSON + `,"nextCursor":"20.40.7","hasMore":true}`,
+		},
+		"changes since run": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?runId=3",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetRun", mock.Anything, 3).Return(&models.Run{ID: 3, ShopID: 1, ProductsVersion: 20}, nil).Once()
+				storage.On("GetProductChanges", mock.Anything, 1, models.ChangesCursor{SinceVersion: 20, Version: 20},
+					api.DefaultPageSize).
+					Return(changes, nil).
+					Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":` + changesJSON + `,"nextCursor":"20.40.7","hasMore":false}`,
+		},
+		"resumed without changes": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?cursor=20.40.7",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetProductChanges", mock.Anything, 1, models.ChangesCursor{SinceVersion: 20, Version: 40, ID: 7},
+					api.DefaultPageSize).
+					Return([]models.ChangedProduct{}, nil).
+					Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[],"nextCursor":"20.40.7","hasMore":false}`,
+		},
+		"all changes": {
+			method: http.MethodGet,
+			target: "/shops/1/changes",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetProductChanges", mock.Anything, 1, models.ChangesCursor{}, api.DefaultPageSize).
+					Return([]models.ChangedProduct{}, nil).
+					Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[],"nextCursor":"0.0.0","hasMore":false}`,
+		},
+		"run of other shop": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?runId=3",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetRun", mock.Anything, 3).Return(&models.Run{ID: 3, ShopID: 2}, nil).Once()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"not existing run": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?runId=3",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetRun", mock.Anything, 3).Return(nil, platform.ErrRunNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		"since and run": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?since=20&runId=3",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid cursor": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?cursor=20.10.7",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"invalid since": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?since=abc",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		"storage error": {
+			method: http.MethodGet,
+			target: "/shops/1/changes?since=20",
+			setup: func(storage *mocks.Storage, _, _ *cmdmocks.Sender) {
+				storage.On("GetShop", mock.Anything, 1).Return(lo.ToPtr(shop), nil).Once()
+				storage.On("GetProductChanges", mock.Anything, 1, models.ChangesCursor{SinceVersion: 20, Version: 20},
+					api.DefaultPageSize).
+					Return(nil, assert.AnError).
+					Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+)
+
+type createShopRequest struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Disabled bool   `json:"disabled"`
+}
+
+// updateShopRequest updates only provided shop's fields.
+type updateShopRequest struct {
+	Name     *string `json:"name"`
+	URL      *string `json:"url"`
+	Disabled *bool   `json:"disabled"`
+}
+
+// parseRequest is optional configuration of parse command, see commander.ParseCommand.
+type parseRequest struct {
+	SupplementalFeedURLs *[]string `json:"supplementalFeedUrls"`
+	Incremental          bool      `json:"incremental"`
+	RemovedProductIDs    []string  `json:"removedProductIds"`
+	ForceDeletion        bool      `json:"forceDeletion"`
+	DryRun               bool      `json:"dryRun"`
+}
+
+// getShops lists shops, deleted shops are listed if `deleted=true` query parameter is set.
+func (s *Server) getShops(w http.ResponseWriter, r *http.Request) {
+	shops, err := s.storage.GetShops(r.Context(), r.URL.Query().Get("deleted") == "true")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	response := make([]shopResponse, 0, len(shops))
+	for ix := range shops {
+		response = append(response, toShopResponse(&shops[ix]))
+	}
+
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) createShop(w http.ResponseWriter, r *http.Request) {
+	var request createShopRequest
+	if err := decodeBody(r, &request); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(request.URL) == "" {
+		s.writeError(w, fmt.Errorf("%w: shop url is required", ErrInvalidRequest))
+		return
+	}
+
+	shop := models.Shop{
+		Name:     request.Name,
+		URL:      request.URL,
+		Disabled: request.Disabled,
+	}
+	if err := s.storage.CreateShop(r.Context(), &shop); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, toShopResponse(&shop))
+}
+
+func (s *Server) getShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toShopResponse(shop))
+}
+
+func (s *Server) updateShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request updateShopRequest
+	if err := decodeBody(r, &request); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	if request.Name != nil {
+		shop.Name = *request.Name
+	}
+
+	if request.URL != nil {
+		if strings.TrimSpace(*request.URL) == "" {
+			s.writeError(w, fmt.Errorf("%w: shop url can't be empty", ErrInvalidRequest))
+			return
+		}
+
+		shop.URL = *request.URL
+	}
+
+	if request.Disabled != nil {
+		shop.Disabled = *request.Disabled
+	}
+
+	if err := s.storage.UpdateShop(r.Context(), *shop); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, toShopResponse(shop))
+}
+
+// parseShop sends parse command of the shop, the body with command's configuration is optional.
+// Parsing runs asynchronously, so its run can be found in shop's runs.
+func (s *Server) parseShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := s.shopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request parseRequest
+	if err := decodeBody(r, &request); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, err)
+		return
+	}
+
+	switch {
+	case shop.DeletedAt != nil:
+		s.writeError(w, platform.ErrShopDeleted)
+		return
+	case shop.Disabled:
+		s.writeError(w, platform.ErrShopDisabled)
+		return
+	}
+
+	var ops []commander.CommandOption
+	if request.SupplementalFeedURLs != nil {
+		ops = append(ops, commander.WithSupplementalFeeds(*request.SupplementalFeedURLs...))
+	}
+
+	if request.Incremental {
+		ops = append(ops, commander.WithIncrementalMode(request.RemovedProductIDs...))
+	}
+
+	if request.ForceDeletion {
+		ops = append(ops, commander.WithForcedDeletion())
+	}
+
+	if request.DryRun {
+		ops = append(ops, commander.WithDryRun())
+	}
+
+	if err := s.parseCommander.SendParseCommand(r.Context(), shop.URL, ops...); err != nil {
+		s.writeError(w, fmt.Errorf("can't send parse command: %w", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// shopFromPath returns shop with ID from request's path. If it can't be read, error response is written.
+func (s *Server) shopFromPath(w http.ResponseWriter, r *http.Request) (*models.Shop, bool) {
+	shopID, err := pathID(r, "shopID")
+	if err != nil {
+		s.writeError(w, err)
+		return nil, false
+	}
+
+	shop, err := s.storage.GetShop(r.Context(), shopID)
+	if err != nil {
+		s.writeError(w, err)
+		return nil, false
+	}
+
+	return shop, true
+}
@@ -80,6 +80,68 @@ func TestUnitDecodeBadXMLFormat(t *testing.T) {
 	)
 }
 
+func TestUnitDecodeIndex(t *testing.T) {
+	tests := map[string]struct {
+		file     string
+		wantURLs []string
+		wantErr  bool
+	}{
+		"feed index": {
+			file: `<?xml version="1.0"?>
+				<feedindex>
+					<feed><loc>http://shop.com/feed-1.xml</loc></feed>
+					<feed><loc> http://shop.com/feed-2.xml </loc></feed>
+					<feed><loc></loc></feed>
+				</feedindex>`,
+			wantURLs: []string{"http://shop.com/feed-1.xml", "http://shop.com/feed-2.xml"},
+		},
+		"sitemap index": {
+			file: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+					<sitemap><loc>http://shop.com/feed-1.xml</loc></sitemap>
+				</sitemapindex>`,
+			wantURLs: []string{"http://shop.com/feed-1.xml"},
+		},
+		"empty index": {
+			file:     `<feedindex></feedindex>`,
+			wantURLs: []string{},
+		},
+		"bad xml format error": {
+			file:    `<feedindex><feed><loc></feed></feedindex>`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			urls, reader, err := decoder.Decoder{}.DecodeIndex(strings.NewReader(tt.file))
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				return
+			}
+
+			require.NoError(t, err, "shouldn't return any error")
+			assert.Nil(t, reader, "shouldn't return reader")
+			assert.Equal(t, tt.wantURLs, urls, "should return correct urls")
+		})
+	}
+}
+
+func TestUnitDecodeIndexNotIndex(t *testing.T) {
+	file := FeedFileAsReader(t)
+
+	urls, reader, err := decoder.Decoder{}.DecodeIndex(file)
+
+	require.NoError(t, err, "shouldn't return any error")
+	assert.Nil(t, urls, "shouldn't return any url")
+
+	want, err := os.ReadFile(path.Join("testdata", feedFileName))
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "should return reader with whole file")
+}
+
 func collect(resultsCh <-chan models.ParsingResult) ([]models.Product, []error) {
 	var (
 		products []models.Product
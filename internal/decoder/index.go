@@ -0,0 +1,67 @@
+package decoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// indexPeekSize is number of bytes read ahead to find out if file is a feed index.
+const indexPeekSize = 4096
+
+// FeedIndex is model for feed index files listing feed files, similar to sitemap index.
+type FeedIndex struct {
+	Entries []IndexEntry `xml:",any"`
+}
+
+// IndexEntry is model for single feed file entry in feed index files.
+type IndexEntry struct {
+	Location string `xml:"loc"`
+}
+
+// DecodeIndex decodes urls of feed files from feed index.
+// If file is not a feed index, it returns nil urls and reader containing whole, unread file.
+func (d Decoder) DecodeIndex(file io.Reader) ([]string, io.Reader, error) {
+	reader := bufio.NewReaderSize(file, indexPeekSize)
+
+	// error is ignored, because file shorter than peek size is returned with io.EOF
+	peeked, _ := reader.Peek(indexPeekSize)
+	if !isIndex(peeked) {
+		return nil, reader, nil
+	}
+
+	var index FeedIndex
+	dec := xml.NewDecoder(reader)
+	dec.Strict = true
+	if err := dec.Decode(&index); err != nil {
+		return nil, nil, fmt.Errorf("can't decode feed index: %w", err)
+	}
+
+	urls := make([]string, 0, len(index.Entries))
+	for ix := range index.Entries {
+		if loc := strings.TrimSpace(index.Entries[ix].Location); loc != "" {
+			urls = append(urls, loc)
+		}
+	}
+
+	return urls, nil, nil
+}
+
+// isIndex returns true if root element of xml document beginning is feed index element.
+func isIndex(beginning []byte) bool {
+	dec := xml.NewDecoder(bytes.NewReader(beginning))
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return false
+		}
+
+		if element, ok := token.(xml.StartElement); ok {
+			return element.Name.Local == "feedindex" || element.Name.Local == "sitemapindex"
+		}
+	}
+}
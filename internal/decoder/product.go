@@ -13,6 +13,7 @@ type Product struct {
 	Condition           string     `xml:"condition" ,json:"condition"`
 	Availability        string     `xml:"availability" ,json:"availability"`
 	Price               string     `xml:"price" ,json:"price"`
+	SalePrice           *string    `xml:"sale_price" ,json:"salePrice"`
 	Shippings           []Shipping `xml:"shipping" ,json:"shipping"`
 	Brand               *string    `xml:"brand" ,json:"brand"`
 	GTIN                *string    `xml:"gtin" ,json:"gtin"`
@@ -44,6 +45,7 @@ func toAppProduct(product *Product) *models.Product {
 		Condition:           product.Condition,
 		Availability:        product.Availability,
 		Price:               product.Price,
+		SalePrice:           product.SalePrice,
 		Shippings:           toAppShippings(product.Shippings),
 		Brand:               product.Brand,
 		GTIN:                product.GTIN,
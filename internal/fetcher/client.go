@@ -0,0 +1,152 @@
+package fetcher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ClientConfig holds configuration of http client used for fetching feed files.
+type ClientConfig struct {
+	// Timeout is total timeout of a single request, including redirects and reading response body.
+	Timeout time.Duration
+	// ConnectTimeout is timeout for establishing TCP connection and TLS handshake.
+	ConnectTimeout time.Duration
+	// MaxRedirects is maximal number of followed redirects, more redirects fail with ErrTooManyRedirects.
+	// With 0, every redirected request fails.
+	MaxRedirects int
+	// AllowHTTPSDowngrade allows following redirects from https to plain http.
+	AllowHTTPSDowngrade bool
+	// ProxyURL is url of outbound http proxy. If empty, proxy is taken from environment variables.
+	ProxyURL string
+	// CACertFile is path to PEM bundle with additional trusted CA certificates.
+	CACertFile string
+	// ClientCertFile is path to PEM client certificate used for mTLS.
+	ClientCertFile string
+	// ClientKeyFile is path to PEM client certificate key used for mTLS.
+	ClientKeyFile string
+	// BlockPrivateNetworks refuses connections to private, loopback, link-local and CGNAT addresses.
+	// Hosts of requests sent through a proxy are checked before proxying, the proxy itself can be private.
+	// The proxy resolves hosts again, so it should also refuse private networks to prevent DNS rebinding.
+	BlockPrivateNetworks bool
+	// AllowedHosts are hostnames, IP addresses or CIDR ranges excluded from private networks blocking.
+	AllowedHosts []string
+}
+
+// NewClient returns new http client configured according to provided config.
+func NewClient(cfg ClientConfig) (*http.Client, error) {
+	proxy, err := proxyFunc(cfg.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(cfg, proxy)
+}
+
+// newClient returns new http client configured according to provided config, which uses provided proxy function.
+func newClient(cfg ClientConfig, proxy func(*http.Request) (*url.URL, error)) (*http.Client, error) {
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   cfg.ConnectTimeout,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if cfg.BlockPrivateNetworks {
+		guard, err := newDialGuard(cfg.AllowedHosts)
+		if err != nil {
+			return nil, err
+		}
+		transport.DialContext = guard.dialContext(dialer)
+		roundTripper = &guardedTransport{transport: transport, guard: guard}
+	}
+
+	return &http.Client{
+		Transport:     roundTripper,
+		Timeout:       cfg.Timeout,
+		CheckRedirect: checkRedirect(cfg.MaxRedirects, cfg.AllowHTTPSDowngrade),
+	}, nil
+}
+
+// checkRedirect returns redirect policy limiting number of redirects and optionally refusing https downgrades.
+func checkRedirect(maxRedirects int, allowDowngrade bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return ErrTooManyRedirects
+		}
+
+		if !allowDowngrade && len(via) > 0 && via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			return ErrHTTPSDowngrade
+		}
+
+		return nil
+	}
+}
+
+func proxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse proxy url: %w", err)
+	}
+
+	return http.ProxyURL(parsed), nil
+}
+
+func tlsConfig(cfg ClientConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA certificates file: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidCACerts
+		}
+
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load client certificate: %w", err)
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
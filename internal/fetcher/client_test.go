@@ -0,0 +1,124 @@
+package fetcher_test
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MichalMitros/google-feed-parser/internal/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitNewClientRedirects(t *testing.T) {
+	plainSrv := httptest.NewServer(http.HandlerFunc(func(wrt http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/redirect" {
+			http.Redirect(wrt, req, "/file", http.StatusFound)
+			return
+		}
+		wrt.Header().Add(contentType, "application/xml")
+		wrt.Write([]byte(response))
+	}))
+	t.Cleanup(plainSrv.Close)
+
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(wrt http.ResponseWriter, req *http.Request) {
+		http.Redirect(wrt, req, plainSrv.URL+"/file", http.StatusFound)
+	}))
+	t.Cleanup(tlsSrv.Close)
+
+	caFile := writeCertificate(t, tlsSrv)
+
+	tests := map[string]struct {
+		cfg      fetcher.ClientConfig
+		url      string
+		wantBody string
+		wantErr  error
+	}{
+		"ok redirect": {
+			cfg:      fetcher.ClientConfig{MaxRedirects: 1},
+			url:      plainSrv.URL + "/redirect",
+			wantBody: response,
+		},
+		"too many redirects error": {
+			cfg:     fetcher.ClientConfig{MaxRedirects: 0},
+			url:     plainSrv.URL + "/redirect",
+			wantErr: fetcher.ErrTooManyRedirects,
+		},
+		"https downgrade error": {
+			cfg:     fetcher.ClientConfig{MaxRedirects: 1, CACertFile: caFile},
+			url:     tlsSrv.URL,
+			wantErr: fetcher.ErrHTTPSDowngrade,
+		},
+		"ok allowed https downgrade": {
+			cfg:      fetcher.ClientConfig{MaxRedirects: 1, CACertFile: caFile, AllowHTTPSDowngrade: true},
+			url:      tlsSrv.URL,
+			wantBody: response,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := fetcher.NewClient(tt.cfg)
+			require.NoError(t, err, "shouldn't return any error")
+
+			fet := fetcher.NewFetcher(client, userAgent)
+			resp, err := fet.FetchFile(context.TODO(), tt.url)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, readAndClose(t, resp), "should return correct response")
+			}
+		})
+	}
+}
+
+func TestUnitNewClientConfigErrors(t *testing.T) {
+	invalidCAFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(invalidCAFile, []byte("not a certificate"), 0o600))
+
+	tests := map[string]struct {
+		cfg     fetcher.ClientConfig
+		wantErr error
+	}{
+		"invalid proxy url": {
+			cfg: fetcher.ClientConfig{ProxyURL: "://proxy"},
+		},
+		"missing CA file": {
+			cfg: fetcher.ClientConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+		"invalid CA file": {
+			cfg:     fetcher.ClientConfig{CACertFile: invalidCAFile},
+			wantErr: fetcher.ErrInvalidCACerts,
+		},
+		"missing client certificate": {
+			cfg: fetcher.ClientConfig{ClientCertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := fetcher.NewClient(tt.cfg)
+
+			require.Error(t, err, "should return error")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+			}
+		})
+	}
+}
+
+// writeCertificate writes test server certificate into PEM file and returns its path.
+func writeCertificate(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(path, certPEM, 0o600), "can't write certificate file")
+
+	return path
+}
@@ -7,4 +7,12 @@ var (
 	ErrStatusNotOK = errors.New("response status is not 200 OK")
 	// ErrContentTypeNotSupported is returned when response content type is not supported.
 	ErrContentTypeNotSupported = errors.New("response content type not supported")
+	// ErrTooManyRedirects is returned when response was redirected more times than allowed.
+	ErrTooManyRedirects = errors.New("too many redirects")
+	// ErrHTTPSDowngrade is returned when https request was redirected to plain http.
+	ErrHTTPSDowngrade = errors.New("redirect from https to http is not allowed")
+	// ErrInvalidCACerts is returned when CA certificates file doesn't contain any valid PEM certificate.
+	ErrInvalidCACerts = errors.New("no valid CA certificates found")
+	// ErrRestrictedAddress is returned when feed url resolves to private, loopback, link-local or CGNAT address.
+	ErrRestrictedAddress = errors.New("connection to restricted address is not allowed")
 )
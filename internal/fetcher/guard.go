@@ -0,0 +1,190 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// cgnatPrefix is shared address space of carrier-grade NAT (RFC 6598), which isn't publicly routable.
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// dialGuard protects from SSRF by refusing connections to private, loopback, link-local and CGNAT addresses.
+// The check is done at dial time on resolved IP addresses, so it also covers redirects and DNS rebinding.
+// Requests sent through a proxy are checked before proxying instead, as only proxy's address is dialed.
+// The proxy resolves their hosts again, so it should refuse private networks too to prevent DNS rebinding.
+type dialGuard struct {
+	allowedHosts    map[string]struct{}
+	allowedPrefixes []netip.Prefix
+}
+
+// proxyAddressKey is context key of address of the proxy which the request is sent through.
+type proxyAddressKey struct{}
+
+// newDialGuard returns new dialGuard with allowlist of hostnames, IP addresses and CIDR ranges.
+func newDialGuard(allowlist []string) (*dialGuard, error) {
+	guard := &dialGuard{
+		allowedHosts: make(map[string]struct{}),
+	}
+
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse allowed network %q: %w", entry, err)
+			}
+			guard.allowedPrefixes = append(guard.allowedPrefixes, prefix.Masked())
+			continue
+		}
+
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			guard.allowedPrefixes = append(guard.allowedPrefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		guard.allowedHosts[entry] = struct{}{}
+	}
+
+	return guard, nil
+}
+
+// dialContext returns dial function which uses guarded dialer for all hosts except allowlisted hostnames
+// and the proxy of request sent through guardedTransport.
+func (g *dialGuard) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	guarded := *dialer
+	guarded.Control = g.control
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if proxy, ok := ctx.Value(proxyAddressKey{}).(string); ok && proxy == strings.ToLower(address) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+
+		if _, ok := g.allowedHosts[strings.ToLower(host)]; ok {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		return guarded.DialContext(ctx, network, address)
+	}
+}
+
+// checkHost resolves not allowlisted host and checks all its addresses.
+func (g *dialGuard) checkHost(ctx context.Context, host string) error {
+	if _, ok := g.allowedHosts[strings.ToLower(host)]; ok {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("can't resolve host %q: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if err := g.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// control is called by net.Dialer after DNS resolution, right before connecting to address.
+func (g *dialGuard) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("can't parse dialed address %q: %w", address, err)
+	}
+
+	return g.checkAddr(addrPort.Addr())
+}
+
+// checkAddr returns ErrRestrictedAddress if address is restricted and not allowlisted.
+func (g *dialGuard) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	for _, prefix := range g.allowedPrefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if isRestricted(addr) {
+		return fmt.Errorf("%w: %s", ErrRestrictedAddress, addr)
+	}
+
+	return nil
+}
+
+// proxyAddress returns address dialed by transport to connect to the proxy.
+func proxyAddress(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+
+	return strings.ToLower(net.JoinHostPort(proxyURL.Hostname(), port))
+}
+
+// guardedTransport checks hosts of requests sent through a proxy, as the transport dials only the proxy then.
+// Transport calls it for every request, including redirects. Only dials of the proxy made for the checked request
+// skip the guard, so the proxy's address can't be requested directly.
+type guardedTransport struct {
+	transport *http.Transport
+	guard     *dialGuard
+}
+
+// RoundTrip checks host of proxied request and sends the request with proxy's address in its context.
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, err := t.transport.Proxy(req)
+	if err != nil || proxyURL == nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	if err := t.guard.checkHost(req.Context(), req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	ctx := context.WithValue(req.Context(), proxyAddressKey{}, proxyAddress(proxyURL))
+
+	return t.transport.RoundTrip(req.WithContext(ctx))
+}
+
+// CloseIdleConnections closes idle connections of the transport.
+func (t *guardedTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// isRestricted returns true if address is not publicly routable.
+func isRestricted(addr netip.Addr) bool {
+	return cgnatPrefix.Contains(addr) ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsUnspecified()
+}
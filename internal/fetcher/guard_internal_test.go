@@ -0,0 +1,39 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitGuardedTransportDirectProxyRequest(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(wrt http.ResponseWriter, _ *http.Request) {
+		wrt.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err, "can't parse proxy url")
+
+	// only requests to localhost are proxied, like with NO_PROXY environment variable.
+	client, err := newClient(ClientConfig{
+		BlockPrivateNetworks: true,
+		AllowedHosts:         []string{"localhost"},
+	}, func(req *http.Request) (*url.URL, error) {
+		if req.URL.Hostname() == "localhost" {
+			return proxyURL, nil
+		}
+		return nil, nil
+	})
+	require.NoError(t, err, "shouldn't return any error")
+
+	resp, err := client.Get("http://localhost/file")
+	require.NoError(t, err, "should send request through private proxy")
+	require.NoError(t, resp.Body.Close(), "can't close response body")
+
+	_, err = client.Get(proxy.URL + "/file")
+	require.ErrorIs(t, err, ErrRestrictedAddress, "should block direct request to proxy's address")
+}
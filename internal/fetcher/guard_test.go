@@ -0,0 +1,176 @@
+package fetcher_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/MichalMitros/google-feed-parser/internal/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitNewClientPrivateNetworks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(wrt http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/redirect" {
+			http.Redirect(wrt, req, "/file", http.StatusFound)
+			return
+		}
+		wrt.Header().Add(contentType, "application/xml")
+		wrt.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err, "can't parse test server url")
+	localhostURL := "http://localhost:" + srvURL.Port()
+
+	tests := map[string]struct {
+		cfg      fetcher.ClientConfig
+		url      string
+		wantBody string
+		wantErr  error
+	}{
+		"ok blocking disabled": {
+			cfg:      fetcher.ClientConfig{},
+			url:      srv.URL + endpoint,
+			wantBody: response,
+		},
+		"loopback address error": {
+			cfg:     fetcher.ClientConfig{BlockPrivateNetworks: true},
+			url:     srv.URL + endpoint,
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+		"resolved loopback address error": {
+			cfg:     fetcher.ClientConfig{BlockPrivateNetworks: true},
+			url:     localhostURL + endpoint,
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+		"ok allowed network": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				AllowedHosts:         []string{"127.0.0.0/8"},
+			},
+			url:      srv.URL + endpoint,
+			wantBody: response,
+		},
+		"ok allowed address after redirect": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				MaxRedirects:         1,
+				AllowedHosts:         []string{"127.0.0.1"},
+			},
+			url:      srv.URL + "/redirect",
+			wantBody: response,
+		},
+		"ok allowed hostname": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				AllowedHosts:         []string{"localhost"},
+			},
+			url:      localhostURL + endpoint,
+			wantBody: response,
+		},
+		"not allowed hostname error": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				AllowedHosts:         []string{"localhost"},
+			},
+			url:     srv.URL + endpoint,
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := fetcher.NewClient(tt.cfg)
+			require.NoError(t, err, "shouldn't return any error")
+
+			fet := fetcher.NewFetcher(client, userAgent)
+			resp, err := fet.FetchFile(context.TODO(), tt.url)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, readAndClose(t, resp), "should return correct response")
+			}
+		})
+	}
+}
+
+func TestUnitNewClientInvalidAllowedNetwork(t *testing.T) {
+	_, err := fetcher.NewClient(fetcher.ClientConfig{
+		BlockPrivateNetworks: true,
+		AllowedHosts:         []string{"10.0.0.0/99"},
+	})
+
+	require.Error(t, err, "should return error")
+}
+
+func TestUnitNewClientPrivateNetworksProxy(t *testing.T) {
+	// proxy responds to proxied requests itself, redirecting the ones with `/redirect` path to loopback address.
+	proxy := httptest.NewServer(http.HandlerFunc(func(wrt http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/redirect" {
+			http.Redirect(wrt, req, "http://127.0.0.1"+endpoint, http.StatusFound)
+			return
+		}
+		wrt.Header().Add(contentType, "application/xml")
+		wrt.Write([]byte(response))
+	}))
+	t.Cleanup(proxy.Close)
+
+	tests := map[string]struct {
+		cfg      fetcher.ClientConfig
+		url      string
+		wantBody string
+		wantErr  error
+	}{
+		"ok private proxy": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				ProxyURL:             proxy.URL,
+				AllowedHosts:         []string{"localhost"},
+			},
+			url:      "http://localhost" + endpoint,
+			wantBody: response,
+		},
+		"loopback address through proxy error": {
+			cfg:     fetcher.ClientConfig{BlockPrivateNetworks: true, ProxyURL: proxy.URL},
+			url:     "http://localhost" + endpoint,
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+		"CGNAT address through proxy error": {
+			cfg:     fetcher.ClientConfig{BlockPrivateNetworks: true, ProxyURL: proxy.URL},
+			url:     "http://100.64.0.1" + endpoint,
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+		"loopback address after redirect through proxy error": {
+			cfg: fetcher.ClientConfig{
+				BlockPrivateNetworks: true,
+				ProxyURL:             proxy.URL,
+				MaxRedirects:         1,
+				AllowedHosts:         []string{"localhost"},
+			},
+			url:     "http://localhost/redirect",
+			wantErr: fetcher.ErrRestrictedAddress,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := fetcher.NewClient(tt.cfg)
+			require.NoError(t, err, "shouldn't return any error")
+
+			fet := fetcher.NewFetcher(client, userAgent)
+			resp, err := fet.FetchFile(context.TODO(), tt.url)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, readAndClose(t, resp), "should return correct response")
+			}
+		})
+	}
+}
@@ -3,16 +3,20 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/rabbitmq"
 	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
 	"github.com/rs/zerolog"
 )
 
-// Parser parses feed files from feed url.
+// Parser parses shop's feed files.
 type Parser interface {
-	Parse(ctx context.Context, shopURL string) error
+	Parse(ctx context.Context, req models.ParseRequest) (*models.Run, error)
 }
 
 // RMQHandler handles RMQ messages.
@@ -20,20 +24,25 @@ type RMQHandler struct {
 	rmq    *rabbitmq.RabbitMQ
 	parser Parser
 	logger *zerolog.Logger
+
+	mu      sync.Mutex
+	running map[int]context.CancelCauseFunc
 }
 
 // NewHandler returns new RMQHandler.
 func NewHandler(rmq *rabbitmq.RabbitMQ, parser Parser, logger *zerolog.Logger) *RMQHandler {
 	return &RMQHandler{
-		rmq:    rmq,
-		parser: parser,
-		logger: logger,
+		rmq:     rmq,
+		parser:  parser,
+		logger:  logger,
+		running: make(map[int]context.CancelCauseFunc),
 	}
 }
 
 // Start starts consuming and handling parsing commands from RMQ.
+// Commands sent with reply_to property are replied with the run's final status.
 func (h *RMQHandler) Start(ctx context.Context, queue string) error {
-	errorsChan, err := h.rmq.Consume(ctx, queue, func(ctx context.Context, message []byte) error {
+	errorsChan, err := h.rmq.Consume(ctx, queue, func(msgCtx context.Context, message []byte) error {
 		cmd, err := decodeMessage(message)
 		if err != nil {
 			return err
@@ -41,9 +50,25 @@ func (h *RMQHandler) Start(ctx context.Context, queue string) error {
 
 		h.logger.Debug().
 			Str("shopUrl", cmd.ShopURL).
+			Bool("incremental", cmd.Incremental).
+			Bool("dryRun", cmd.DryRun).
 			Msg("parsing started")
 
-		err = h.parser.Parse(ctx, cmd.ShopURL)
+		ctx, register, done := h.startParsing(msgCtx)
+		defer done()
+
+		run, err := h.parser.Parse(ctx, models.ParseRequest{
+			ShopURL:              cmd.ShopURL,
+			SupplementalFeedURLs: cmd.SupplementalFeedURLs,
+			Incremental:          cmd.Incremental,
+			RemovedProductIDs:    cmd.RemovedProductIDs,
+			ForceDeletion:        cmd.ForceDeletion,
+			DryRun:               cmd.DryRun,
+			RunStarted:           register,
+		})
+
+		h.reply(context.WithoutCancel(msgCtx), run, err)
+
 		if err != nil {
 			return fmt.Errorf("parsing failed: %w", err)
 		}
@@ -58,17 +83,131 @@ func (h *RMQHandler) Start(ctx context.Context, queue string) error {
 		return err
 	}
 
-	go func() {
-		for err := range errorsChan {
-			h.logger.Error().
-				Err(err).
-				Msg("can't handle message")
+	go h.logErrors(errorsChan)
+
+	return nil
+}
+
+// StartCancelling starts consuming and handling cancel commands sent to routing key.
+// Parse commands are handled one by one, so cancel commands have to be consumed with separate RabbitMQ channel.
+// Each service instance consumes its own queue, so cancel command reaches the instance running the parsing.
+func (h *RMQHandler) StartCancelling(ctx context.Context, rmq *rabbitmq.RabbitMQ, routingKey string) error {
+	queue, err := rmq.BindTemporaryQueue(routingKey)
+	if err != nil {
+		return fmt.Errorf("can't bind cancel commands queue: %w", err)
+	}
+
+	errorsChan, err := rmq.Consume(ctx, queue, func(_ context.Context, message []byte) error {
+		var cmd commander.CancelCommand
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			return fmt.Errorf("can't decode cancel command: %w", err)
+		}
+
+		if h.cancelParsing(cmd.RunID) {
+			h.logger.Debug().
+				Int("runId", cmd.RunID).
+				Msg("parsing cancelled")
 		}
-	}()
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	go h.logErrors(errorsChan)
 
 	return nil
 }
 
+// startParsing returns cancellable context of parsing and function registering parsing's run, so it can be cancelled.
+// Returned done function unregisters the run and has to be called when parsing is finished.
+func (h *RMQHandler) startParsing(ctx context.Context) (context.Context, func(runID int), func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	var runID int
+	register := func(id int) {
+		h.mu.Lock()
+		runID = id
+		h.running[id] = cancel
+		h.mu.Unlock()
+	}
+
+	return ctx, register, func() {
+		h.mu.Lock()
+		delete(h.running, runID)
+		h.mu.Unlock()
+
+		cancel(nil)
+	}
+}
+
+// cancelParsing cancels run's parsing and returns true if the run was being parsed by this instance.
+func (h *RMQHandler) cancelParsing(runID int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cancel, ok := h.running[runID]
+	if ok {
+		cancel(platform.ErrRunCancelled)
+	}
+
+	return ok
+}
+
+// reply replies parse command with run's final status.
+func (h *RMQHandler) reply(ctx context.Context, run *models.Run, parseErr error) {
+	msg, err := json.Marshal(parseReply(run, parseErr))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't marshal parse reply")
+		return
+	}
+
+	if err := h.rmq.Reply(ctx, msg); err != nil {
+		h.logger.Error().Err(err).Msg("can't reply parse command")
+	}
+}
+
+func parseReply(run *models.Run, err error) commander.ParseReply {
+	if run != nil {
+		return commander.ParseReply{
+			Status:        string(run.Status),
+			StatusMessage: run.StatusMessage,
+			RunID:         run.ID,
+		}
+	}
+
+	status := string(models.RunStatusFailed)
+	switch {
+	case errors.Is(err, platform.ErrAlreadyRunning):
+		status = commander.ParseSkipped
+	case errors.Is(err, platform.ErrShopDisabled),
+		errors.Is(err, platform.ErrShopDeleted),
+		errors.Is(err, platform.ErrShopNotFound),
+		errors.Is(err, platform.ErrShopURLChanged):
+		status = commander.ParseRejected
+	}
+
+	var msg *string
+	if err != nil {
+		errMsg := err.Error()
+		msg = &errMsg
+	}
+
+	return commander.ParseReply{
+		Status:        status,
+		StatusMessage: msg,
+	}
+}
+
+func (h *RMQHandler) logErrors(errorsChan <-chan error) {
+	for err := range errorsChan {
+		h.logger.Error().
+			Err(err).
+			Msg("can't handle message")
+	}
+}
+
 func decodeMessage(msg []byte) (*commander.ParseCommand, error) {
 	var cmd commander.ParseCommand
 	err := json.Unmarshal(msg, &cmd)
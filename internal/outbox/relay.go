@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultInterval is default interval of checking outbox for new events.
+	DefaultInterval = time.Second
+	// DefaultBatchSize is default maximum number of events published in one transaction.
+	DefaultBatchSize = 100
+)
+
+//go:generate mockery --name Storage --filename storage.go
+
+// Storage stores events waiting for publishing.
+type Storage interface {
+	// PublishOutboxEvents passes up to limit oldest events to publish function and removes published events.
+	// Returns number of published events.
+	PublishOutboxEvents(
+		ctx context.Context,
+		limit int,
+		publish func(ctx context.Context, event models.OutboxEvent) error,
+	) (published int, err error)
+}
+
+//go:generate mockery --name Publisher --filename publisher.go
+
+// Publisher publishes messages to routing key.
+type Publisher interface {
+	Publish(ctx context.Context, routingKey string, message []byte) error
+}
+
+// Relay publishes events saved in storage's outbox.
+type Relay struct {
+	storage          Storage
+	publisher        Publisher
+	logger           *zerolog.Logger
+	interval         time.Duration
+	batchSize        int
+	routingKeyPrefix string
+	routingKeys      []routingKey
+}
+
+// routingKey is routing key of events with type prefix.
+type routingKey struct {
+	typePrefix string
+	key        string
+}
+
+// Option is Relay's option.
+type Option func(r *Relay)
+
+// NewRelay returns new Relay publishing events from storage with publisher.
+func NewRelay(storage Storage, publisher Publisher, logger *zerolog.Logger, ops ...Option) *Relay {
+	relay := &Relay{
+		storage:   storage,
+		publisher: publisher,
+		logger:    logger,
+		interval:  DefaultInterval,
+		batchSize: DefaultBatchSize,
+	}
+
+	for _, op := range ops {
+		op(relay)
+	}
+
+	return relay
+}
+
+// WithInterval sets interval of checking outbox for new events.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// WithBatchSize sets maximum number of events published in one transaction.
+func WithBatchSize(size int) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithRoutingKeyPrefix sets prefix of routing keys, events are published to prefix followed by event type.
+func WithRoutingKeyPrefix(prefix string) Option {
+	return func(r *Relay) {
+		r.routingKeyPrefix = prefix
+	}
+}
+
+// WithRoutingKey publishes events with type starting with typePrefix to provided routing key
+// instead of routing key prefix followed by event type.
+func WithRoutingKey(typePrefix, key string) Option {
+	return func(r *Relay) {
+		r.routingKeys = append(r.routingKeys, routingKey{typePrefix: typePrefix, key: key})
+	}
+}
+
+// Run publishes events until context is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.PublishEvents(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error().
+					Err(err).
+					Msg("can't publish outbox events")
+			}
+		}
+	}
+}
+
+// PublishEvents publishes all events waiting in outbox, batch by batch.
+func (r *Relay) PublishEvents(ctx context.Context) error {
+	for {
+		published, err := r.storage.PublishOutboxEvents(ctx, r.batchSize, r.publish)
+		if err != nil {
+			return err
+		}
+
+		if published < r.batchSize {
+			return nil
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, key := range r.routingKeys {
+		if strings.HasPrefix(event.Type, key.typePrefix) {
+			return r.publisher.Publish(ctx, key.key, event.Payload)
+		}
+	}
+
+	return r.publisher.Publish(ctx, r.routingKeyPrefix+event.Type, event.Payload)
+}
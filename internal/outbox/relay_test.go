@@ -0,0 +1,135 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MichalMitros/google-feed-parser/internal/outbox"
+	"github.com/MichalMitros/google-feed-parser/internal/outbox/mocks"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitPublishEvents(t *testing.T) {
+	events := []models.OutboxEvent{
+		{ID: 1, Type: "product.created", Payload: []byte(`{"productId":"1"}`)},
+		{ID: 2, Type: "product.deleted", Payload: []byte(`{"productId":"2"}`)},
+		{ID: 3, Type: "product.updated", Payload: []byte(`{"productId":"3"}`)},
+	}
+
+	tests := map[string]struct {
+		batchSize  int
+		batches    [][]models.OutboxEvent
+		publishErr error
+		storageErr error
+		wantErr    error
+	}{
+		"single batch": {
+			batchSize: 5,
+			batches:   [][]models.OutboxEvent{events},
+		},
+		"many batches": {
+			batchSize: 2,
+			batches:   [][]models.OutboxEvent{events[:2], events[2:]},
+		},
+		"full last batch": {
+			batchSize: 3,
+			batches:   [][]models.OutboxEvent{events, {}},
+		},
+		"publish error": {
+			batchSize:  5,
+			batches:    [][]models.OutboxEvent{events[:1]},
+			publishErr: assert.AnError,
+			wantErr:    assert.AnError,
+		},
+		"storage error": {
+			batchSize:  5,
+			batches:    [][]models.OutboxEvent{{}},
+			storageErr: assert.AnError,
+			wantErr:    assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			publisher := mocks.NewPublisher(t)
+			logger := zerolog.Nop()
+
+			for _, batch := range tt.batches {
+				storage.On("PublishOutboxEvents", mock.Anything, tt.batchSize, mock.Anything).
+					Return(func(
+						ctx context.Context,
+						_ int,
+						publish func(context.Context, models.OutboxEvent) error,
+					) (int, error) {
+						for ix := range batch {
+							if err := publish(ctx, batch[ix]); err != nil {
+								return ix, err
+							}
+						}
+						return len(batch), tt.storageErr
+					}).
+					Once()
+
+				for ix := range batch {
+					publisher.On("Publish", mock.Anything, "gfp."+batch[ix].Type, batch[ix].Payload).
+						Return(tt.publishErr).
+						Once()
+				}
+			}
+
+			relay := outbox.NewRelay(
+				storage,
+				publisher,
+				&logger,
+				outbox.WithBatchSize(tt.batchSize),
+				outbox.WithRoutingKeyPrefix("gfp."),
+			)
+
+			err := relay.PublishEvents(context.TODO())
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitPublishEventsRoutingKey(t *testing.T) {
+	events := []models.OutboxEvent{
+		{ID: 1, Type: "run.started", Payload: []byte(`{"shopId":1}`)},
+		{ID: 2, Type: "product.created", Payload: []byte(`{"productId":"1"}`)},
+		{ID: 3, Type: "run.finished", Payload: []byte(`{"shopId":1}`)},
+	}
+
+	storage := mocks.NewStorage(t)
+	publisher := mocks.NewPublisher(t)
+	logger := zerolog.Nop()
+
+	storage.On("PublishOutboxEvents", mock.Anything, outbox.DefaultBatchSize, mock.Anything).
+		Return(func(ctx context.Context, _ int, publish func(context.Context, models.OutboxEvent) error) (int, error) {
+			for ix := range events {
+				if err := publish(ctx, events[ix]); err != nil {
+					return ix, err
+				}
+			}
+			return len(events), nil
+		})
+	publisher.On("Publish", mock.Anything, "gfp.runs", events[0].Payload).Return(nil).Once()
+	publisher.On("Publish", mock.Anything, "gfp.product.created", events[1].Payload).Return(nil).Once()
+	publisher.On("Publish", mock.Anything, "gfp.runs", events[2].Payload).Return(nil).Once()
+
+	relay := outbox.NewRelay(
+		storage,
+		publisher,
+		&logger,
+		outbox.WithRoutingKeyPrefix("gfp."),
+		outbox.WithRoutingKey("run.", "gfp.runs"),
+	)
+
+	err := relay.PublishEvents(context.TODO())
+
+	require.NoError(t, err, "shouldn't return any error")
+}
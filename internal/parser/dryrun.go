@@ -0,0 +1,197 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
+)
+
+// changesSampleSize is maximum number of product changes stored in dry run's changes sample.
+const changesSampleSize = 50
+
+// productsDiff are statistics of changes which parsed products would make in storage.
+type productsDiff struct {
+	createdProducts   int32
+	updatedProducts   int32
+	unchangedProducts int32
+	// storedActiveProducts is number of parsed products which are stored and not deleted.
+	storedActiveProducts int32
+	// storedActiveIDs are IDs of stored not-deleted products which were parsed.
+	storedActiveIDs map[int]struct{}
+	sample          []models.ProductChange
+}
+
+// diffProducts compares batches of parsed products with stored products without writing anything.
+// Products are compared by content the same way as when they are updated, changed fields are only reported.
+func (p Parser) diffProducts(ctx context.Context, run *models.Run, input chan []models.Product) (productsDiff, error) {
+	diff := productsDiff{
+		storedActiveIDs: make(map[int]struct{}),
+		sample:          []models.ProductChange{},
+	}
+
+	for batch := range input {
+		lo.ForEach(batch, func(_ models.Product, ix int) { batch[ix].Version = run.ProductsVersion })
+		productIDs := lo.Map(batch, func(_ models.Product, ix int) string { return batch[ix].ProductID })
+		stored, err := p.storage.GetProducts(ctx, run.ShopID, productIDs)
+		if err != nil {
+			return diff, err
+		}
+
+		unchangedIDs, err := p.storage.GetUnchangedProducts(ctx, run.ShopID, batch)
+		if err != nil {
+			return diff, err
+		}
+
+		storedByID := lo.KeyBy(stored, func(product models.Product) string { return product.ProductID })
+		unchanged := lo.SliceToMap(unchangedIDs, func(id string) (string, struct{}) { return id, struct{}{} })
+
+		for ix := range batch {
+			storedProduct, ok := storedByID[batch[ix].ProductID]
+			if !ok {
+				diff.createdProducts++
+				diff.sample = addChange(diff.sample, models.ProductChange{
+					ProductID: batch[ix].ProductID,
+					Type:      models.ChangeCreated,
+				})
+				continue
+			}
+
+			if storedProduct.DeletedAt == nil {
+				diff.storedActiveProducts++
+				diff.storedActiveIDs[storedProduct.ID] = struct{}{}
+			}
+
+			if _, ok := unchanged[batch[ix].ProductID]; ok {
+				diff.unchangedProducts++
+				continue
+			}
+
+			diff.updatedProducts++
+			diff.sample = addChange(diff.sample, models.ProductChange{
+				ProductID:     batch[ix].ProductID,
+				Type:          models.ChangeUpdated,
+				ChangedFields: models.ChangedFields(&storedProduct, &batch[ix]),
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// diffDeletions sets number of products which the run would delete and adds them to run's changes sample.
+// It returns ErrSuspiciousShrink if the deletion would be skipped because of suspicious feed shrink.
+func (p Parser) diffDeletions(
+	ctx context.Context,
+	run *models.Run,
+	req models.ParseRequest,
+	removedIDs []string,
+	diff productsDiff,
+) error {
+	if req.Incremental {
+		deletedProducts, err := p.diffRemovedProducts(ctx, run, append(removedIDs, req.RemovedProductIDs...))
+		run.DeletedProducts = &deletedProducts
+
+		if err != nil {
+			return fmt.Errorf("can't get removed products: %w", err)
+		}
+
+		return nil
+	}
+
+	activeProducts, _, err := p.storage.CountProducts(ctx, run.ShopID, run.ProductsVersion)
+	if err != nil {
+		return fmt.Errorf("can't count products: %w", err)
+	}
+
+	deletedProducts := activeProducts - diff.storedActiveProducts
+
+	if !req.ForceDeletion {
+		if err := p.shrinkError(activeProducts, deletedProducts); err != nil {
+			run.DeletedProducts = lo.ToPtr(int32(0))
+			return err
+		}
+	}
+
+	run.DeletedProducts = &deletedProducts
+
+	if err := p.diffOutdatedProducts(ctx, run, diff.storedActiveIDs, deletedProducts); err != nil {
+		return fmt.Errorf("can't get outdated products: %w", err)
+	}
+
+	return nil
+}
+
+// diffOutdatedProducts adds shop's not-deleted products which weren't parsed to run's changes sample.
+// Products are read page by page until the sample is full or all outdated products are found.
+func (p Parser) diffOutdatedProducts(
+	ctx context.Context,
+	run *models.Run,
+	parsedIDs map[int]struct{},
+	outdatedProducts int32,
+) error {
+	found := int32(0)
+	afterID := 0
+	for found < outdatedProducts && len(run.ChangesSample) < changesSampleSize {
+		stored, err := p.storage.GetShopProducts(ctx, run.ShopID, models.ProductFilter{}, afterID, int(p.batchSize))
+		if err != nil {
+			return err
+		}
+
+		if len(stored) == 0 {
+			return nil
+		}
+
+		for ix := range stored {
+			if _, ok := parsedIDs[stored[ix].ID]; ok {
+				continue
+			}
+
+			found++
+			run.ChangesSample = addChange(run.ChangesSample, models.ProductChange{
+				ProductID: stored[ix].ProductID,
+				Type:      models.ChangeDeleted,
+			})
+		}
+
+		afterID = stored[len(stored)-1].ID
+	}
+
+	return nil
+}
+
+// diffRemovedProducts returns number of not-deleted products with provided IDs and adds them to run's changes sample.
+func (p Parser) diffRemovedProducts(ctx context.Context, run *models.Run, productIDs []string) (int32, error) {
+	deletedProducts := int32(0)
+
+	for _, batch := range lo.Chunk(lo.Uniq(productIDs), int(p.batchSize)) {
+		stored, err := p.storage.GetProducts(ctx, run.ShopID, batch)
+		if err != nil {
+			return deletedProducts, err
+		}
+
+		for ix := range stored {
+			if stored[ix].DeletedAt != nil {
+				continue
+			}
+
+			deletedProducts++
+			run.ChangesSample = addChange(run.ChangesSample, models.ProductChange{
+				ProductID: stored[ix].ProductID,
+				Type:      models.ChangeDeleted,
+			})
+		}
+	}
+
+	return deletedProducts, nil
+}
+
+// addChange adds change to changes sample if the sample is not full yet.
+func addChange(sample []models.ProductChange, change models.ProductChange) []models.ProductChange {
+	if len(sample) >= changesSampleSize {
+		return sample
+	}
+
+	return append(sample, change)
+}
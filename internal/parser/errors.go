@@ -0,0 +1,19 @@
+package parser
+
+import "errors"
+
+var (
+	// ErrEmptyFeedIndex is returned when feed index doesn't list any feed file.
+	ErrEmptyFeedIndex = errors.New("feed index doesn't contain any feed file")
+	// ErrNestedFeedIndex is returned when feed file listed in feed index is a feed index too.
+	ErrNestedFeedIndex = errors.New("nested feed indexes are not supported")
+	// ErrSuspiciousShrink is returned when deletion of outdated products is skipped,
+	// because the run would delete more than allowed percentage of shop's products.
+	ErrSuspiciousShrink = errors.New("suspicious feed shrink, deletion of outdated products skipped")
+	// ErrTooManyFailures is returned when number or ratio of failed products exceeds allowed maximum.
+	ErrTooManyFailures = errors.New("too many failed products")
+	// ErrUnknownDecoder is returned when shop's configuration selects decoder which isn't registered.
+	ErrUnknownDecoder = errors.New("unknown decoder")
+	// ErrParseTimeout is set as cause of cancelled context of parsing which exceeded its timeout.
+	ErrParseTimeout = errors.New("parsing timed out")
+)
@@ -34,6 +34,45 @@ func (_m *Decoder) Decode(_a0 context.Context, _a1 io.Reader, _a2 chan<- models.
 	return r0
 }
 
+// DecodeIndex provides a mock function with given fields: _a0
+func (_m *Decoder) DecodeIndex(_a0 io.Reader) ([]string, io.Reader, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecodeIndex")
+	}
+
+	var r0 []string
+	var r1 io.Reader
+	var r2 error
+	if rf, ok := ret.Get(0).(func(io.Reader) ([]string, io.Reader, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(io.Reader) []string); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(io.Reader) io.Reader); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.Reader)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(io.Reader) error); ok {
+		r2 = rf(_a0)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // NewDecoder creates a new instance of Decoder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewDecoder(t interface {
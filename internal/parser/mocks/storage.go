@@ -14,9 +14,44 @@ type Storage struct {
 	mock.Mock
 }
 
-// DeleteOldProducts provides a mock function with given fields: ctx, shopID, version, batchSize
-func (_m *Storage) DeleteOldProducts(ctx context.Context, shopID int, version int64, batchSize uint) (int32, error) {
-	ret := _m.Called(ctx, shopID, version, batchSize)
+// CountProducts provides a mock function with given fields: ctx, shopID, version
+func (_m *Storage) CountProducts(ctx context.Context, shopID int, version int64) (int32, int32, error) {
+	ret := _m.Called(ctx, shopID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountProducts")
+	}
+
+	var r0 int32
+	var r1 int32
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64) (int32, int32, error)); ok {
+		return rf(ctx, shopID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64) int32); ok {
+		r0 = rf(ctx, shopID, version)
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int64) int32); ok {
+		r1 = rf(ctx, shopID, version)
+	} else {
+		r1 = ret.Get(1).(int32)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int64) error); ok {
+		r2 = rf(ctx, shopID, version)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteOldProducts provides a mock function with given fields: ctx, shopID, runID, version, batchSize
+func (_m *Storage) DeleteOldProducts(ctx context.Context, shopID int, runID int, version int64, batchSize uint) (int32, error) {
+	ret := _m.Called(ctx, shopID, runID, version, batchSize)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteOldProducts")
@@ -24,17 +59,45 @@ func (_m *Storage) DeleteOldProducts(ctx context.Context, shopID int, version in
 
 	var r0 int32
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int64, uint) (int32, error)); ok {
-		return rf(ctx, shopID, version, batchSize)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int64, uint) (int32, error)); ok {
+		return rf(ctx, shopID, runID, version, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int64, uint) int32); ok {
+		r0 = rf(ctx, shopID, runID, version, batchSize)
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int64, uint) error); ok {
+		r1 = rf(ctx, shopID, runID, version, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteProducts provides a mock function with given fields: ctx, shopID, runID, productIDs
+func (_m *Storage) DeleteProducts(ctx context.Context, shopID int, runID int, productIDs []string) (int32, error) {
+	ret := _m.Called(ctx, shopID, runID, productIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProducts")
+	}
+
+	var r0 int32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []string) (int32, error)); ok {
+		return rf(ctx, shopID, runID, productIDs)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int64, uint) int32); ok {
-		r0 = rf(ctx, shopID, version, batchSize)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []string) int32); ok {
+		r0 = rf(ctx, shopID, runID, productIDs)
 	} else {
 		r0 = ret.Get(0).(int32)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int64, uint) error); ok {
-		r1 = rf(ctx, shopID, version, batchSize)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, []string) error); ok {
+		r1 = rf(ctx, shopID, runID, productIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -60,29 +123,57 @@ func (_m *Storage) FinishRun(ctx context.Context, run *models.Run) error {
 	return r0
 }
 
-// StartRun provides a mock function with given fields: ctx, shopURL, version
-func (_m *Storage) StartRun(ctx context.Context, shopURL string, version int64) (*models.Run, error) {
-	ret := _m.Called(ctx, shopURL, version)
+// GetProducts provides a mock function with given fields: ctx, shopID, productIDs
+func (_m *Storage) GetProducts(ctx context.Context, shopID int, productIDs []string) ([]models.Product, error) {
+	ret := _m.Called(ctx, shopID, productIDs)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StartRun")
+		panic("no return value specified for GetProducts")
 	}
 
-	var r0 *models.Run
+	var r0 []models.Product
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (*models.Run, error)); ok {
-		return rf(ctx, shopURL, version)
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) ([]models.Product, error)); ok {
+		return rf(ctx, shopID, productIDs)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *models.Run); ok {
-		r0 = rf(ctx, shopURL, version)
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) []models.Product); ok {
+		r0 = rf(ctx, shopID, productIDs)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Run)
+			r0 = ret.Get(0).([]models.Product)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
-		r1 = rf(ctx, shopURL, version)
+	if rf, ok := ret.Get(1).(func(context.Context, int, []string) error); ok {
+		r1 = rf(ctx, shopID, productIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShopConfig provides a mock function with given fields: ctx, shopID
+func (_m *Storage) GetShopConfig(ctx context.Context, shopID int) (models.ShopConfig, error) {
+	ret := _m.Called(ctx, shopID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShopConfig")
+	}
+
+	var r0 models.ShopConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.ShopConfig, error)); ok {
+		return rf(ctx, shopID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.ShopConfig); ok {
+		r0 = rf(ctx, shopID)
+	} else {
+		r0 = ret.Get(0).(models.ShopConfig)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, shopID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -90,9 +181,153 @@ func (_m *Storage) StartRun(ctx context.Context, shopURL string, version int64)
 	return r0, r1
 }
 
-// UpdateProducts provides a mock function with given fields: ctx, products, shopID
-func (_m *Storage) UpdateProducts(ctx context.Context, products []models.Product, shopID int) (int32, int32, error) {
-	ret := _m.Called(ctx, products, shopID)
+// GetShopProducts provides a mock function with given fields: ctx, shopID, filter, afterID, limit
+func (_m *Storage) GetShopProducts(ctx context.Context, shopID int, filter models.ProductFilter, afterID int, limit int) ([]models.Product, error) {
+	ret := _m.Called(ctx, shopID, filter, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShopProducts")
+	}
+
+	var r0 []models.Product
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.ProductFilter, int, int) ([]models.Product, error)); ok {
+		return rf(ctx, shopID, filter, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, models.ProductFilter, int, int) []models.Product); ok {
+		r0 = rf(ctx, shopID, filter, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Product)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, models.ProductFilter, int, int) error); ok {
+		r1 = rf(ctx, shopID, filter, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSupplementalFeeds provides a mock function with given fields: ctx, shopID
+func (_m *Storage) GetSupplementalFeeds(ctx context.Context, shopID int) ([]string, error) {
+	ret := _m.Called(ctx, shopID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSupplementalFeeds")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, shopID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, shopID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, shopID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUnchangedProducts provides a mock function with given fields: ctx, shopID, products
+func (_m *Storage) GetUnchangedProducts(ctx context.Context, shopID int, products []models.Product) ([]string, error) {
+	ret := _m.Called(ctx, shopID, products)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnchangedProducts")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.Product) ([]string, error)); ok {
+		return rf(ctx, shopID, products)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.Product) []string); ok {
+		r0 = rf(ctx, shopID, products)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []models.Product) error); ok {
+		r1 = rf(ctx, shopID, products)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewRunLease provides a mock function with given fields: ctx, runID
+func (_m *Storage) RenewRunLease(ctx context.Context, runID int) error {
+	ret := _m.Called(ctx, runID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenewRunLease")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, runID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetSupplementalFeeds provides a mock function with given fields: ctx, shopID, feedURLs
+func (_m *Storage) SetSupplementalFeeds(ctx context.Context, shopID int, feedURLs []string) error {
+	ret := _m.Called(ctx, shopID, feedURLs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSupplementalFeeds")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) error); ok {
+		r0 = rf(ctx, shopID, feedURLs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartRun provides a mock function with given fields: ctx, shopURL, run
+func (_m *Storage) StartRun(ctx context.Context, shopURL string, run *models.Run) error {
+	ret := _m.Called(ctx, shopURL, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.Run) error); ok {
+		r0 = rf(ctx, shopURL, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProducts provides a mock function with given fields: ctx, products, shopID, runID
+func (_m *Storage) UpdateProducts(ctx context.Context, products []models.Product, shopID int, runID int) (int32, int32, int32, error) {
+	ret := _m.Called(ctx, products, shopID, runID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateProducts")
@@ -100,29 +335,36 @@ func (_m *Storage) UpdateProducts(ctx context.Context, products []models.Product
 
 	var r0 int32
 	var r1 int32
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, []models.Product, int) (int32, int32, error)); ok {
-		return rf(ctx, products, shopID)
+	var r2 int32
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Product, int, int) (int32, int32, int32, error)); ok {
+		return rf(ctx, products, shopID, runID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []models.Product, int) int32); ok {
-		r0 = rf(ctx, products, shopID)
+	if rf, ok := ret.Get(0).(func(context.Context, []models.Product, int, int) int32); ok {
+		r0 = rf(ctx, products, shopID, runID)
 	} else {
 		r0 = ret.Get(0).(int32)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []models.Product, int) int32); ok {
-		r1 = rf(ctx, products, shopID)
+	if rf, ok := ret.Get(1).(func(context.Context, []models.Product, int, int) int32); ok {
+		r1 = rf(ctx, products, shopID, runID)
 	} else {
 		r1 = ret.Get(1).(int32)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, []models.Product, int) error); ok {
-		r2 = rf(ctx, products, shopID)
+	if rf, ok := ret.Get(2).(func(context.Context, []models.Product, int, int) int32); ok {
+		r2 = rf(ctx, products, shopID, runID)
 	} else {
-		r2 = ret.Error(2)
+		r2 = ret.Get(2).(int32)
 	}
 
-	return r0, r1, r2
+	if rf, ok := ret.Get(3).(func(context.Context, []models.Product, int, int) error); ok {
+		r3 = rf(ctx, products, shopID, runID)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
 }
 
 // NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
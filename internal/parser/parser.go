@@ -2,11 +2,15 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
 	"github.com/samber/lo"
 	"golang.org/x/sync/errgroup"
@@ -24,6 +28,9 @@ type Fetcher interface {
 // Decoder decodes xml feed file into parsing results.
 type Decoder interface {
 	Decode(context.Context, io.Reader, chan<- models.ParsingResult) error
+	// DecodeIndex returns urls of feed files if provided file is a feed index.
+	// Otherwise it returns nil urls and reader with the whole file.
+	DecodeIndex(io.Reader) ([]string, io.Reader, error)
 }
 
 // Clock provides times.
@@ -36,47 +43,100 @@ type Clock interface {
 
 // Storage is products and runs storage.
 type Storage interface {
-	// StartRun creates new run if there is no run for provided shop running.
-	StartRun(ctx context.Context, shopURL string, version int64) (run *models.Run, err error)
+	// StartRun saves provided run as new run of the shop if there is no run for the shop running.
+	// It sets run's ID, shop ID and creation time.
+	// Returns platform.ErrShopDisabled or platform.ErrShopDeleted if the shop is disabled or deleted,
+	// platform.ErrShopNotFound if dry run's shop doesn't exist and platform.ErrShopURLChanged for previous shop url.
+	StartRun(ctx context.Context, shopURL string, run *models.Run) error
 	// FinishRun finishes provided run and updates its statistics.
 	FinishRun(ctx context.Context, run *models.Run) error
+	// GetShopConfig returns shop's parsing configuration overriding parser's configuration.
+	GetShopConfig(ctx context.Context, shopID int) (models.ShopConfig, error)
+	// RenewRunLease extends lease of running run, so it's not considered abandoned.
+	// Returns platform.ErrRunNotRunning if the run is already finished.
+	RenewRunLease(ctx context.Context, runID int) error
 	// UpdateProducts creates new products and updates existing products and their shippings.
-	// Returns number of created and updated products.
+	// Existing products which didn't change only get new version. Changes are recorded in history of provided run.
+	// Returns number of created, updated and unchanged products.
 	UpdateProducts(
 		ctx context.Context,
 		products []models.Product,
 		shopID int,
-	) (newProducts int32, updatedProducts int32, err error)
+		runID int,
+	) (newProducts int32, updatedProducts int32, unchangedProducts int32, err error)
+	// DeleteProducts deletes from storage not-deleted shop products with provided product IDs.
+	// Deletions are recorded in history of provided run.
+	// Returns number of deleted products.
+	DeleteProducts(ctx context.Context, shopID int, runID int, productIDs []string) (deletedProducts int32, err error)
 	// DeleteOldProducts deletes from storage all not-deleted products with version lower than provided for provided shop.
+	// Deletions are recorded in history of provided run.
 	// Returns number of deleted products.
 	DeleteOldProducts(
 		ctx context.Context,
 		shopID int,
+		runID int,
 		version int64,
 		batchSize uint,
 	) (deletedProducts int32, err error)
+	// CountProducts returns number of not-deleted shop products and number of those with version lower than provided.
+	CountProducts(
+		ctx context.Context,
+		shopID int,
+		version int64,
+	) (activeProducts int32, outdatedProducts int32, err error)
+	// SetSupplementalFeeds replaces shop's supplemental feeds with provided feed urls.
+	SetSupplementalFeeds(ctx context.Context, shopID int, feedURLs []string) error
+	// GetProducts returns shop's stored products, including deleted ones, with provided product IDs.
+	GetProducts(ctx context.Context, shopID int, productIDs []string) (products []models.Product, err error)
+	// GetShopProducts returns up to limit shop's products matching filter, ordered by ID.
+	// If afterID is set, only products with greater ID are returned.
+	GetShopProducts(
+		ctx context.Context,
+		shopID int,
+		filter models.ProductFilter,
+		afterID int,
+		limit int,
+	) (products []models.Product, err error)
+	// GetUnchangedProducts returns product IDs of provided products which UpdateProducts would only set new version,
+	// because they have the same content as stored products.
+	GetUnchangedProducts(ctx context.Context, shopID int, products []models.Product) (productIDs []string, err error)
+	// GetSupplementalFeeds returns urls of shop's supplemental feeds in order in which they should be applied.
+	GetSupplementalFeeds(ctx context.Context, shopID int) (feedURLs []string, err error)
 }
 
+// DefaultLeaseRenewalInterval is default interval of renewing lease of running run.
+const DefaultLeaseRenewalInterval = 30 * time.Second
+
 // Option is custom configuration of Parser.
 type Option func(p *Parser)
 
 // Parser fetches, decodes and parses feed files.
 type Parser struct {
-	fetcher   Fetcher
-	decoder   Decoder
-	storage   Storage
-	batchSize uint
-	clock     Clock
+	fetcher               Fetcher
+	decoder               Decoder
+	storage               Storage
+	batchSize             uint
+	clock                 Clock
+	removalAvailabilities []string
+	maxDeletionPercent    float64
+	maxFailedProducts     int
+	maxFailureRatio       float64
+	leaseRenewalInterval  time.Duration
+	storageWorkers        int
+	parseTimeout          time.Duration
+	decoders              map[string]Decoder
 }
 
 // NewParser returns new Parser.
 func NewParser(fetcher Fetcher, decoder Decoder, storage Storage, batchSize uint, ops ...Option) *Parser {
 	par := &Parser{
-		fetcher:   fetcher,
-		decoder:   decoder,
-		storage:   storage,
-		batchSize: batchSize,
-		clock:     systemClock{},
+		fetcher:              fetcher,
+		decoder:              decoder,
+		storage:              storage,
+		batchSize:            batchSize,
+		clock:                systemClock{},
+		leaseRenewalInterval: DefaultLeaseRenewalInterval,
+		storageWorkers:       1,
 	}
 
 	for _, op := range ops {
@@ -86,65 +146,241 @@ func NewParser(fetcher Fetcher, decoder Decoder, storage Storage, batchSize uint
 	return par
 }
 
-// Parser parses feed files from shopURL.
-func (p Parser) Parse(ctx context.Context, shopURL string) error {
-	version := p.clock.Timestamp()
+// Parse parses shop's primary feed with attributes overridden by shop's supplemental feeds.
+// Only products from primary feed are stored, so outdated products are deleted based on primary feed.
+// In incremental mode products are only upserted and deleted products are explicitly marked as removed.
+// Returns finished run, or nil if the run couldn't be started, and error the run failed with.
+func (p Parser) Parse(ctx context.Context, req models.ParseRequest) (*models.Run, error) {
+	run := &models.Run{
+		ProductsVersion: p.clock.Timestamp(),
+		IsIncremental:   req.Incremental,
+		IsDryRun:        req.DryRun,
+	}
 
 	// insert new run in storage.
-	run, err := p.storage.StartRun(ctx, shopURL, version)
+	if err := p.storage.StartRun(ctx, req.ShopURL, run); err != nil {
+		return nil, fmt.Errorf("can't start parsing: %w", err)
+	}
+
+	if req.RunStarted != nil {
+		req.RunStarted(run.ID)
+	}
+
+	// apply shop's configuration to copy of the parser.
+	par, err := p.withShopConfig(ctx, run.ShopID)
+	if err != nil {
+		return run, p.finishParsing(context.WithoutCancel(ctx), run, err)
+	}
+
+	// fail the run if it exceeds parse timeout.
+	if par.parseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, par.parseTimeout, ErrParseTimeout)
+		defer cancel()
+	}
+
+	// renew run's lease while parsing, so the run is not considered abandoned.
+	leaseCtx, stopRenewing := par.renewLease(ctx, run.ID)
+	err = par.parseFeed(leaseCtx, run, req)
+	stopRenewing()
+
+	// report cancellation reason instead of errors caused by the cancellation.
+	if cause := context.Cause(leaseCtx); err != nil && (errors.Is(cause, platform.ErrRunNotRunning) ||
+		errors.Is(cause, platform.ErrRunCancelled) || errors.Is(cause, ErrParseTimeout)) {
+		err = cause
+	}
+
+	// finish the run even if parsing was cancelled.
+	return run, par.finishParsing(context.WithoutCancel(ctx), run, err)
+}
+
+// withShopConfig returns copy of the parser with shop's configuration applied over parser's configuration.
+func (p Parser) withShopConfig(ctx context.Context, shopID int) (Parser, error) {
+	config, err := p.storage.GetShopConfig(ctx, shopID)
+	if err != nil {
+		return p, fmt.Errorf("can't get shop config: %w", err)
+	}
+
+	var ops []Option
+	if config.BatchSize != nil {
+		ops = append(ops, WithBatchSize(*config.BatchSize))
+	}
+	if config.StorageWorkers != nil {
+		ops = append(ops, WithStorageWorkers(*config.StorageWorkers))
+	}
+	if config.ParseTimeout != nil {
+		ops = append(ops, WithParseTimeout(*config.ParseTimeout))
+	}
+	if config.RemovalAvailabilities != nil {
+		ops = append(ops, WithRemovalAvailabilities(config.RemovalAvailabilities...))
+	}
+	if config.MaxDeletionPercent != nil {
+		ops = append(ops, WithMaxDeletionPercent(*config.MaxDeletionPercent))
+	}
+	if config.MaxFailedProducts != nil || config.MaxFailureRatio != nil {
+		ops = append(ops, WithMaxFailures(
+			lo.FromPtrOr(config.MaxFailedProducts, p.maxFailedProducts),
+			lo.FromPtrOr(config.MaxFailureRatio, p.maxFailureRatio),
+		))
+	}
+
+	par := p
+	for _, op := range ops {
+		op(&par)
+	}
+
+	if config.Decoder != nil {
+		decoder, ok := p.decoders[*config.Decoder]
+		if !ok {
+			return p, fmt.Errorf("%w: %s", ErrUnknownDecoder, *config.Decoder)
+		}
+		par.decoder = decoder
+	}
+
+	return par, nil
+}
+
+// parseFeed parses feeds, stores products and deletes outdated or removed products.
+// It returns error which should be set as run's status.
+func (p Parser) parseFeed(ctx context.Context, run *models.Run, req models.ParseRequest) error {
+	// load supplemental feeds.
+	overrides, err := p.loadSupplementalFeeds(ctx, run.ShopID, req.SupplementalFeedURLs, req.DryRun)
 	if err != nil {
-		return fmt.Errorf("can't start parsing: %w", err)
+		return err
 	}
 
 	// fetch feed file.
-	xmlFile, err := p.fetcher.FetchFile(ctx, shopURL)
+	xmlFile, err := p.fetcher.FetchFile(ctx, req.ShopURL)
 	if err != nil {
-		return p.finishParsing(ctx, run, fmt.Errorf("can't fetch feed file: %w", err))
+		return fmt.Errorf("can't fetch feed file: %w", err)
 	}
 	defer xmlFile.Close()
 
 	// parse products.
-	createdProducts, updatedProducts, failedProducts, err := p.parseProducts(ctx, version, run.ShopID, xmlFile)
+	stats, diff, err := p.parseProducts(ctx, run, req, xmlFile, overrides)
+	if err != nil {
+		return err
+	}
 
-	run.CreatedProducts = &createdProducts
-	run.UpdatedProducts = &updatedProducts
-	run.FailedProducts = &failedProducts
+	// fail run if too many products failed, so products missing because of failures are not deleted.
+	if err := p.checkFailures(stats); err != nil {
+		return err
+	}
 
-	if err != nil {
-		return p.finishParsing(ctx, run, err)
+	// don't delete any product if parsing was cancelled in the meantime.
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+
+	if req.DryRun {
+		// only count products which would be deleted.
+		return p.diffDeletions(ctx, run, req, stats.removedIDs, diff)
+	}
+
+	if req.Incremental {
+		// delete only products explicitly marked as removed.
+		removedIDs := append(stats.removedIDs, req.RemovedProductIDs...)
+		deletedProducts, err := p.deleteRemovedProducts(ctx, run.ShopID, run.ID, removedIDs)
+		run.DeletedProducts = &deletedProducts
+
+		if err != nil {
+			return fmt.Errorf("can't delete removed products: %w", err)
+		}
+
+		return nil
+	}
+
+	// skip deletion if feed shrank suspiciously.
+	if !req.ForceDeletion {
+		if err := p.checkShrink(ctx, run.ShopID, run.ProductsVersion); err != nil {
+			run.DeletedProducts = lo.ToPtr(int32(0))
+			return err
+		}
 	}
 
 	// delete outdated products.
-	deletedProducts, err := p.storage.DeleteOldProducts(ctx, run.ShopID, version, p.batchSize)
+	deletedProducts, err := p.storage.DeleteOldProducts(ctx, run.ShopID, run.ID, run.ProductsVersion, p.batchSize)
 	run.DeletedProducts = &deletedProducts
 
 	if err != nil {
-		return p.finishParsing(ctx, run, fmt.Errorf("can't delete outdated products: %w", err))
+		return fmt.Errorf("can't delete outdated products: %w", err)
+	}
+
+	return nil
+}
+
+// renewLease periodically renews run's lease until returned stop function is called.
+// Failed renewals are retried on next tick, but if the run is not running anymore
+// (e.g. it was closed as abandoned), returned context is cancelled with the renewal error as its cause.
+func (p Parser) renewLease(ctx context.Context, runID int) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if p.leaseRenewalInterval <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(p.leaseRenewalInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := p.storage.RenewRunLease(ctx, runID)
+				if errors.Is(err, platform.ErrRunNotRunning) {
+					cancel(fmt.Errorf("can't renew run lease: %w", err))
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		cancel(nil)
+		<-done
 	}
+}
 
-	return p.finishParsing(ctx, run, nil)
+// filterStats are statistics of filtered parsing results.
+type filterStats struct {
+	// parsedProducts is number of all parsing results, including failed ones.
+	parsedProducts int
+	failedProducts int
+	// removedIDs are IDs of products marked as removed in incremental mode.
+	removedIDs []string
 }
 
+// parseProducts decodes, filters and stores products from feed file and sets run's products statistics.
+// In incremental mode products marked as removed are not stored, their IDs are returned in statistics instead.
+// In dry-run mode products are only compared with stored products and differences are returned.
 func (p Parser) parseProducts(
 	ctx context.Context,
-	version int64,
-	shopID int,
+	run *models.Run,
+	req models.ParseRequest,
 	xmlFile io.ReadCloser,
-) (int32, int32, int32, error) {
+	overrides map[string]models.Product,
+) (filterStats, productsDiff, error) {
 	parsingResults := make(chan models.ParsingResult)
 	filteredProducts := make(chan []models.Product)
-	failedProducts := int32(0)
 	createdProducts := int32(0)
 	updatedProducts := int32(0)
+	unchangedProducts := int32(0)
+	var (
+		stats filterStats
+		diff  productsDiff
+	)
 
 	errGroup, egCtx := errgroup.WithContext(ctx)
 
-	// decode feed file.
+	// decode feed file, decoding error doesn't cancel the pipeline, so already decoded products are processed.
+	var decodeErr error
 	errGroup.Go(func() error {
 		defer close(parsingResults)
-		if err := p.decoder.Decode(egCtx, xmlFile, parsingResults); err != nil {
-			return fmt.Errorf("can't decode feed file: %w", err)
-		}
+		decodeErr = p.decodeFeed(egCtx, req.ShopURL, xmlFile, parsingResults)
 		return nil
 	})
 
@@ -152,44 +388,257 @@ func (p Parser) parseProducts(
 	errGroup.Go(func() error {
 		defer close(filteredProducts)
 
-		failed, err := p.filterProducts(egCtx, parsingResults, filteredProducts)
+		filtered, err := p.filterProducts(egCtx, parsingResults, filteredProducts, overrides, req.Incremental)
 		if err != nil {
 			return fmt.Errorf("can't filter products: %w", err)
 		}
-		_ = atomic.AddInt32(&failedProducts, int32(failed))
+		stats = filtered
 
 		return nil
 	})
 
+	// compare products with stored products in dry-run mode.
+	if req.DryRun {
+		errGroup.Go(func() error {
+			var err error
+			diff, err = p.diffProducts(egCtx, run, filteredProducts)
+			if err != nil {
+				return fmt.Errorf("can't compare products: %w", err)
+			}
+
+			return nil
+		})
+
+		// decoding error is read only after all goroutines finished.
+		waitErr := errGroup.Wait()
+		err := errors.Join(decodeErr, waitErr)
+
+		run.CreatedProducts = &diff.createdProducts
+		run.UpdatedProducts = &diff.updatedProducts
+		run.UnchangedProducts = &diff.unchangedProducts
+		run.FailedProducts = lo.ToPtr(int32(stats.failedProducts))
+		run.ChangesSample = diff.sample
+
+		return stats, diff, err
+	}
+
+	// partition products between storage workers.
+	workersInputs := []chan []models.Product{filteredProducts}
+	if p.storageWorkers > 1 {
+		workersInputs = make([]chan []models.Product, p.storageWorkers)
+		for ix := range workersInputs {
+			workersInputs[ix] = make(chan []models.Product)
+		}
+
+		errGroup.Go(func() error {
+			return p.partitionProducts(egCtx, filteredProducts, workersInputs)
+		})
+	}
+
 	// update products.
-	errGroup.Go(func() error {
-		created, updated, err := p.updateProducts(egCtx, shopID, version, filteredProducts)
-		_ = atomic.AddInt32(&createdProducts, created)
-		_ = atomic.AddInt32(&updatedProducts, updated)
+	for _, input := range workersInputs {
+		errGroup.Go(func() error {
+			created, updated, unchanged, err := p.updateProducts(egCtx, run.ShopID, run.ID, run.ProductsVersion, input)
+			_ = atomic.AddInt32(&createdProducts, created)
+			_ = atomic.AddInt32(&updatedProducts, updated)
+			_ = atomic.AddInt32(&unchangedProducts, unchanged)
+
+			if err != nil {
+				return fmt.Errorf("can't update products: %w", err)
+			}
+
+			return nil
+		})
+	}
+
+	waitErr := errGroup.Wait()
+	err := errors.Join(decodeErr, waitErr)
+
+	run.CreatedProducts = &createdProducts
+	run.UpdatedProducts = &updatedProducts
+	run.UnchangedProducts = &unchangedProducts
+	run.FailedProducts = lo.ToPtr(int32(stats.failedProducts))
+
+	return stats, diff, err
+}
 
+// checkFailures returns ErrTooManyFailures if number or ratio of failed products exceeds configured maximum.
+func (p Parser) checkFailures(stats filterStats) error {
+	if p.maxFailedProducts > 0 && stats.failedProducts > p.maxFailedProducts {
+		return fmt.Errorf("%w: %d products failed, max %d allowed",
+			ErrTooManyFailures, stats.failedProducts, p.maxFailedProducts)
+	}
+
+	if p.maxFailureRatio > 0 && stats.parsedProducts > 0 &&
+		float64(stats.failedProducts)/float64(stats.parsedProducts) > p.maxFailureRatio {
+		return fmt.Errorf("%w: %d of %d products failed, max failure ratio is %g",
+			ErrTooManyFailures, stats.failedProducts, stats.parsedProducts, p.maxFailureRatio)
+	}
+
+	return nil
+}
+
+// deleteRemovedProducts deletes products with provided IDs in batches and returns number of deleted products.
+func (p Parser) deleteRemovedProducts(ctx context.Context, shopID, runID int, productIDs []string) (int32, error) {
+	deletedProducts := int32(0)
+
+	for _, batch := range lo.Chunk(lo.Uniq(productIDs), int(p.batchSize)) {
+		deleted, err := p.storage.DeleteProducts(ctx, shopID, runID, batch)
+		deletedProducts += deleted
 		if err != nil {
-			return fmt.Errorf("can't update products: %w", err)
+			return deletedProducts, err
 		}
+	}
+
+	return deletedProducts, nil
+}
 
+// checkShrink returns ErrSuspiciousShrink if more than max deletion percent of shop's products would be deleted.
+// Guard is disabled if max deletion percent is not set.
+func (p Parser) checkShrink(ctx context.Context, shopID int, version int64) error {
+	if p.maxDeletionPercent <= 0 {
 		return nil
-	})
+	}
+
+	activeProducts, outdatedProducts, err := p.storage.CountProducts(ctx, shopID, version)
+	if err != nil {
+		return fmt.Errorf("can't count products: %w", err)
+	}
+
+	return p.shrinkError(activeProducts, outdatedProducts)
+}
+
+// shrinkError returns ErrSuspiciousShrink if deleted products are more than max deletion percent of active products.
+func (p Parser) shrinkError(activeProducts, deletedProducts int32) error {
+	if p.maxDeletionPercent <= 0 || activeProducts == 0 {
+		return nil
+	}
+
+	if float64(deletedProducts)/float64(activeProducts)*100 > p.maxDeletionPercent {
+		return fmt.Errorf("%w: %d of %d products would be deleted", ErrSuspiciousShrink, deletedProducts, activeProducts)
+	}
+
+	return nil
+}
+
+// loadSupplementalFeeds replaces shop's supplemental feeds if new feed urls are provided
+// and returns products attributes overrides decoded from shop's supplemental feeds.
+// In dry-run mode provided feed urls are used without replacing stored ones.
+func (p Parser) loadSupplementalFeeds(
+	ctx context.Context,
+	shopID int,
+	newFeedURLs *[]string,
+	dryRun bool,
+) (map[string]models.Product, error) {
+	if newFeedURLs != nil {
+		if !dryRun {
+			if err := p.storage.SetSupplementalFeeds(ctx, shopID, *newFeedURLs); err != nil {
+				return nil, fmt.Errorf("can't set supplemental feeds: %w", err)
+			}
+		}
+
+		return p.loadOverrides(ctx, *newFeedURLs)
+	}
+
+	feedURLs, err := p.storage.GetSupplementalFeeds(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get supplemental feeds: %w", err)
+	}
+
+	return p.loadOverrides(ctx, feedURLs)
+}
+
+// decodeFeed decodes feed file or, if the file is a feed index, all feed files listed in it.
+// Any failed feed file fails the whole decoding, so outdated products are never deleted based on partial feed.
+func (p Parser) decodeFeed(
+	ctx context.Context,
+	shopURL string,
+	xmlFile io.Reader,
+	output chan<- models.ParsingResult,
+) error {
+	partURLs, feedFile, err := p.decoder.DecodeIndex(xmlFile)
+	if err != nil {
+		return fmt.Errorf("can't decode feed index: %w", err)
+	}
+
+	if partURLs == nil {
+		if err := p.decoder.Decode(ctx, feedFile, output); err != nil {
+			return fmt.Errorf("can't decode feed file: %w", err)
+		}
+		return nil
+	}
+
+	if len(partURLs) == 0 {
+		return ErrEmptyFeedIndex
+	}
+
+	for _, partURL := range partURLs {
+		if err := p.decodeFeedPart(ctx, shopURL, partURL, output); err != nil {
+			return fmt.Errorf("can't parse feed file %s: %w", partURL, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeFeedPart fetches and decodes single feed file listed in feed index.
+func (p Parser) decodeFeedPart(
+	ctx context.Context,
+	indexURL string,
+	partURL string,
+	output chan<- models.ParsingResult,
+) error {
+	partURL, err := resolveURL(indexURL, partURL)
+	if err != nil {
+		return err
+	}
 
-	err := errGroup.Wait()
+	partFile, err := p.fetcher.FetchFile(ctx, partURL)
+	if err != nil {
+		return fmt.Errorf("can't fetch feed file: %w", err)
+	}
+	defer partFile.Close()
+
+	nestedURLs, feedFile, err := p.decoder.DecodeIndex(partFile)
+	if err != nil {
+		return fmt.Errorf("can't decode feed index: %w", err)
+	}
+
+	if nestedURLs != nil {
+		return ErrNestedFeedIndex
+	}
 
-	return createdProducts, updatedProducts, failedProducts, err
+	if err := p.decoder.Decode(ctx, feedFile, output); err != nil {
+		return fmt.Errorf("can't decode feed file: %w", err)
+	}
+
+	return nil
 }
 
 func (p Parser) filterProducts(
 	ctx context.Context,
 	input <-chan models.ParsingResult,
 	output chan []models.Product,
-) (int, error) {
-	failedProducts := 0
+	overrides map[string]models.Product,
+	incremental bool,
+) (filterStats, error) {
+	var stats filterStats
 	batch := make([]models.Product, 0, p.batchSize)
 
 	for result := range input {
+		stats.parsedProducts++
+
 		if result.Error != nil {
-			failedProducts++
+			stats.failedProducts++
+			continue
+		}
+
+		if override, ok := overrides[result.Product.ProductID]; ok {
+			applyOverride(&result.Product, &override)
+		}
+
+		if incremental && p.isRemoved(&result.Product) {
+			stats.removedIDs = append(stats.removedIDs, result.Product.ProductID)
 			continue
 		}
 
@@ -197,7 +646,7 @@ func (p Parser) filterProducts(
 		if len(batch) == int(p.batchSize) {
 			select {
 			case <-ctx.Done():
-				return failedProducts, ctx.Err()
+				return stats, ctx.Err()
 			case output <- batch:
 			}
 			batch = make([]models.Product, 0, p.batchSize)
@@ -207,34 +656,49 @@ func (p Parser) filterProducts(
 	if len(batch) > 0 {
 		select {
 		case <-ctx.Done():
-			return failedProducts, ctx.Err()
+			return stats, ctx.Err()
 		case output <- batch:
 		}
 	}
 
-	return failedProducts, nil
+	return stats, nil
+}
+
+// isRemoved returns true if product's availability is one of removal availabilities.
+func (p Parser) isRemoved(product *models.Product) bool {
+	availability := strings.ToLower(strings.TrimSpace(product.Availability))
+	return lo.Contains(p.removalAvailabilities, availability)
 }
 
 func (p Parser) updateProducts(
 	ctx context.Context,
 	shopID int,
+	runID int,
 	version int64,
-	input chan []models.Product,
-) (int32, int32, error) {
+	input <-chan []models.Product,
+) (int32, int32, int32, error) {
 	createdProducts := int32(0)
 	updatedProducts := int32(0)
+	unchangedProducts := int32(0)
 
+	var err error
 	for batch := range input {
-		lo.ForEach(batch, func(_ models.Product, ix int) { batch[ix].Version = version })
-		created, updated, err := p.storage.UpdateProducts(ctx, batch, shopID)
+		// input is drained after failure, so all parsing results are counted.
 		if err != nil {
-			return createdProducts, updatedProducts, err
+			continue
+		}
+
+		lo.ForEach(batch, func(_ models.Product, ix int) { batch[ix].Version = version })
+		var created, updated, unchanged int32
+		if created, updated, unchanged, err = p.storage.UpdateProducts(ctx, batch, shopID, runID); err != nil {
+			continue
 		}
 		createdProducts += created
 		updatedProducts += updated
+		unchangedProducts += unchanged
 	}
 
-	return createdProducts, updatedProducts, nil
+	return createdProducts, updatedProducts, unchangedProducts, err
 }
 
 func (p Parser) finishParsing(ctx context.Context, run *models.Run, status error) error {
@@ -242,6 +706,7 @@ func (p Parser) finishParsing(ctx context.Context, run *models.Run, status error
 		run.StatusMessage = lo.ToPtr(status.Error())
 	}
 	run.IsSuccess = lo.ToPtr(status == nil)
+	run.Status = runStatus(status)
 	run.FinishedAt = p.clock.Now()
 
 	err := p.storage.FinishRun(ctx, run)
@@ -256,9 +721,110 @@ func (p Parser) finishParsing(ctx context.Context, run *models.Run, status error
 	return status
 }
 
+// runStatus returns status of run finished with provided error.
+func runStatus(err error) models.RunStatus {
+	switch {
+	case err == nil:
+		return models.RunStatusSucceeded
+	case errors.Is(err, ErrSuspiciousShrink):
+		return models.RunStatusSuspiciousShrink
+	case errors.Is(err, platform.ErrRunCancelled):
+		return models.RunStatusCancelled
+	default:
+		return models.RunStatusFailed
+	}
+}
+
+// resolveURL resolves feed file url relative to feed index url.
+func resolveURL(indexURL, partURL string) (string, error) {
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return "", fmt.Errorf("can't parse feed index url: %w", err)
+	}
+
+	ref, err := url.Parse(partURL)
+	if err != nil {
+		return "", fmt.Errorf("can't parse feed file url: %w", err)
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
 // WithClock sets Parser's custom Clock.
 func WithClock(c Clock) Option {
 	return func(p *Parser) {
 		p.clock = c
 	}
 }
+
+// WithRemovalAvailabilities sets availability values marking products as removed in incremental feeds.
+func WithRemovalAvailabilities(availabilities ...string) Option {
+	return func(p *Parser) {
+		p.removalAvailabilities = lo.Map(availabilities, func(availability string, _ int) string {
+			return strings.ToLower(strings.TrimSpace(availability))
+		})
+	}
+}
+
+// WithMaxDeletionPercent sets maximum percentage of shop's products which can be deleted by single run.
+// If more products would be deleted, deletion is skipped and run is finished with suspicious shrink status.
+func WithMaxDeletionPercent(percent float64) Option {
+	return func(p *Parser) {
+		p.maxDeletionPercent = percent
+	}
+}
+
+// WithMaxFailures sets maximum number and ratio (from 0 to 1) of failed products in single run.
+// If any of them is exceeded, run fails before deleting any product. Zero value disables the limit.
+func WithMaxFailures(count int, ratio float64) Option {
+	return func(p *Parser) {
+		p.maxFailedProducts = count
+		p.maxFailureRatio = ratio
+	}
+}
+
+// WithLeaseRenewalInterval sets interval of renewing lease of running run.
+// It should be a few times shorter than storage's run lease duration. Zero value disables renewing.
+func WithLeaseRenewalInterval(interval time.Duration) Option {
+	return func(p *Parser) {
+		p.leaseRenewalInterval = interval
+	}
+}
+
+// WithBatchSize sets number of products stored in one batch. Zero value is ignored.
+func WithBatchSize(size uint) Option {
+	return func(p *Parser) {
+		if size > 0 {
+			p.batchSize = size
+		}
+	}
+}
+
+// WithParseTimeout sets maximum duration of parsing, the run fails when it's exceeded. Zero value disables the timeout.
+func WithParseTimeout(timeout time.Duration) Option {
+	return func(p *Parser) {
+		p.parseTimeout = timeout
+	}
+}
+
+// WithDecoder registers decoder with provided name, so it can be selected by shop's configuration.
+// Shops without selected decoder use parser's default decoder.
+func WithDecoder(name string, decoder Decoder) Option {
+	return func(p *Parser) {
+		if p.decoders == nil {
+			p.decoders = make(map[string]Decoder)
+		}
+		p.decoders[name] = decoder
+	}
+}
+
+// WithStorageWorkers sets number of workers storing batches of products concurrently.
+// Products are partitioned between workers by product ID, so workers never update the same products.
+// Values lower than 1 are ignored.
+func WithStorageWorkers(workers int) Option {
+	return func(p *Parser) {
+		if workers > 0 {
+			p.storageWorkers = workers
+		}
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"io"
 	"math/rand"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/MichalMitros/google-feed-parser/internal/parser"
 	"github.com/MichalMitros/google-feed-parser/internal/parser/mocks"
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models/modelstesting"
 	"github.com/go-faker/faker/v4"
@@ -66,20 +69,23 @@ func TestUnitParse(t *testing.T) {
 	}
 
 	wantNewProducts := 4
-	wantUpdatedProducts := 3
+	wantUpdatedProducts := 2
+	wantUnchangedProducts := 1
 	wantDeletedProducts := rand.Int31()
 	wantFailedProducts := 2
 	wantRun := &models.Run{
-		ID:              runID,
-		ShopID:          shopID,
-		CreatedAt:       createdAt,
-		FinishedAt:      &now,
-		IsSuccess:       lo.ToPtr(true),
-		CreatedProducts: lo.ToPtr(int32(wantNewProducts)),
-		UpdatedProducts: lo.ToPtr(int32(wantUpdatedProducts)),
-		DeletedProducts: lo.ToPtr(wantDeletedProducts),
-		FailedProducts:  lo.ToPtr(int32(wantFailedProducts)),
-		ProductsVersion: version,
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(wantNewProducts)),
+		UpdatedProducts:   lo.ToPtr(int32(wantUpdatedProducts)),
+		DeletedProducts:   lo.ToPtr(wantDeletedProducts),
+		FailedProducts:    lo.ToPtr(int32(wantFailedProducts)),
+		ProductsVersion:   version,
+		UnchangedProducts: lo.ToPtr(int32(wantUnchangedProducts)),
 	}
 
 	fetcher := mocks.NewFetcher(t)
@@ -87,13 +93,15 @@ func TestUnitParse(t *testing.T) {
 	storage := mocks.NewStorage(t)
 
 	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 	mockFetcher(fetcher, shopURL, nil)
 	mockDecoder(decoder, results, nil)
-	for ix := range toUpdate {
-		// first products is always new, second (if exists) is updated
-		mockStorageUpdateProducts(storage, toUpdate[ix], run.ShopID, 1, int32(len(toUpdate[ix])-1), nil)
-	}
-	mockStorageDeleteOldProducts(storage, run.ShopID, version, batchSize, wantDeletedProducts, nil)
+	// first product is always new, second (if exists) is updated, except in third batch where it is unchanged
+	mockStorageUpdateProducts(storage, toUpdate[0], run.ShopID, run.ID, 1, 1, 0, nil)
+	mockStorageUpdateProducts(storage, toUpdate[1], run.ShopID, run.ID, 1, 1, 0, nil)
+	mockStorageUpdateProducts(storage, toUpdate[2], run.ShopID, run.ID, 1, 0, 1, nil)
+	mockStorageUpdateProducts(storage, toUpdate[3], run.ShopID, run.ID, 1, 0, 0, nil)
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, wantDeletedProducts, nil)
 	mockStorageFinishRun(storage, wantRun, nil)
 
 	par := parser.NewParser(
@@ -104,9 +112,10 @@ func TestUnitParse(t *testing.T) {
 		parser.WithClock(fakeClock{timestamp: version, now: &now}),
 	)
 
-	err := par.Parse(context.TODO(), shopURL)
+	finishedRun, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 	require.NoError(t, err, "shouldn't return any error")
+	require.Equal(t, wantRun, finishedRun, "should return finished run")
 }
 
 func TestUnitParseStorageError(t *testing.T) {
@@ -132,13 +141,14 @@ func TestUnitParseStorageError(t *testing.T) {
 			parser.WithClock(fakeClock{timestamp: version, now: &now}),
 		)
 
-		err := par.Parse(context.TODO(), shopURL)
+		startedRun, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 		require.ErrorContains(t, err,
 			"can't start parsing",
 			"should return error about failed parsing start",
 		)
 		require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
+		require.Nil(t, startedRun, "shouldn't return run which wasn't started")
 	})
 
 	t.Run("update products error", func(t *testing.T) {
@@ -158,16 +168,18 @@ func TestUnitParseStorageError(t *testing.T) {
 		wantUpdatedProducts := 1
 		wantFailedProducts := 2
 		wantRun := &models.Run{
-			ID:              runID,
-			ShopID:          shopID,
-			CreatedAt:       createdAt,
-			FinishedAt:      &now,
-			IsSuccess:       lo.ToPtr(false),
-			StatusMessage:   lo.ToPtr("can't update products: assert.AnError general error for testing"),
-			CreatedProducts: lo.ToPtr(int32(wantNewProducts)),
-			UpdatedProducts: lo.ToPtr(int32(wantUpdatedProducts)),
-			FailedProducts:  lo.ToPtr(int32(wantFailedProducts)),
-			ProductsVersion: version,
+			ID:                runID,
+			ShopID:            shopID,
+			CreatedAt:         createdAt,
+			FinishedAt:        &now,
+			IsSuccess:         lo.ToPtr(false),
+			Status:            models.RunStatusFailed,
+			StatusMessage:     lo.ToPtr("can't update products: assert.AnError general error for testing"),
+			CreatedProducts:   lo.ToPtr(int32(wantNewProducts)),
+			UpdatedProducts:   lo.ToPtr(int32(wantUpdatedProducts)),
+			UnchangedProducts: lo.ToPtr(int32(0)),
+			FailedProducts:    lo.ToPtr(int32(wantFailedProducts)),
+			ProductsVersion:   version,
 		}
 
 		fetcher := mocks.NewFetcher(t)
@@ -175,10 +187,11 @@ func TestUnitParseStorageError(t *testing.T) {
 		storage := mocks.NewStorage(t)
 
 		mockStorageStartRun(storage, shopURL, run, nil)
+		mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 		mockFetcher(fetcher, shopURL, nil)
 		mockDecoder(decoder, results[:6], nil)
-		mockStorageUpdateProducts(storage, toUpdate[0], run.ShopID, 1, 1, nil)
-		mockStorageUpdateProducts(storage, toUpdate[1], run.ShopID, 0, 0, assert.AnError)
+		mockStorageUpdateProducts(storage, toUpdate[0], run.ShopID, run.ID, 1, 1, 0, nil)
+		mockStorageUpdateProducts(storage, toUpdate[1], run.ShopID, run.ID, 0, 0, 0, assert.AnError)
 		mockStorageFinishRun(storage, wantRun, nil)
 
 		par := parser.NewParser(
@@ -189,7 +202,7 @@ func TestUnitParseStorageError(t *testing.T) {
 			parser.WithClock(fakeClock{timestamp: version, now: &now}),
 		)
 
-		err := par.Parse(context.TODO(), shopURL)
+		_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 		require.ErrorContains(t, err,
 			"can't update products",
@@ -219,17 +232,19 @@ func TestUnitParseStorageError(t *testing.T) {
 		wantDeletedProducts := rand.Int31()
 		wantFailedProducts := 2
 		wantRun := &models.Run{
-			ID:              runID,
-			ShopID:          shopID,
-			CreatedAt:       createdAt,
-			FinishedAt:      &now,
-			IsSuccess:       lo.ToPtr(false),
-			StatusMessage:   lo.ToPtr("can't delete outdated products: assert.AnError general error for testing"),
-			CreatedProducts: lo.ToPtr(int32(wantNewProducts)),
-			UpdatedProducts: lo.ToPtr(int32(wantUpdatedProducts)),
-			DeletedProducts: lo.ToPtr(wantDeletedProducts),
-			FailedProducts:  lo.ToPtr(int32(wantFailedProducts)),
-			ProductsVersion: version,
+			ID:                runID,
+			ShopID:            shopID,
+			CreatedAt:         createdAt,
+			FinishedAt:        &now,
+			IsSuccess:         lo.ToPtr(false),
+			Status:            models.RunStatusFailed,
+			StatusMessage:     lo.ToPtr("can't delete outdated products: assert.AnError general error for testing"),
+			CreatedProducts:   lo.ToPtr(int32(wantNewProducts)),
+			UpdatedProducts:   lo.ToPtr(int32(wantUpdatedProducts)),
+			UnchangedProducts: lo.ToPtr(int32(0)),
+			DeletedProducts:   lo.ToPtr(wantDeletedProducts),
+			FailedProducts:    lo.ToPtr(int32(wantFailedProducts)),
+			ProductsVersion:   version,
 		}
 
 		fetcher := mocks.NewFetcher(t)
@@ -237,13 +252,14 @@ func TestUnitParseStorageError(t *testing.T) {
 		storage := mocks.NewStorage(t)
 
 		mockStorageStartRun(storage, shopURL, run, nil)
+		mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 		mockFetcher(fetcher, shopURL, nil)
 		mockDecoder(decoder, results, nil)
 		for ix := range toUpdate {
 			// first products is always new, second (if exists) is updated
-			mockStorageUpdateProducts(storage, toUpdate[ix], run.ShopID, 1, int32(len(toUpdate[ix])-1), nil)
+			mockStorageUpdateProducts(storage, toUpdate[ix], run.ShopID, run.ID, 1, int32(len(toUpdate[ix])-1), 0, nil)
 		}
-		mockStorageDeleteOldProducts(storage, run.ShopID, version, batchSize, wantDeletedProducts, assert.AnError)
+		mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, wantDeletedProducts, assert.AnError)
 		mockStorageFinishRun(storage, wantRun, nil)
 
 		par := parser.NewParser(
@@ -254,7 +270,7 @@ func TestUnitParseStorageError(t *testing.T) {
 			parser.WithClock(fakeClock{timestamp: version, now: &now}),
 		)
 
-		err := par.Parse(context.TODO(), shopURL)
+		_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 		require.ErrorContains(t, err,
 			"can't delete outdated products",
@@ -277,6 +293,7 @@ func TestUnitParseStorageError(t *testing.T) {
 			CreatedAt:       createdAt,
 			FinishedAt:      &now,
 			IsSuccess:       lo.ToPtr(false),
+			Status:          models.RunStatusFailed,
 			StatusMessage:   lo.ToPtr("can't fetch feed file: assert.AnError general error for testing"),
 			ProductsVersion: version,
 		}
@@ -286,6 +303,7 @@ func TestUnitParseStorageError(t *testing.T) {
 		storage := mocks.NewStorage(t)
 
 		mockStorageStartRun(storage, shopURL, run, nil)
+		mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 		mockFetcher(fetcher, shopURL, assert.AnError)
 		mockStorageFinishRun(storage, wantRun, assert.AnError)
 
@@ -297,7 +315,7 @@ func TestUnitParseStorageError(t *testing.T) {
 			parser.WithClock(fakeClock{timestamp: version, now: &now}),
 		)
 
-		err := par.Parse(context.TODO(), shopURL)
+		_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 		require.ErrorContains(t, err, "can't finish failed parsing", "should return error about failed run finishing")
 		require.ErrorContains(t, err, "can't fetch feed file", "should return error about failed fetching")
@@ -319,6 +337,7 @@ func TestUnitParseFetcherError(t *testing.T) {
 		CreatedAt:       createdAt,
 		FinishedAt:      &now,
 		IsSuccess:       lo.ToPtr(false),
+		Status:          models.RunStatusFailed,
 		StatusMessage:   lo.ToPtr("can't fetch feed file: assert.AnError general error for testing"),
 		ProductsVersion: version,
 	}
@@ -328,6 +347,7 @@ func TestUnitParseFetcherError(t *testing.T) {
 	storage := mocks.NewStorage(t)
 
 	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 	mockFetcher(fetcher, shopURL, assert.AnError)
 	mockStorageFinishRun(storage, wantRun, nil)
 
@@ -339,7 +359,7 @@ func TestUnitParseFetcherError(t *testing.T) {
 		parser.WithClock(fakeClock{timestamp: version, now: &now}),
 	)
 
-	err := par.Parse(context.TODO(), shopURL)
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
 
 	require.ErrorContains(t, err, "can't fetch feed file", "should return error about failed fetching")
 	require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
@@ -359,26 +379,85 @@ func TestUnitParseDecoderError(t *testing.T) {
 	wantUpdatedProducts := 1
 	wantFailedProducts := 0
 	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(false),
+		Status:            models.RunStatusFailed,
+		StatusMessage:     lo.ToPtr("can't decode feed file: assert.AnError general error for testing"),
+		CreatedProducts:   lo.ToPtr(int32(wantNewProducts)),
+		UpdatedProducts:   lo.ToPtr(int32(wantUpdatedProducts)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(wantFailedProducts)),
+		ProductsVersion:   version,
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	mockFetcher(fetcher, shopURL, nil)
+	mockDecoder(decoder, results[:2], assert.AnError)
+	mockStorageUpdateProducts(storage, toUpdate, run.ShopID, run.ID, 1, 1, 0, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+	require.ErrorContains(t, err, "can't decode feed file", "should return error about failed decoding")
+	require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
+}
+
+func TestUnitParseDryRunDecoderError(t *testing.T) {
+	run := &models.Run{
 		ID:              runID,
 		ShopID:          shopID,
 		CreatedAt:       createdAt,
-		FinishedAt:      &now,
-		IsSuccess:       lo.ToPtr(false),
-		StatusMessage:   lo.ToPtr("can't decode feed file: assert.AnError general error for testing"),
-		CreatedProducts: lo.ToPtr(int32(wantNewProducts)),
-		UpdatedProducts: lo.ToPtr(int32(wantUpdatedProducts)),
-		FailedProducts:  lo.ToPtr(int32(wantFailedProducts)),
 		ProductsVersion: version,
 	}
 
+	toCompare := []models.Product{results[0].Product, results[1].Product}
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(false),
+		Status:            models.RunStatusFailed,
+		StatusMessage:     lo.ToPtr("can't decode feed file: assert.AnError general error for testing"),
+		CreatedProducts:   lo.ToPtr(int32(2)),
+		UpdatedProducts:   lo.ToPtr(int32(0)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(0)),
+		ProductsVersion:   version,
+		IsDryRun:          true,
+		ChangesSample: []models.ProductChange{
+			{ProductID: toCompare[0].ProductID, Type: models.ChangeCreated},
+			{ProductID: toCompare[1].ProductID, Type: models.ChangeCreated},
+		},
+	}
+
 	fetcher := mocks.NewFetcher(t)
 	decoder := mocks.NewDecoder(t)
 	storage := mocks.NewStorage(t)
 
 	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
 	mockFetcher(fetcher, shopURL, nil)
 	mockDecoder(decoder, results[:2], assert.AnError)
-	mockStorageUpdateProducts(storage, toUpdate, run.ShopID, 1, 1, nil)
+	storage.On("GetProducts", mock.Anything, run.ShopID, []string{toCompare[0].ProductID, toCompare[1].ProductID}).
+		Return([]models.Product{}, nil)
+	storage.On("GetUnchangedProducts", mock.Anything, run.ShopID, toCompare).Return([]string{}, nil)
 	mockStorageFinishRun(storage, wantRun, nil)
 
 	par := parser.NewParser(
@@ -389,14 +468,1228 @@ func TestUnitParseDecoderError(t *testing.T) {
 		parser.WithClock(fakeClock{timestamp: version, now: &now}),
 	)
 
-	err := par.Parse(context.TODO(), shopURL)
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL, DryRun: true})
 
 	require.ErrorContains(t, err, "can't decode feed file", "should return error about failed decoding")
 	require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
 }
 
+func TestUnitParseFeedIndex(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	// non-failed results from both feed files in batches of 2
+	toUpdate := [][]models.Product{
+		{results[0].Product, results[1].Product},
+		{results[3].Product, results[4].Product},
+		{results[6].Product, results[7].Product},
+		{results[8].Product},
+	}
+
+	wantDeletedProducts := rand.Int31()
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(4)),
+		UpdatedProducts:   lo.ToPtr(int32(3)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		DeletedProducts:   lo.ToPtr(wantDeletedProducts),
+		FailedProducts:    lo.ToPtr(int32(2)),
+		ProductsVersion:   version,
+	}
+
+	indexURL := "http://shop.com/feeds/index.xml"
+	partURLs := []string{"part-1.xml", "http://cdn.shop.com/part-2.xml"}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, indexURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	indexFile := mockFetcherFile(fetcher, indexURL, "index")
+	firstFile := mockFetcherFile(fetcher, "http://shop.com/feeds/part-1.xml", "first")
+	secondFile := mockFetcherFile(fetcher, "http://cdn.shop.com/part-2.xml", "second")
+	decoder.On("DecodeIndex", indexFile).Return(partURLs, nil, nil)
+	mockDecoderFile(decoder, firstFile, results[:3], nil)
+	mockDecoderFile(decoder, secondFile, results[3:], nil)
+	for ix := range toUpdate {
+		// first products is always new, second (if exists) is updated
+		mockStorageUpdateProducts(storage, toUpdate[ix], run.ShopID, run.ID, 1, int32(len(toUpdate[ix])-1), 0, nil)
+	}
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, wantDeletedProducts, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: indexURL})
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitParseFeedIndexError(t *testing.T) {
+	indexURL := "http://shop.com/index.xml"
+
+	t.Run("feed file fetching error", func(t *testing.T) {
+		run := &models.Run{
+			ID:              runID,
+			ShopID:          shopID,
+			CreatedAt:       createdAt,
+			ProductsVersion: version,
+		}
+
+		wantRun := &models.Run{
+			ID:         runID,
+			ShopID:     shopID,
+			CreatedAt:  createdAt,
+			FinishedAt: &now,
+			IsSuccess:  lo.ToPtr(false),
+			Status:     models.RunStatusFailed,
+			StatusMessage: lo.ToPtr(
+				"can't parse feed file part-2.xml: can't fetch feed file: assert.AnError general error for testing",
+			),
+			CreatedProducts:   lo.ToPtr(int32(1)),
+			UpdatedProducts:   lo.ToPtr(int32(1)),
+			UnchangedProducts: lo.ToPtr(int32(0)),
+			FailedProducts:    lo.ToPtr(int32(1)),
+			ProductsVersion:   version,
+		}
+
+		fetcher := mocks.NewFetcher(t)
+		decoder := mocks.NewDecoder(t)
+		storage := mocks.NewStorage(t)
+
+		mockStorageStartRun(storage, indexURL, run, nil)
+		mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+		indexFile := mockFetcherFile(fetcher, indexURL, "index")
+		firstFile := mockFetcherFile(fetcher, "http://shop.com/part-1.xml", "first")
+		fetcher.On("FetchFile", mock.Anything, "http://shop.com/part-2.xml").Return(nil, assert.AnError)
+		decoder.On("DecodeIndex", indexFile).Return([]string{"part-1.xml", "part-2.xml"}, nil, nil)
+		mockDecoderFile(decoder, firstFile, results[:3], nil)
+		mockStorageUpdateProducts(storage, []models.Product{results[0].Product, results[1].Product}, run.ShopID, run.ID, 1, 1, 0, nil)
+		mockStorageFinishRun(storage, wantRun, nil)
+
+		par := parser.NewParser(
+			fetcher,
+			decoder,
+			storage,
+			batchSize,
+			parser.WithClock(fakeClock{timestamp: version, now: &now}),
+		)
+
+		_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: indexURL})
+
+		require.ErrorContains(t, err, "can't fetch feed file", "should return error about failed fetching")
+		require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
+	})
+
+	tests := map[string]struct {
+		partURLs   []string
+		nestedURLs []string
+		wantErr    error
+	}{
+		"empty index error": {
+			partURLs: []string{},
+			wantErr:  parser.ErrEmptyFeedIndex,
+		},
+		"nested index error": {
+			partURLs:   []string{"part-1.xml"},
+			nestedURLs: []string{"part-2.xml"},
+			wantErr:    parser.ErrNestedFeedIndex,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, indexURL, run, nil)
+			mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+			indexFile := mockFetcherFile(fetcher, indexURL, "index")
+			decoder.On("DecodeIndex", indexFile).Return(tt.partURLs, nil, nil)
+			if tt.nestedURLs != nil {
+				nestedFile := mockFetcherFile(fetcher, "http://shop.com/part-1.xml", "nested")
+				decoder.On("DecodeIndex", nestedFile).Return(tt.nestedURLs, nil, nil)
+			}
+			storage.On("FinishRun", mock.Anything, mock.MatchedBy(func(r *models.Run) bool {
+				return !*r.IsSuccess && r.DeletedProducts == nil
+			})).Return(nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: indexURL})
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitParseSupplementalFeeds(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	primaryURL := "http://shop.com/feed.xml"
+	supplementalURLs := []string{"http://shop.com/first.xml", "http://shop.com/second.xml"}
+
+	primaryResults := []models.ParsingResult{
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1" })},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2" })},
+	}
+	firstResults := []models.ParsingResult{
+		{Product: models.Product{ProductID: "1", Title: "first title", Brand: lo.ToPtr("first brand")}},
+		{Product: models.Product{ProductID: "3", Title: "not existing product"}},
+		{Error: assert.AnError},
+	}
+	secondResults := []models.ParsingResult{
+		{Product: models.Product{ProductID: "1", Title: "second title", Price: "1.00 USD"}},
+	}
+
+	wantProduct := primaryResults[0].Product
+	wantProduct.Title = "second title"
+	wantProduct.Brand = lo.ToPtr("first brand")
+	wantProduct.Price = "1.00 USD"
+	wantProduct.Version = version
+	otherProduct := primaryResults[1].Product
+	otherProduct.Version = version
+
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(2)),
+		UpdatedProducts:   lo.ToPtr(int32(0)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		DeletedProducts:   lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(0)),
+		ProductsVersion:   version,
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, primaryURL, run, nil)
+	storage.On("SetSupplementalFeeds", mock.Anything, run.ShopID, supplementalURLs).Return(nil)
+	firstFile := mockFetcherFile(fetcher, supplementalURLs[0], "first")
+	secondFile := mockFetcherFile(fetcher, supplementalURLs[1], "second")
+	primaryFile := mockFetcherFile(fetcher, primaryURL, "primary")
+	mockDecoderFile(decoder, firstFile, firstResults, nil)
+	mockDecoderFile(decoder, secondFile, secondResults, nil)
+	mockDecoderFile(decoder, primaryFile, primaryResults, nil)
+	mockStorageUpdateProducts(storage, []models.Product{wantProduct, otherProduct}, run.ShopID, run.ID, 2, 0, 0, nil)
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, 0, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{
+		ShopURL:              primaryURL,
+		SupplementalFeedURLs: &supplementalURLs,
+	})
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitParseClearSupplementalFeeds(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(1)),
+		UpdatedProducts:   lo.ToPtr(int32(1)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		DeletedProducts:   lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(0)),
+		ProductsVersion:   version,
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, shopURL, run, nil)
+	// stored supplemental feeds are removed instead of being fetched.
+	storage.On("SetSupplementalFeeds", mock.Anything, run.ShopID, []string{}).Return(nil)
+	mockFetcher(fetcher, shopURL, nil)
+	mockDecoder(decoder, results[:2], nil)
+	products := []models.Product{results[0].Product, results[1].Product}
+	mockStorageUpdateProducts(storage, products, run.ShopID, run.ID, 1, 1, 0, nil)
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, 0, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{
+		ShopURL:              shopURL,
+		SupplementalFeedURLs: &[]string{},
+	})
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitParseSupplementalFeedsError(t *testing.T) {
+	supplementalURL := "http://shop.com/supplemental.xml"
+
+	tests := map[string]struct {
+		mock          func(fetcher *mocks.Fetcher, storage *mocks.Storage)
+		wantStatusMsg string
+	}{
+		"get supplemental feeds error": {
+			mock: func(_ *mocks.Fetcher, storage *mocks.Storage) {
+				mockStorageGetSupplementalFeeds(storage, shopID, nil, assert.AnError)
+			},
+			wantStatusMsg: "can't get supplemental feeds: assert.AnError general error for testing",
+		},
+		"fetch supplemental feed error": {
+			mock: func(fetcher *mocks.Fetcher, storage *mocks.Storage) {
+				mockStorageGetSupplementalFeeds(storage, shopID, []string{supplementalURL}, nil)
+				fetcher.On("FetchFile", mock.Anything, supplementalURL).Return(nil, assert.AnError)
+			},
+			wantStatusMsg: "can't parse supplemental feed http://shop.com/supplemental.xml: " +
+				"can't fetch feed file: assert.AnError general error for testing",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			wantRun := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				FinishedAt:      &now,
+				IsSuccess:       lo.ToPtr(false),
+				Status:          models.RunStatusFailed,
+				StatusMessage:   lo.ToPtr(tt.wantStatusMsg),
+				ProductsVersion: version,
+			}
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, shopURL, run, nil)
+			tt.mock(fetcher, storage)
+			mockStorageFinishRun(storage, wantRun, nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+			require.ErrorIs(t, err, assert.AnError, errShouldContainAssertErrorMsg)
+		})
+	}
+}
+
+func TestUnitParseIncremental(t *testing.T) {
+	incrementalResults := []models.ParsingResult{
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1" })},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2"; p.Availability = " Removed " })},
+		{Error: assert.AnError},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3" })},
+	}
+
+	toUpdate := []models.Product{incrementalResults[0].Product, incrementalResults[3].Product}
+	lo.ForEach(toUpdate, func(_ models.Product, ix int) { toUpdate[ix].Version = version })
+
+	tests := map[string]struct {
+		deleteErr     error
+		wantSuccess   bool
+		wantStatus    models.RunStatus
+		wantStatusMsg *string
+	}{
+		"ok": {
+			wantSuccess: true,
+			wantStatus:  models.RunStatusSucceeded,
+		},
+		"delete products error": {
+			deleteErr:     assert.AnError,
+			wantStatus:    models.RunStatusFailed,
+			wantStatusMsg: lo.ToPtr("can't delete removed products: assert.AnError general error for testing"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			wantRun := &models.Run{
+				ID:                runID,
+				ShopID:            shopID,
+				CreatedAt:         createdAt,
+				FinishedAt:        &now,
+				IsSuccess:         lo.ToPtr(tt.wantSuccess),
+				Status:            tt.wantStatus,
+				StatusMessage:     tt.wantStatusMsg,
+				CreatedProducts:   lo.ToPtr(int32(1)),
+				UpdatedProducts:   lo.ToPtr(int32(1)),
+				UnchangedProducts: lo.ToPtr(int32(0)),
+				DeletedProducts:   lo.ToPtr(int32(2)),
+				FailedProducts:    lo.ToPtr(int32(1)),
+				ProductsVersion:   version,
+				IsIncremental:     true,
+			}
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, shopURL, run, nil)
+			mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+			mockFetcher(fetcher, shopURL, nil)
+			mockDecoder(decoder, incrementalResults, nil)
+			mockStorageUpdateProducts(storage, toUpdate, run.ShopID, run.ID, 1, 1, 0, nil)
+			storage.On("DeleteProducts", mock.Anything, run.ShopID, run.ID, []string{"2", "4"}).Return(int32(2), tt.deleteErr)
+			mockStorageFinishRun(storage, wantRun, nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+				parser.WithRemovalAvailabilities("removed"),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{
+				ShopURL:           shopURL,
+				Incremental:       true,
+				RemovedProductIDs: []string{"4", "2"},
+			})
+
+			require.ErrorIs(t, err, tt.deleteErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitParseSuspiciousShrink(t *testing.T) {
+	tests := map[string]struct {
+		forceDeletion    bool
+		activeProducts   int32
+		outdatedProducts int32
+		countErr         error
+		wantDeleteCall   bool
+		wantStatus       models.RunStatus
+		wantStatusMsg    *string
+		wantErr          error
+	}{
+		"shrink below threshold": {
+			activeProducts:   100,
+			outdatedProducts: 50,
+			wantDeleteCall:   true,
+			wantStatus:       models.RunStatusSucceeded,
+		},
+		"no active products": {
+			wantDeleteCall: true,
+			wantStatus:     models.RunStatusSucceeded,
+		},
+		"forced deletion": {
+			forceDeletion:  true,
+			wantDeleteCall: true,
+			wantStatus:     models.RunStatusSucceeded,
+		},
+		"suspicious shrink": {
+			activeProducts:   100,
+			outdatedProducts: 51,
+			wantStatus:       models.RunStatusSuspiciousShrink,
+			wantStatusMsg: lo.ToPtr(
+				"suspicious feed shrink, deletion of outdated products skipped: 51 of 100 products would be deleted",
+			),
+			wantErr: parser.ErrSuspiciousShrink,
+		},
+		"count products error": {
+			countErr:      assert.AnError,
+			wantStatus:    models.RunStatusFailed,
+			wantStatusMsg: lo.ToPtr("can't count products: assert.AnError general error for testing"),
+			wantErr:       assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			wantRun := &models.Run{
+				ID:                runID,
+				ShopID:            shopID,
+				CreatedAt:         createdAt,
+				FinishedAt:        &now,
+				IsSuccess:         lo.ToPtr(tt.wantErr == nil),
+				Status:            tt.wantStatus,
+				StatusMessage:     tt.wantStatusMsg,
+				CreatedProducts:   lo.ToPtr(int32(1)),
+				UpdatedProducts:   lo.ToPtr(int32(0)),
+				UnchangedProducts: lo.ToPtr(int32(0)),
+				DeletedProducts:   lo.ToPtr(int32(0)),
+				FailedProducts:    lo.ToPtr(int32(0)),
+				ProductsVersion:   version,
+			}
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, shopURL, run, nil)
+			mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+			mockFetcher(fetcher, shopURL, nil)
+			mockDecoder(decoder, results[:1], nil)
+			mockStorageUpdateProducts(storage, []models.Product{results[0].Product}, run.ShopID, run.ID, 1, 0, 0, nil)
+			if !tt.forceDeletion {
+				storage.On("CountProducts", mock.Anything, run.ShopID, version).
+					Return(tt.activeProducts, tt.outdatedProducts, tt.countErr)
+			}
+			if tt.wantDeleteCall {
+				mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, 0, nil)
+			}
+			mockStorageFinishRun(storage, wantRun, nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+				parser.WithMaxDeletionPercent(50),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{
+				ShopURL:       shopURL,
+				ForceDeletion: tt.forceDeletion,
+			})
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitParseTooManyFailures(t *testing.T) {
+	// non-failed results in batches of 2
+	toUpdate := [][]models.Product{
+		{results[0].Product, results[1].Product},
+		{results[3].Product, results[4].Product},
+		{results[6].Product, results[7].Product},
+		{results[8].Product},
+	}
+
+	tests := map[string]struct {
+		maxFailedProducts int
+		maxFailureRatio   float64
+		wantStatusMsg     *string
+		wantErr           error
+	}{
+		"limits disabled": {},
+		"limits not exceeded": {
+			maxFailedProducts: 2,
+			maxFailureRatio:   0.25,
+		},
+		"max failed products exceeded": {
+			maxFailedProducts: 1,
+			wantStatusMsg:     lo.ToPtr("too many failed products: 2 products failed, max 1 allowed"),
+			wantErr:           parser.ErrTooManyFailures,
+		},
+		"max failure ratio exceeded": {
+			maxFailureRatio: 0.2,
+			wantStatusMsg:   lo.ToPtr("too many failed products: 2 of 9 products failed, max failure ratio is 0.2"),
+			wantErr:         parser.ErrTooManyFailures,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			wantRun := &models.Run{
+				ID:                runID,
+				ShopID:            shopID,
+				CreatedAt:         createdAt,
+				FinishedAt:        &now,
+				IsSuccess:         lo.ToPtr(tt.wantErr == nil),
+				Status:            models.RunStatusFailed,
+				StatusMessage:     tt.wantStatusMsg,
+				CreatedProducts:   lo.ToPtr(int32(4)),
+				UpdatedProducts:   lo.ToPtr(int32(3)),
+				UnchangedProducts: lo.ToPtr(int32(0)),
+				FailedProducts:    lo.ToPtr(int32(2)),
+				ProductsVersion:   version,
+			}
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, shopURL, run, nil)
+			mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+			mockFetcher(fetcher, shopURL, nil)
+			mockDecoder(decoder, results, nil)
+			for ix := range toUpdate {
+				mockStorageUpdateProducts(storage, toUpdate[ix], run.ShopID, run.ID, 1, int32(len(toUpdate[ix])-1), 0, nil)
+			}
+			if tt.wantErr == nil {
+				wantRun.Status = models.RunStatusSucceeded
+				wantRun.DeletedProducts = lo.ToPtr(int32(0))
+				mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, 0, nil)
+			}
+			mockStorageFinishRun(storage, wantRun, nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+				parser.WithMaxFailures(tt.maxFailedProducts, tt.maxFailureRatio),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitParseRunLeaseLost(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(false),
+		Status:            models.RunStatusFailed,
+		StatusMessage:     lo.ToPtr("can't renew run lease: run is not running"),
+		CreatedProducts:   lo.ToPtr(int32(0)),
+		UpdatedProducts:   lo.ToPtr(int32(0)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(0)),
+		ProductsVersion:   version,
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	mockFetcher(fetcher, shopURL, nil)
+	decoder.On("DecodeIndex", mock.Anything).Return(func(r io.Reader) ([]string, io.Reader, error) {
+		return nil, r, nil
+	})
+	// decoding lasts until parsing is cancelled.
+	decoder.On("Decode", mock.Anything, mock.Anything, mock.Anything).Return(func(
+		ctx context.Context,
+		_ io.Reader,
+		_ chan<- models.ParsingResult,
+	) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	storage.On("RenewRunLease", mock.Anything, run.ID).Return(nil).Once()
+	storage.On("RenewRunLease", mock.Anything, run.ID).Return(platform.ErrRunNotRunning).Once()
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+		parser.WithLeaseRenewalInterval(time.Millisecond),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+	require.ErrorIs(t, err, platform.ErrRunNotRunning, "should return correct error")
+}
+
+func TestUnitParseCancelled(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(false),
+		Status:            models.RunStatusCancelled,
+		StatusMessage:     lo.ToPtr("run cancelled"),
+		CreatedProducts:   lo.ToPtr(int32(1)),
+		UpdatedProducts:   lo.ToPtr(int32(1)),
+		UnchangedProducts: lo.ToPtr(int32(0)),
+		FailedProducts:    lo.ToPtr(int32(0)),
+		ProductsVersion:   version,
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	mockFetcher(fetcher, shopURL, nil)
+	// parsing is cancelled after first batch of products is stored.
+	updated := make(chan struct{})
+	decoder.On("DecodeIndex", mock.Anything).Return(func(r io.Reader) ([]string, io.Reader, error) {
+		return nil, r, nil
+	})
+	decoder.On("Decode", mock.Anything, mock.Anything, mock.Anything).Return(func(
+		ctx context.Context,
+		_ io.Reader,
+		output chan<- models.ParsingResult,
+	) error {
+		output <- results[0]
+		output <- results[1]
+		<-updated
+		cancel(platform.ErrRunCancelled)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	mockStorageUpdateProducts(storage, []models.Product{results[0].Product, results[1].Product}, run.ShopID, run.ID, 1, 1, 0, nil).
+		Run(func(_ mock.Arguments) { close(updated) })
+	storage.On("FinishRun", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }), wantRun).
+		Return(nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+	)
+
+	var startedRunID int
+	_, err := par.Parse(ctx, models.ParseRequest{
+		ShopURL:    shopURL,
+		RunStarted: func(runID int) { startedRunID = runID },
+	})
+
+	require.ErrorIs(t, err, platform.ErrRunCancelled, "should return correct error")
+	require.Equal(t, runID, startedRunID, "should report ID of started run")
+}
+
+func TestUnitParseDryRun(t *testing.T) {
+	dryRunResults := []models.ParsingResult{
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1" })},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2" })},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3" })},
+		{Product: modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "4" })},
+	}
+
+	unchanged := dryRunResults[1].Product
+	unchanged.ID = 2
+	changed := dryRunResults[2].Product
+	changed.ID = 3
+	changed.Title += " old"
+	changed.Price += " old"
+	deleted := dryRunResults[3].Product
+	deleted.ID = 4
+	deleted.DeletedAt = &createdAt
+
+	// stored products 5, 6 and 7 are not in the feed.
+	outdated := lo.Map([]int{5, 6, 7}, func(id int, _ int) models.Product {
+		return modelstesting.FakeProduct(func(p *models.Product) {
+			p.ID = id
+			p.ProductID = strconv.Itoa(id)
+		})
+	})
+
+	// parsed products are compared with version of the run.
+	versioned := lo.Map(dryRunResults, func(result models.ParsingResult, _ int) models.Product {
+		result.Product.Version = version
+		return result.Product
+	})
+
+	tests := map[string]struct {
+		maxDeletionPercent float64
+		getProductsErr     error
+		getShopProductsErr error
+		wantRun            func(run *models.Run)
+		wantErr            error
+	}{
+		"ok": {
+			wantRun: func(run *models.Run) {
+				run.IsSuccess = lo.ToPtr(true)
+				run.Status = models.RunStatusSucceeded
+				run.DeletedProducts = lo.ToPtr(int32(3))
+				run.ChangesSample = append(run.ChangesSample,
+					models.ProductChange{ProductID: "5", Type: models.ChangeDeleted},
+					models.ProductChange{ProductID: "6", Type: models.ChangeDeleted},
+					models.ProductChange{ProductID: "7", Type: models.ChangeDeleted},
+				)
+			},
+		},
+		"get outdated products error": {
+			getShopProductsErr: assert.AnError,
+			wantRun: func(run *models.Run) {
+				run.IsSuccess = lo.ToPtr(false)
+				run.Status = models.RunStatusFailed
+				run.StatusMessage = lo.ToPtr("can't get outdated products: assert.AnError general error for testing")
+				run.DeletedProducts = lo.ToPtr(int32(3))
+			},
+			wantErr: assert.AnError,
+		},
+		"suspicious shrink": {
+			maxDeletionPercent: 50,
+			wantRun: func(run *models.Run) {
+				run.IsSuccess = lo.ToPtr(false)
+				run.Status = models.RunStatusSuspiciousShrink
+				run.StatusMessage = lo.ToPtr(
+					"suspicious feed shrink, deletion of outdated products skipped: 3 of 5 products would be deleted",
+				)
+				run.DeletedProducts = lo.ToPtr(int32(0))
+			},
+			wantErr: parser.ErrSuspiciousShrink,
+		},
+		"get products error": {
+			getProductsErr: assert.AnError,
+			wantRun: func(run *models.Run) {
+				run.IsSuccess = lo.ToPtr(false)
+				run.Status = models.RunStatusFailed
+				run.StatusMessage = lo.ToPtr("can't compare products: assert.AnError general error for testing")
+				run.CreatedProducts = lo.ToPtr(int32(0))
+				run.UpdatedProducts = lo.ToPtr(int32(0))
+				run.UnchangedProducts = lo.ToPtr(int32(0))
+				run.ChangesSample = []models.ProductChange{}
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			run := &models.Run{
+				ID:              runID,
+				ShopID:          shopID,
+				CreatedAt:       createdAt,
+				ProductsVersion: version,
+			}
+
+			wantRun := &models.Run{
+				ID:                runID,
+				ShopID:            shopID,
+				CreatedAt:         createdAt,
+				FinishedAt:        &now,
+				CreatedProducts:   lo.ToPtr(int32(1)),
+				UpdatedProducts:   lo.ToPtr(int32(2)),
+				UnchangedProducts: lo.ToPtr(int32(1)),
+				FailedProducts:    lo.ToPtr(int32(0)),
+				ProductsVersion:   version,
+				IsDryRun:          true,
+				ChangesSample: []models.ProductChange{
+					{ProductID: "1", Type: models.ChangeCreated},
+					{ProductID: "3", Type: models.ChangeUpdated, ChangedFields: []string{"title", "price"}},
+					{ProductID: "4", Type: models.ChangeUpdated, ChangedFields: []string{"deleted_at"}},
+				},
+			}
+			tt.wantRun(wantRun)
+
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageStartRun(storage, shopURL, run, nil)
+			mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+			mockFetcher(fetcher, shopURL, nil)
+			mockDecoder(decoder, dryRunResults, nil)
+			storage.On("GetProducts", mock.Anything, run.ShopID, []string{"1", "2"}).
+				Return([]models.Product{unchanged}, tt.getProductsErr)
+			if tt.getProductsErr == nil {
+				storage.On("GetUnchangedProducts", mock.Anything, run.ShopID, versioned[:2]).
+					Return([]string{"2"}, nil)
+				storage.On("GetProducts", mock.Anything, run.ShopID, []string{"3", "4"}).
+					Return([]models.Product{changed, deleted}, nil)
+				storage.On("GetUnchangedProducts", mock.Anything, run.ShopID, versioned[2:]).
+					Return([]string{}, nil)
+				storage.On("CountProducts", mock.Anything, run.ShopID, version).Return(int32(5), int32(0), nil)
+			}
+			// outdated products are read page by page only when they would be deleted.
+			if tt.getProductsErr == nil && tt.maxDeletionPercent == 0 {
+				storage.On("GetShopProducts", mock.Anything, run.ShopID, models.ProductFilter{}, 0, int(batchSize)).
+					Return([]models.Product{unchanged, outdated[0]}, tt.getShopProductsErr)
+				if tt.getShopProductsErr == nil {
+					storage.On("GetShopProducts", mock.Anything, run.ShopID, models.ProductFilter{}, 5, int(batchSize)).
+						Return([]models.Product{changed, outdated[1]}, nil)
+					storage.On("GetShopProducts", mock.Anything, run.ShopID, models.ProductFilter{}, 6, int(batchSize)).
+						Return(outdated[2:], nil)
+				}
+			}
+			mockStorageFinishRun(storage, wantRun, nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+				parser.WithMaxDeletionPercent(tt.maxDeletionPercent),
+			)
+
+			_, err := par.Parse(context.TODO(), models.ParseRequest{
+				ShopURL: shopURL,
+				DryRun:  true,
+			})
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitParseWithStorageWorkers(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	wantProducts := lo.FilterMap(results, func(result models.ParsingResult, _ int) (models.Product, bool) {
+		return result.Product, result.Error == nil
+	})
+	wantDeletedProducts := rand.Int31()
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(len(wantProducts) - 1)),
+		UpdatedProducts:   lo.ToPtr(int32(1)),
+		DeletedProducts:   lo.ToPtr(wantDeletedProducts),
+		FailedProducts:    lo.ToPtr(int32(2)),
+		ProductsVersion:   version,
+		UnchangedProducts: lo.ToPtr(int32(0)),
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	mockFetcher(fetcher, shopURL, nil)
+	mockDecoder(decoder, results, nil)
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, batchSize, wantDeletedProducts, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	// first product is updated, all others are new
+	var (
+		mu      sync.Mutex
+		stored  []models.Product
+		batches [][]models.Product
+	)
+	storage.On("UpdateProducts", mock.Anything, mock.Anything, run.ShopID, run.ID).
+		Return(func(_ context.Context, batch []models.Product, _, _ int) (int32, int32, int32, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			stored = append(stored, batch...)
+			batches = append(batches, batch)
+
+			if lo.ContainsBy(batch, func(p models.Product) bool { return p.ProductID == wantProducts[0].ProductID }) {
+				return int32(len(batch) - 1), 1, 0, nil
+			}
+			return int32(len(batch)), 0, 0, nil
+		})
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+		parser.WithStorageWorkers(3),
+	)
+
+	finishedRun, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+	require.NoError(t, err, "shouldn't return any error")
+	require.Equal(t, wantRun, finishedRun, "should return finished run with aggregated statistics")
+	assert.ElementsMatch(t, wantProducts, stored, "should store each product exactly once")
+	for _, batch := range batches {
+		assert.LessOrEqual(t, len(batch), int(batchSize), "shouldn't exceed batch size")
+	}
+}
+
+func TestUnitParseWithShopConfig(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	wantProducts := lo.FilterMap(results, func(result models.ParsingResult, _ int) (models.Product, bool) {
+		return result.Product, result.Error == nil
+	})
+	wantDeletedProducts := rand.Int31()
+	wantRun := &models.Run{
+		ID:                runID,
+		ShopID:            shopID,
+		CreatedAt:         createdAt,
+		FinishedAt:        &now,
+		IsSuccess:         lo.ToPtr(true),
+		Status:            models.RunStatusSucceeded,
+		CreatedProducts:   lo.ToPtr(int32(len(wantProducts))),
+		UpdatedProducts:   lo.ToPtr(int32(0)),
+		DeletedProducts:   lo.ToPtr(wantDeletedProducts),
+		FailedProducts:    lo.ToPtr(int32(2)),
+		ProductsVersion:   version,
+		UnchangedProducts: lo.ToPtr(int32(0)),
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	shopDecoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	shopBatchSize := uint(1)
+	mockStorageGetShopConfig(storage, run.ShopID, models.ShopConfig{
+		BatchSize: &shopBatchSize,
+		Decoder:   lo.ToPtr("custom"),
+	}, nil)
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	mockFetcher(fetcher, shopURL, nil)
+	mockDecoder(shopDecoder, results, nil)
+	mockStorageDeleteOldProducts(storage, run.ShopID, run.ID, version, shopBatchSize, wantDeletedProducts, nil)
+	mockStorageFinishRun(storage, wantRun, nil)
+
+	var batches [][]models.Product
+	storage.On("UpdateProducts", mock.Anything, mock.Anything, run.ShopID, run.ID).
+		Return(func(_ context.Context, batch []models.Product, _, _ int) (int32, int32, int32, error) {
+			batches = append(batches, batch)
+			return int32(len(batch)), 0, 0, nil
+		})
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+		parser.WithDecoder("custom", shopDecoder),
+	)
+
+	finishedRun, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+	require.NoError(t, err, "shouldn't return any error")
+	require.Equal(t, wantRun, finishedRun, "should return finished run")
+	assert.Len(t, batches, len(wantProducts), "should store products in batches of shop's batch size")
+}
+
+func TestUnitParseWithShopConfigError(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	tests := map[string]struct {
+		config    models.ShopConfig
+		configErr error
+		wantErr   error
+	}{
+		"config error": {
+			configErr: assert.AnError,
+			wantErr:   assert.AnError,
+		},
+		"unknown decoder": {
+			config:  models.ShopConfig{Decoder: lo.ToPtr("unknown")},
+			wantErr: parser.ErrUnknownDecoder,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := mocks.NewFetcher(t)
+			decoder := mocks.NewDecoder(t)
+			storage := mocks.NewStorage(t)
+
+			mockStorageGetShopConfig(storage, run.ShopID, tt.config, tt.configErr)
+			mockStorageStartRun(storage, shopURL, run, nil)
+			storage.On("FinishRun", mock.Anything, mock.MatchedBy(func(r *models.Run) bool {
+				return r.Status == models.RunStatusFailed
+			})).Return(nil)
+
+			par := parser.NewParser(
+				fetcher,
+				decoder,
+				storage,
+				batchSize,
+				parser.WithClock(fakeClock{timestamp: version, now: &now}),
+			)
+
+			finishedRun, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+			require.NotNil(t, finishedRun, "should return failed run")
+		})
+	}
+}
+
+func TestUnitParseTimeout(t *testing.T) {
+	run := &models.Run{
+		ID:              runID,
+		ShopID:          shopID,
+		CreatedAt:       createdAt,
+		ProductsVersion: version,
+	}
+
+	fetcher := mocks.NewFetcher(t)
+	decoder := mocks.NewDecoder(t)
+	storage := mocks.NewStorage(t)
+
+	mockStorageGetShopConfig(storage, run.ShopID, models.ShopConfig{ParseTimeout: lo.ToPtr(time.Millisecond)}, nil)
+	mockStorageStartRun(storage, shopURL, run, nil)
+	mockStorageGetSupplementalFeeds(storage, run.ShopID, nil, nil)
+	// fetching takes longer than shop's parse timeout.
+	fetcher.On("FetchFile", mock.Anything, shopURL).Return(func(ctx context.Context, _ string) (io.ReadCloser, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	storage.On("FinishRun", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }),
+		mock.MatchedBy(func(r *models.Run) bool {
+			return r.Status == models.RunStatusFailed && *r.StatusMessage == parser.ErrParseTimeout.Error()
+		})).
+		Return(nil)
+
+	par := parser.NewParser(
+		fetcher,
+		decoder,
+		storage,
+		batchSize,
+		parser.WithClock(fakeClock{timestamp: version, now: &now}),
+		parser.WithParseTimeout(time.Hour),
+	)
+
+	_, err := par.Parse(context.TODO(), models.ParseRequest{ShopURL: shopURL})
+
+	require.ErrorIs(t, err, parser.ErrParseTimeout, "should return correct error")
+}
+
+// mockStorageStartRun mocks starting of run, started run gets ID, shop ID and creation time of provided run.
 func mockStorageStartRun(storage *mocks.Storage, shopURL string, run *models.Run, err error) {
-	storage.On("StartRun", mock.Anything, shopURL, mock.AnythingOfType("int64")).Return(run, err)
+	storage.On("StartRun", mock.Anything, shopURL, mock.AnythingOfType("*models.Run")).
+		Run(func(args mock.Arguments) {
+			started := args.Get(2).(*models.Run)
+			started.ID = run.ID
+			started.ShopID = run.ShopID
+			started.CreatedAt = run.CreatedAt
+		}).
+		Return(err)
+
+	// started run loads shop's config, tests of shop's config mock it before starting the run.
+	if err == nil {
+		mockStorageGetShopConfig(storage, run.ShopID, models.ShopConfig{}, nil)
+	}
+}
+
+func mockStorageGetShopConfig(storage *mocks.Storage, shopID int, config models.ShopConfig, err error) {
+	storage.On("GetShopConfig", mock.Anything, shopID).Return(config, err)
+}
+
+func mockStorageGetSupplementalFeeds(storage *mocks.Storage, shopID int, feedURLs []string, err error) {
+	storage.On("GetSupplementalFeeds", mock.Anything, shopID).Return(feedURLs, err)
 }
 
 func mockStorageFinishRun(storage *mocks.Storage, run *models.Run, err error) {
@@ -406,26 +1699,35 @@ func mockStorageFinishRun(storage *mocks.Storage, run *models.Run, err error) {
 func mockStorageUpdateProducts(
 	storage *mocks.Storage,
 	products []models.Product,
-	shopID int, newProducts,
-	updatedProducts int32,
+	shopID, runID int,
+	newProducts, updatedProducts, unchangedProducts int32,
 	err error,
-) {
-	storage.On("UpdateProducts", mock.Anything, products, shopID).Return(newProducts, updatedProducts, err)
+) *mock.Call {
+	return storage.On("UpdateProducts", mock.Anything, products, shopID, runID).
+		Return(newProducts, updatedProducts, unchangedProducts, err)
 }
 
 func mockStorageDeleteOldProducts(
 	storage *mocks.Storage,
-	shopID int,
+	shopID, runID int,
 	version int64,
 	batchSize uint,
 	deletedProducts int32,
 	err error,
 ) {
-	storage.On("DeleteOldProducts", mock.Anything, shopID, version, batchSize).Return(deletedProducts, err)
+	storage.On("DeleteOldProducts", mock.Anything, shopID, runID, version, batchSize).Return(deletedProducts, err)
 }
 
 func mockDecoder(decoder *mocks.Decoder, results []models.ParsingResult, err error) {
-	decoder.On("Decode", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+	mockDecoderFile(decoder, mock.Anything, results, err)
+}
+
+// mockDecoderFile mocks decoding of provided feed file, which is not a feed index.
+func mockDecoderFile(decoder *mocks.Decoder, file any, results []models.ParsingResult, err error) {
+	decoder.On("DecodeIndex", file).Return(func(r io.Reader) ([]string, io.Reader, error) {
+		return nil, r, nil
+	})
+	decoder.On("Decode", mock.Anything, file, mock.Anything).Run(func(args mock.Arguments) {
 		output := args.Get(2).(chan<- models.ParsingResult)
 		ctx := args.Get(0).(context.Context)
 		for ix := range results {
@@ -446,6 +1748,13 @@ func mockFetcher(fetcher *mocks.Fetcher, shopURL string, err error) {
 	fetcher.On("FetchFile", mock.Anything, shopURL).Return(reader, err)
 }
 
+// mockFetcherFile mocks fetching of feed file with provided content and returns the file.
+func mockFetcherFile(fetcher *mocks.Fetcher, url, content string) io.ReadCloser {
+	reader := io.NopCloser(strings.NewReader(content))
+	fetcher.On("FetchFile", mock.Anything, url).Return(reader, nil)
+	return reader
+}
+
 type fakeClock struct {
 	timestamp int64
 	now       *time.Time
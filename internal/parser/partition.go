@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+)
+
+// partitionProducts splits batches of products between partitions by product ID and sends them
+// as batches of parser's batch size to partitions' channels, which are closed when input is drained.
+// Each product ID always goes to the same partition, so batches stored concurrently by different partitions
+// never lock the same product rows.
+func (p Parser) partitionProducts(
+	ctx context.Context,
+	input <-chan []models.Product,
+	outputs []chan []models.Product,
+) error {
+	defer func() {
+		for _, output := range outputs {
+			close(output)
+		}
+	}()
+
+	batches := make([][]models.Product, len(outputs))
+
+	send := func(ix int) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case outputs[ix] <- batches[ix]:
+		}
+		batches[ix] = nil
+		return nil
+	}
+
+	for batch := range input {
+		for _, product := range batch {
+			ix := partition(product.ProductID, len(outputs))
+			if batches[ix] == nil {
+				batches[ix] = make([]models.Product, 0, p.batchSize)
+			}
+
+			batches[ix] = append(batches[ix], product)
+			if len(batches[ix]) == int(p.batchSize) {
+				if err := send(ix); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for ix := range batches {
+		if len(batches[ix]) > 0 {
+			if err := send(ix); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// partition returns number of partition, from 0 to partitions-1, of product with provided product ID.
+func partition(productID string, partitions int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(productID))
+	return int(h.Sum32() % uint32(partitions))
+}
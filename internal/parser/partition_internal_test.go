@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitPartitionProducts(t *testing.T) {
+	par := Parser{batchSize: 3}
+	products := lo.Times(20, func(_ int) models.Product {
+		return models.Product{ProductID: lo.RandomString(8, lo.AlphanumericCharset)}
+	})
+
+	input := make(chan []models.Product, 2)
+	input <- products[:10]
+	input <- products[10:]
+	close(input)
+
+	outputs := lo.Times(4, func(_ int) chan []models.Product { return make(chan []models.Product) })
+	partitioned := make([][]models.Product, len(outputs))
+
+	var wg sync.WaitGroup
+	for ix, output := range outputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range output {
+				assert.LessOrEqual(t, len(batch), 3, "shouldn't exceed batch size")
+				partitioned[ix] = append(partitioned[ix], batch...)
+			}
+		}()
+	}
+
+	err := par.partitionProducts(context.TODO(), input, outputs)
+	wg.Wait()
+
+	require.NoError(t, err, "shouldn't return any error")
+	assert.ElementsMatch(t, products, lo.Flatten(partitioned), "should send each product exactly once")
+	for ix, batch := range partitioned {
+		for _, product := range batch {
+			assert.Equal(t, ix, partition(product.ProductID, len(outputs)), "should send product to its partition")
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"golang.org/x/sync/errgroup"
+)
+
+// loadOverrides fetches and decodes supplemental feeds into products attributes overrides mapped by product ID.
+// Feeds are applied in provided order, so attributes from later feeds override attributes from earlier ones.
+func (p Parser) loadOverrides(ctx context.Context, feedURLs []string) (map[string]models.Product, error) {
+	overrides := make(map[string]models.Product)
+
+	for _, feedURL := range feedURLs {
+		if err := p.loadFeedOverrides(ctx, feedURL, overrides); err != nil {
+			return nil, fmt.Errorf("can't parse supplemental feed %s: %w", feedURL, err)
+		}
+	}
+
+	return overrides, nil
+}
+
+// loadFeedOverrides fetches and decodes single supplemental feed and merges its products into overrides.
+// Items which can't be decoded or don't have product ID are skipped, as they can't be matched with any product.
+func (p Parser) loadFeedOverrides(ctx context.Context, feedURL string, overrides map[string]models.Product) error {
+	feedFile, err := p.fetcher.FetchFile(ctx, feedURL)
+	if err != nil {
+		return fmt.Errorf("can't fetch feed file: %w", err)
+	}
+	defer feedFile.Close()
+
+	parsingResults := make(chan models.ParsingResult)
+
+	errGroup, egCtx := errgroup.WithContext(ctx)
+
+	errGroup.Go(func() error {
+		defer close(parsingResults)
+		return p.decodeFeed(egCtx, feedURL, feedFile, parsingResults)
+	})
+
+	errGroup.Go(func() error {
+		for result := range parsingResults {
+			if result.Error != nil || result.Product.ProductID == "" {
+				continue
+			}
+
+			override := overrides[result.Product.ProductID]
+			applyOverride(&override, &result.Product)
+			overrides[result.Product.ProductID] = override
+		}
+		return nil
+	})
+
+	return errGroup.Wait()
+}
+
+// applyOverride sets product attributes which are present in override.
+// Empty strings, empty slices and nil pointers are treated as not present attributes.
+func applyOverride(product, override *models.Product) {
+	overrideValue(&product.ProductID, override.ProductID)
+	overrideValue(&product.Title, override.Title)
+	overrideValue(&product.Description, override.Description)
+	overrideValue(&product.URL, override.URL)
+	overrideValue(&product.ImageURL, override.ImageURL)
+	overrideSlice(&product.AdditionalImageURLs, override.AdditionalImageURLs)
+	overrideValue(&product.Condition, override.Condition)
+	overrideValue(&product.Availability, override.Availability)
+	overrideValue(&product.Price, override.Price)
+	overridePointer(&product.SalePrice, override.SalePrice)
+	overrideSlice(&product.Shippings, override.Shippings)
+	overridePointer(&product.Brand, override.Brand)
+	overridePointer(&product.GTIN, override.GTIN)
+	overridePointer(&product.MPN, override.MPN)
+	overridePointer(&product.ProductCategory, override.ProductCategory)
+	overridePointer(&product.ProductType, override.ProductType)
+	overridePointer(&product.Color, override.Color)
+	overridePointer(&product.Size, override.Size)
+	overridePointer(&product.ItemGroupID, override.ItemGroupID)
+	overridePointer(&product.Gender, override.Gender)
+	overridePointer(&product.AgeGroup, override.AgeGroup)
+}
+
+func overrideValue(value *string, override string) {
+	if override != "" {
+		*value = override
+	}
+}
+
+func overrideSlice[T any](value *[]T, override []T) {
+	if len(override) > 0 {
+		*value = override
+	}
+}
+
+func overridePointer[T any](value **T, override *T) {
+	if override != nil {
+		*value = override
+	}
+}
@@ -4,5 +4,27 @@ import (
 	"errors"
 )
 
-// ErrAlreadyRunning is an error returned when run can't be started because previous run is not finished yet.
-var ErrAlreadyRunning = errors.New("parsing already running for this shop")
+var (
+	// ErrAlreadyRunning is an error returned when run can't be started because previous run is not finished yet.
+	ErrAlreadyRunning = errors.New("parsing already running for this shop")
+	// ErrRunNotRunning is an error returned when running run is expected, but the run is already finished.
+	ErrRunNotRunning = errors.New("run is not running")
+	// ErrRunNotFound is an error returned when run with provided ID doesn't exist.
+	ErrRunNotFound = errors.New("run not found")
+	// ErrShopNotFound is an error returned when shop with provided ID or url doesn't exist.
+	ErrShopNotFound = errors.New("shop not found")
+	// ErrShopDisabled is an error returned when disabled shop's parsing is requested.
+	ErrShopDisabled = errors.New("shop is disabled")
+	// ErrShopDeleted is an error returned when deleted shop's parsing or update is requested.
+	ErrShopDeleted = errors.New("shop is deleted")
+	// ErrShopURLChanged is an error returned when parsing of shop's previous url is requested.
+	ErrShopURLChanged = errors.New("shop url was changed")
+	// ErrShopURLTaken is an error returned when shop's url is already used by other shop.
+	ErrShopURLTaken = errors.New("shop url is already used by other shop")
+	// ErrShopScheduleNotFound is an error returned when shop doesn't have parsing schedule.
+	ErrShopScheduleNotFound = errors.New("shop schedule not found")
+	// ErrInvalidRetention is an error returned when retention time isn't whole number of days, as it's stored in days.
+	ErrInvalidRetention = errors.New("retention time must be non-negative whole number of days")
+	// ErrRunCancelled is an error set as cause of cancelled context of run cancelled on demand.
+	ErrRunCancelled = errors.New("run cancelled")
+)
@@ -0,0 +1,61 @@
+package models
+
+import "slices"
+
+// ChangedFields returns names of attributes which differ between stored and parsed product.
+// Restoring of deleted product is reported as changed "deleted_at" attribute.
+func ChangedFields(stored, parsed *Product) []string {
+	var fields []string
+	for _, change := range FieldChanges(stored, parsed) {
+		fields = append(fields, change.Field)
+	}
+
+	return fields
+}
+
+// FieldChanges returns attributes which differ between stored and parsed product with their old and new values.
+// Restoring of deleted product is reported as changed "deleted_at" attribute.
+func FieldChanges(stored, parsed *Product) []FieldChange {
+	var changes []FieldChange
+	compare := func(field string, equal bool, oldValue, newValue any) {
+		if !equal {
+			changes = append(changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+
+	compare("title", stored.Title == parsed.Title, stored.Title, parsed.Title)
+	compare("description", stored.Description == parsed.Description, stored.Description, parsed.Description)
+	compare("url", stored.URL == parsed.URL, stored.URL, parsed.URL)
+	compare("image_url", stored.ImageURL == parsed.ImageURL, stored.ImageURL, parsed.ImageURL)
+	compare("additional_image_urls", slices.Equal(stored.AdditionalImageURLs, parsed.AdditionalImageURLs),
+		stored.AdditionalImageURLs, parsed.AdditionalImageURLs)
+	compare("condition", stored.Condition == parsed.Condition, stored.Condition, parsed.Condition)
+	compare("availability", stored.Availability == parsed.Availability, stored.Availability, parsed.Availability)
+	compare("price", stored.Price == parsed.Price, stored.Price, parsed.Price)
+	compare("sale_price", equalPointers(stored.SalePrice, parsed.SalePrice), stored.SalePrice, parsed.SalePrice)
+	compare("shippings", slices.Equal(stored.Shippings, parsed.Shippings), stored.Shippings, parsed.Shippings)
+	compare("brand", equalPointers(stored.Brand, parsed.Brand), stored.Brand, parsed.Brand)
+	compare("gtin", equalPointers(stored.GTIN, parsed.GTIN), stored.GTIN, parsed.GTIN)
+	compare("mpn", equalPointers(stored.MPN, parsed.MPN), stored.MPN, parsed.MPN)
+	compare("product_category", equalPointers(stored.ProductCategory, parsed.ProductCategory),
+		stored.ProductCategory, parsed.ProductCategory)
+	compare("product_type", equalPointers(stored.ProductType, parsed.ProductType),
+		stored.ProductType, parsed.ProductType)
+	compare("color", equalPointers(stored.Color, parsed.Color), stored.Color, parsed.Color)
+	compare("size", equalPointers(stored.Size, parsed.Size), stored.Size, parsed.Size)
+	compare("item_group_id", equalPointers(stored.ItemGroupID, parsed.ItemGroupID),
+		stored.ItemGroupID, parsed.ItemGroupID)
+	compare("gender", equalPointers(stored.Gender, parsed.Gender), stored.Gender, parsed.Gender)
+	compare("age_group", equalPointers(stored.AgeGroup, parsed.AgeGroup), stored.AgeGroup, parsed.AgeGroup)
+	compare("deleted_at", stored.DeletedAt == nil, stored.DeletedAt, parsed.DeletedAt)
+
+	return changes
+}
+
+func equalPointers[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -8,17 +8,91 @@ type ParsingResult struct {
 	Error   error
 }
 
+// ParseRequest is request to parse shop's feeds.
+type ParseRequest struct {
+	// ShopURL is url of shop's primary feed.
+	ShopURL string
+	// SupplementalFeedURLs replaces shop's supplemental feeds if not nil, empty list removes all of them.
+	SupplementalFeedURLs *[]string
+	// Incremental enables incremental mode, in which feed contains only changed products
+	// and only products marked as removed are deleted.
+	Incremental bool
+	// RemovedProductIDs are IDs of products deleted in incremental mode.
+	RemovedProductIDs []string
+	// ForceDeletion disables mass-deletion guard, so outdated products are deleted regardless of their number.
+	ForceDeletion bool
+	// DryRun enables dry-run mode, in which feed is only compared with stored products and nothing is written
+	// except the run with statistics and sample of changes.
+	DryRun bool
+	// RunStarted is called with ID of the run once it's started, if not nil.
+	RunStarted func(runID int)
+}
+
 // Shop is shop model.
 type Shop struct {
-	ID        int
-	Name      string
-	URL       string
+	ID   int
+	Name string
+	URL  string
+	// Disabled shop isn't parsed, but its products are kept.
+	Disabled  bool
 	CreatedAt time.Time
 	DeletedAt *time.Time
 
 	LastRuns []Run
 }
 
+// ShopConfig is shop's parsing configuration overriding default configuration.
+// Nil values aren't set for the shop, so defaults are used.
+type ShopConfig struct {
+	BatchSize      *uint
+	StorageWorkers *int
+	// ParseTimeout is maximum duration of shop's parsing, zero disables the timeout.
+	ParseTimeout *time.Duration
+	// Decoder is name of decoder used to decode shop's feeds.
+	Decoder               *string
+	RemovalAvailabilities []string
+	MaxDeletionPercent    *float64
+	MaxFailedProducts     *int
+	MaxFailureRatio       *float64
+}
+
+// ShopSchedule is shop's parsing schedule. Cron expression is used if set, otherwise the interval.
+type ShopSchedule struct {
+	ShopID int
+	// ShopURL is feed url of the shop, used in scheduled parse commands.
+	ShopURL  string
+	Cron     *string
+	Interval *time.Duration
+	// NextRunAt is time of next scheduled parsing, nil if it isn't calculated yet.
+	NextRunAt *time.Time
+	// Running is true if shop's run is running.
+	Running bool
+}
+
+// ShopURLChange is replaced feed url of a shop.
+type ShopURLChange struct {
+	// URL is feed url of the shop before the change.
+	URL       string
+	ChangedAt time.Time
+}
+
+// RunStatus is status of parsing process run.
+type RunStatus string
+
+const (
+	// RunStatusRunning is status of not finished run.
+	RunStatusRunning RunStatus = "running"
+	// RunStatusSucceeded is status of successfully finished run.
+	RunStatusSucceeded RunStatus = "succeeded"
+	// RunStatusFailed is status of failed run.
+	RunStatusFailed RunStatus = "failed"
+	// RunStatusSuspiciousShrink is status of run which skipped deletion of outdated products,
+	// because feed shrank more than allowed.
+	RunStatusSuspiciousShrink RunStatus = "suspicious_shrink"
+	// RunStatusCancelled is status of run cancelled on demand.
+	RunStatusCancelled RunStatus = "cancelled"
+)
+
 // Run is parsing process run model.
 type Run struct {
 	ID              int
@@ -32,6 +106,96 @@ type Run struct {
 	DeletedProducts *int32
 	FailedProducts  *int32
 	ProductsVersion int64
+	IsIncremental   bool
+	Status          RunStatus
+	IsDryRun        bool
+	// UnchangedProducts is number of parsed products which didn't change since previous run.
+	UnchangedProducts *int32
+	// ChangesSample is sample of product changes found by dry run.
+	ChangesSample []ProductChange
+}
+
+// ChangeType is type of product change.
+type ChangeType string
+
+const (
+	// ChangeCreated is type of change creating new product.
+	ChangeCreated ChangeType = "created"
+	// ChangeUpdated is type of change updating stored product.
+	ChangeUpdated ChangeType = "updated"
+	// ChangeDeleted is type of change deleting stored product.
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// ProductChange is change of a single product.
+type ProductChange struct {
+	ProductID string
+	Type      ChangeType
+	// ChangedFields are names of changed product attributes, set only for updated products.
+	ChangedFields []string
+}
+
+// FieldChange is change of a single product attribute.
+type FieldChange struct {
+	Field    string
+	OldValue any
+	NewValue any
+}
+
+// ProductHistoryEntry is recorded update or deletion of a product.
+type ProductHistoryEntry struct {
+	ID int64
+	// RunID is ID of the run which changed the product, it's nil if the run was already removed.
+	RunID *int
+	// Version is version of updated product, or last version of deleted product.
+	Version int64
+	Type    ChangeType
+	// Changes are changed attributes, values of attributes read from storage are decoded from JSON.
+	Changes   []FieldChange
+	CreatedAt time.Time
+}
+
+// PricePoint is product's price and availability set by a run, it's saved only when any of them changes.
+type PricePoint struct {
+	// RunID is ID of the run which changed the product, it's nil if the run was already removed.
+	RunID *int
+	// Price and SalePrice are amounts of product's prices, nil if they can't be parsed.
+	Price        *float64
+	Currency     *string
+	SalePrice    *float64
+	Availability string
+	CreatedAt    time.Time
+}
+
+// DailyPriceStats are changes of prices and availability of shop's products aggregated per day.
+type DailyPriceStats struct {
+	// Day is UTC date of the changes.
+	Day             time.Time
+	ChangedProducts int32
+	// PriceDrops and PriceRises are numbers of changes of product's effective price (sale price, or price if not set).
+	PriceDrops int32
+	PriceRises int32
+	// StockOuts is number of changes of product's availability to out of stock.
+	StockOuts int32
+}
+
+// RetentionPolicy is shop's retention of soft-deleted products and runs.
+// Nil values aren't set for the shop, so default retention is used.
+type RetentionPolicy struct {
+	ShopID int
+	// DeletedProducts is time after which soft-deleted products are purged with their shippings and history.
+	DeletedProducts *time.Duration
+	// Runs is time after which runs are pruned.
+	Runs *time.Duration
+	// KeptRuns is number of the newest shop's runs which are never pruned.
+	KeptRuns *int
+}
+
+// OutboxEvent is event saved together with changes it describes, waiting for publishing.
+type OutboxEvent struct {
+	ID      int64
+	Type    string
+	Payload []byte
 }
 
 // Product is product model.
@@ -49,6 +213,7 @@ type Product struct {
 	Condition           string
 	Availability        string
 	Price               string
+	SalePrice           *string
 	Shippings           []Shipping
 	Brand               *string
 	GTIN                *string
@@ -62,6 +227,38 @@ type Product struct {
 	AgeGroup            *string
 }
 
+// ProductFilter filters queried products, nil values don't filter the products.
+type ProductFilter struct {
+	Availability *string
+	Brand        *string
+	// Category matches products of the category and its subcategories (e.g. "Apparel" matches "Apparel > Shoes").
+	Category *string
+	// MinPrice and MaxPrice filter products by amount of price, products with unparseable price don't match them.
+	MinPrice *float64
+	MaxPrice *float64
+	// UpdatedSince matches products created, updated or deleted by runs with greater products version.
+	UpdatedSince   *int64
+	IncludeDeleted bool
+}
+
+// ChangesCursor is position in shop's product changes, which are ordered by their version and product's ID.
+// Changes since a version are read from cursor with Version and SinceVersion set to that version.
+type ChangesCursor struct {
+	// SinceVersion is products version since which changes are read, products created after it are created ones.
+	SinceVersion int64
+	// Version and ID are version and product's ID of the last read change.
+	Version int64
+	ID      int
+}
+
+// ChangedProduct is product created, updated or deleted since a products version.
+type ChangedProduct struct {
+	Type ChangeType
+	// Version is products version of the run which last changed the product.
+	Version int64
+	Product Product
+}
+
 // Shipping is product's shipping model.
 type Shipping struct {
 	Country string
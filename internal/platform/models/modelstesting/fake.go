@@ -21,6 +21,7 @@ func FakeProduct(ops ...func(p *models.Product)) models.Product {
 		Condition:           faker.Word(),
 		Availability:        faker.Word(),
 		Price:               faker.Word(),
+		SalePrice:           lo.ToPtr(faker.Word()),
 		Shippings:           fakeShippings(),
 		Brand:               lo.ToPtr(faker.Word()),
 		GTIN:                lo.ToPtr(faker.Word()),
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParsePrice parses price in feed format, which is amount followed by ISO 4217 currency code (e.g. "15.00 USD").
+// Returns nil amount if price can't be parsed and empty currency if it's missing.
+func ParsePrice(price string) (*float64, string) {
+	fields := strings.Fields(price)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, ""
+	}
+
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, ""
+	}
+
+	if len(fields) == 1 {
+		return &amount, ""
+	}
+
+	return &amount, strings.ToUpper(fields[1])
+}
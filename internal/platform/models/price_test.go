@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitParsePrice(t *testing.T) {
+	tests := map[string]struct {
+		price            string
+		expectedAmount   *float64
+		expectedCurrency string
+	}{
+		"price with currency": {
+			price:            "15.00 USD",
+			expectedAmount:   lo.ToPtr(15.0),
+			expectedCurrency: "USD",
+		},
+		"price with lowercase currency": {
+			price:            "9.99 pln",
+			expectedAmount:   lo.ToPtr(9.99),
+			expectedCurrency: "PLN",
+		},
+		"price without currency": {
+			price:          "15",
+			expectedAmount: lo.ToPtr(15.0),
+		},
+		"empty price": {
+			price: "",
+		},
+		"invalid amount": {
+			price: "free USD",
+		},
+		"too many fields": {
+			price: "15.00 USD net",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			amount, currency := models.ParsePrice(tt.price)
+
+			assert.Equal(t, tt.expectedAmount, amount, "should return parsed amount")
+			assert.Equal(t, tt.expectedCurrency, currency, "should return parsed currency")
+		})
+	}
+}
@@ -9,8 +9,12 @@ import (
 )
 
 // HandlerFunc is function which handles messages.
+// Handled message can be replied with RabbitMQ.Reply called with context passed to the handler.
 type HandlerFunc func(ctx context.Context, message []byte) error
 
+// deliveryKey is context key of handled delivery.
+type deliveryKey struct{}
+
 // RabbitMQ consumes and publishes amqp messages.
 type RabbitMQ struct {
 	channel   *amqp.Channel
@@ -49,6 +53,118 @@ func (mq *RabbitMQ) Publish(ctx context.Context, routingKey string, message []by
 	)
 }
 
+// Request publishes message to routing key and waits for reply until context is done.
+// Reply is received with exclusive, auto-deleted queue declared for the request.
+func (mq *RabbitMQ) Request(ctx context.Context, routingKey string, message []byte) ([]byte, error) {
+	queue, err := mq.channel.QueueDeclare(
+		"",    // name generated by server
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't declare reply queue: %w", err)
+	}
+
+	correlationID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("can't create correlation ID: %w", err)
+	}
+
+	replies, err := mq.channel.Consume(
+		queue.Name,
+		correlationID.String(),
+		true, // auto acknowledge
+		true, // exclusive
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't consume replies: %w", err)
+	}
+	defer func() { _ = mq.channel.Cancel(correlationID.String(), false) }()
+
+	err = mq.channel.PublishWithContext(
+		ctx,
+		mq.exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:   "application/json",
+			Body:          message,
+			ReplyTo:       queue.Name,
+			CorrelationId: correlationID.String(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't publish request: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case reply, ok := <-replies:
+			if !ok {
+				return nil, fmt.Errorf("replies channel closed")
+			}
+			if reply.CorrelationId == correlationID.String() {
+				return reply.Body, nil
+			}
+		}
+	}
+}
+
+// Reply publishes reply to message handled with context, if the message was sent with reply_to property.
+func (mq *RabbitMQ) Reply(ctx context.Context, message []byte) error {
+	delivery, ok := ctx.Value(deliveryKey{}).(*amqp.Delivery)
+	if !ok || delivery.ReplyTo == "" {
+		return nil
+	}
+
+	msg := amqp.Publishing{
+		ContentType:   "application/json",
+		Body:          message,
+		CorrelationId: delivery.CorrelationId,
+	}
+
+	// replies are sent with default exchange directly to reply queue.
+	return mq.channel.PublishWithContext(
+		ctx,
+		"",
+		delivery.ReplyTo,
+		false,
+		false,
+		msg,
+	)
+}
+
+// BindTemporaryQueue declares exclusive, auto-deleted queue bound to routing key and returns its name.
+// Every service instance binding the same routing key receives its own copy of each message.
+func (mq *RabbitMQ) BindTemporaryQueue(routingKey string) (string, error) {
+	queue, err := mq.channel.QueueDeclare(
+		"",    // name generated by server
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("can't declare queue: %w", err)
+	}
+
+	if err := mq.channel.QueueBind(queue.Name, routingKey, mq.exchange, false, nil); err != nil {
+		return "", fmt.Errorf("can't bind queue: %w", err)
+	}
+
+	return queue.Name, nil
+}
+
 // Consume consumes messages from queue and passes deliveries to provided handler function.
 // It returns channel with errors from handler function and consuming process.
 // Function works asynchronously, it consumes messages in background as long as context is not closed.
@@ -88,7 +204,7 @@ func (mq *RabbitMQ) consumeMessages(
 	handler HandlerFunc,
 ) {
 	for delivery := range deliveries {
-		err := handler(ctx, delivery.Body)
+		err := handler(context.WithValue(ctx, deliveryKey{}, &delivery), delivery.Body)
 		if err != nil {
 			_ = pushError(ctx, err, consumingErrors)
 			if err := mq.nackMessage(ctx, &delivery, consumingErrors); err != nil {
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// stagingSchema is schema of temporary tables of the current session.
+const stagingSchema = "pg_temp"
+
+// bulkUpsertProducts copies products and their shippings into temporary staging tables
+// and merges them into products and shippings with set-based queries.
+// Staging tables are created by the transaction and dropped when it ends, so they're never shared with other transactions.
+func bulkUpsertProducts(ctx context.Context, tx *sql.Tx, products []models.Product, shopID int32) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	if err := createStaging(ctx, tx); err != nil {
+		return fmt.Errorf("can't create staging tables: %w", err)
+	}
+
+	if err := copyProducts(ctx, tx, products, shopID); err != nil {
+		return fmt.Errorf("can't copy products into staging table: %w", err)
+	}
+
+	if err := copyShippings(ctx, tx, products); err != nil {
+		return fmt.Errorf("can't copy shippings into staging table: %w", err)
+	}
+
+	if err := mergeProducts(ctx, tx); err != nil {
+		return fmt.Errorf("can't merge staged products: %w", err)
+	}
+
+	if err := mergeShippings(ctx, tx, shopID); err != nil {
+		return fmt.Errorf("can't merge staged shippings: %w", err)
+	}
+
+	return nil
+}
+
+// productStaging returns temporary product staging table of the current transaction.
+func productStaging() *table.ProductStagingTable {
+	return table.ProductStaging.FromSchema(stagingSchema)
+}
+
+// shippingStaging returns temporary shipping staging table of the current transaction.
+func shippingStaging() *table.ShippingStagingTable {
+	return table.ShippingStaging.FromSchema(stagingSchema)
+}
+
+// createStaging creates temporary staging tables dropped on commit, with the same columns as staging tables in schema.
+func createStaging(ctx context.Context, tx *sql.Tx) error {
+	for _, staging := range []pg.Table{table.ProductStaging, table.ShippingStaging} {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"CREATE TEMP TABLE %[2]s (LIKE %[1]s.%[2]s) ON COMMIT DROP",
+			pq.QuoteIdentifier(staging.SchemaName()),
+			pq.QuoteIdentifier(staging.TableName()),
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stagedProductValues maps names of staged product's columns to their values.
+// Both copying and merging of staged products use only columns listed here.
+func stagedProductValues(product *pgmodels.Product) map[string]any {
+	return map[string]any{
+		table.ProductStaging.ShopID.Name():            product.ShopID,
+		table.ProductStaging.Version.Name():           product.Version,
+		table.ProductStaging.ProductID.Name():         product.ProductID,
+		table.ProductStaging.Title.Name():             product.Title,
+		table.ProductStaging.Description.Name():       product.Description,
+		table.ProductStaging.URL.Name():               product.URL,
+		table.ProductStaging.ImgURL.Name():            product.ImgURL,
+		table.ProductStaging.AdditionalImgUrls.Name(): product.AdditionalImgUrls,
+		table.ProductStaging.Condition.Name():         product.Condition,
+		table.ProductStaging.Availability.Name():      product.Availability,
+		table.ProductStaging.Price.Name():             product.Price,
+		table.ProductStaging.Brand.Name():             product.Brand,
+		table.ProductStaging.Gtin.Name():              product.Gtin,
+		table.ProductStaging.Mpn.Name():               product.Mpn,
+		table.ProductStaging.ProductCategory.Name():   product.ProductCategory,
+		table.ProductStaging.ProductType.Name():       product.ProductType,
+		table.ProductStaging.Color.Name():             product.Color,
+		table.ProductStaging.Size.Name():              product.Size,
+		table.ProductStaging.ItemGroupID.Name():       product.ItemGroupID,
+		table.ProductStaging.Gender.Name():            product.Gender,
+		table.ProductStaging.AgeGroup.Name():          product.AgeGroup,
+		table.ProductStaging.ContentHash.Name():       product.ContentHash,
+		table.ProductStaging.DeletedAt.Name():         product.DeletedAt,
+		table.ProductStaging.SalePrice.Name():         product.SalePrice,
+		table.ProductStaging.PriceAmount.Name():       product.PriceAmount,
+		table.ProductStaging.ChangedVersion.Name():    product.ChangedVersion,
+		table.ProductStaging.CreatedVersion.Name():    product.CreatedVersion,
+	}
+}
+
+// stagedShippingValues maps names of staged shipping's columns to their values.
+func stagedShippingValues(productID string, position int32, shipping models.Shipping) map[string]any {
+	return map[string]any{
+		table.ShippingStaging.ProductID.Name(): productID,
+		table.ShippingStaging.Position.Name():  position,
+		table.ShippingStaging.Country.Name():   shipping.Country,
+		table.ShippingStaging.Service.Name():   shipping.Service,
+		table.ShippingStaging.Price.Name():     shipping.Price,
+	}
+}
+
+// copyProducts copies products into product staging table.
+func copyProducts(ctx context.Context, tx *sql.Tx, products []models.Product, shopID int32) error {
+	rows := make([]map[string]any, 0, len(products))
+	for ix := range products {
+		rows = append(rows, stagedProductValues(ToDBProduct(&products[ix], int64(shopID), nil)))
+	}
+
+	return copyRows(ctx, tx, productStaging(), rows)
+}
+
+// copyShippings copies shippings of products into shipping staging table with their positions,
+// so they're stored in the same order as with row-by-row insert.
+func copyShippings(ctx context.Context, tx *sql.Tx, products []models.Product) error {
+	var rows []map[string]any
+	for ix := range products {
+		for _, shipping := range products[ix].Shippings {
+			rows = append(rows, stagedShippingValues(products[ix].ProductID, int32(len(rows)), shipping))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return copyRows(ctx, tx, shippingStaging(), rows)
+}
+
+// copyRows copies rows, which map column names to values, into table with COPY FROM STDIN.
+// Copied columns are taken from the first row, so all rows have to have the same columns.
+func copyRows(ctx context.Context, tx *sql.Tx, staging pg.Table, rows []map[string]any) error {
+	columnNames := lo.Keys(rows[0])
+	slices.Sort(columnNames)
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema(staging.SchemaName(), staging.TableName(), columnNames...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	values := make([]any, len(columnNames))
+	for _, row := range rows {
+		for ix, name := range columnNames {
+			values[ix] = row[name]
+		}
+
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return err
+		}
+	}
+
+	// flush buffered rows.
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+// mergeProducts upserts staged products into products table.
+func mergeProducts(ctx context.Context, tx *sql.Tx) error {
+	columns, stagedColumns := stagedProductColumns()
+
+	_, err := table.Product.INSERT(columns).
+		QUERY(
+			pg.SELECT(stagedColumns).
+				FROM(productStaging()),
+		).
+		ON_CONFLICT(table.Product.ShopID, table.Product.ProductID).
+		DO_UPDATE(
+			pg.SET(
+				updatedColumns().SET(pg.ROW(excludedColumns()...)),
+			),
+		).
+		ExecContext(ctx, tx)
+
+	return err
+}
+
+// stagedProductColumns returns product's columns which are staged with matching product staging table's columns.
+// Columns are paired by names of staged product's values, so staged products are merged with the same columns
+// as they're copied with, regardless of tables' columns order.
+func stagedProductColumns() (pg.ColumnList, pg.ColumnList) {
+	staged := stagedProductValues(&pgmodels.Product{})
+	stagingColumns := lo.SliceToMap(productStaging().AllColumns, func(column pg.Column) (string, pg.Column) {
+		return column.Name(), column
+	})
+
+	var columns, stagedColumns pg.ColumnList
+	for _, column := range table.Product.AllColumns {
+		if _, ok := staged[column.Name()]; ok {
+			columns = append(columns, column)
+			stagedColumns = append(stagedColumns, stagingColumns[column.Name()])
+		}
+	}
+
+	return columns, stagedColumns
+}
+
+// mergeShippings replaces shippings of staged products with staged shippings.
+func mergeShippings(ctx context.Context, tx *sql.Tx, shopID int32) error {
+	products, shippings := productStaging(), shippingStaging()
+
+	_, err := table.Shipping.DELETE().
+		WHERE(table.Shipping.ProductID.IN(
+			pg.SELECT(table.Product.ID).
+				FROM(table.Product.INNER_JOIN(
+					products,
+					pg.AND(
+						products.ShopID.EQ(table.Product.ShopID),
+						products.ProductID.EQ(table.Product.ProductID),
+					),
+				)),
+		)).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("can't delete outdated products shippings: %w", err)
+	}
+
+	_, err = table.Shipping.INSERT(table.Shipping.AllColumns.Except(table.Shipping.ID)).
+		QUERY(
+			pg.SELECT(
+				table.Product.ID,
+				shippings.Country,
+				shippings.Service,
+				shippings.Price,
+			).
+				FROM(shippings.INNER_JOIN(
+					table.Product,
+					pg.AND(
+						table.Product.ShopID.EQ(pg.Int32(shopID)),
+						table.Product.ProductID.EQ(shippings.ProductID),
+					),
+				)).
+				ORDER_BY(shippings.Position.ASC()),
+		).
+		ExecContext(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("can't insert shippings: %w", err)
+	}
+
+	return nil
+}
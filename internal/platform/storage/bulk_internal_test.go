@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"testing"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitStagedProductColumns(t *testing.T) {
+	staged := stagedProductValues(&pgmodels.Product{})
+
+	columns, stagedColumns := stagedProductColumns()
+
+	require.Len(t, columns, len(staged), "should merge all staged columns")
+	require.Len(t, stagedColumns, len(columns), "should pair every merged column")
+	for ix := range columns {
+		require.Contains(t, staged, columns[ix].Name(), "should merge only staged columns")
+		require.Equal(t, columns[ix].Name(), stagedColumns[ix].Name(), "should pair columns by name")
+	}
+	for _, column := range table.ProductStaging.AllColumns {
+		require.Contains(t, staged, column.Name(), "should copy every column of staging table")
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// GetProductChanges returns up to limit shop's products created, updated or deleted after the cursor
+// with their shippings, ordered by version of their last change and their ID, so changes can be read page by page
+// from cursor of the last returned change.
+// Changes made by shop's running runs, and by newer runs, aren't returned until the runs finish,
+// so changes read later can't be ordered before the cursor.
+func (p Postgres) GetProductChanges(
+	ctx context.Context,
+	shopID int,
+	cursor models.ChangesCursor,
+	limit int,
+) ([]models.ChangedProduct, error) {
+	running := pg.EXISTS(
+		table.Run.SELECT(table.Run.ID).
+			WHERE(pg.AND(
+				table.Run.ShopID.EQ(table.Product.ShopID),
+				table.Run.FinishedAt.IS_NULL(),
+				table.Run.Success.IS_NULL(),
+				table.Run.HeartbeatAt.GT(pg.TimestampzT(time.Now().Add(-p.runLease))),
+				table.Run.ProductsVersion.LT_EQ(table.Product.ChangedVersion),
+			)),
+	)
+
+	var stored []pgmodels.Product
+	err := table.Product.SELECT(table.Product.AllColumns).
+		WHERE(pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.ChangedVersion.GT(pg.Int64(cursor.SinceVersion)),
+			pg.OR(
+				table.Product.ChangedVersion.GT(pg.Int64(cursor.Version)),
+				pg.AND(
+					table.Product.ChangedVersion.EQ(pg.Int64(cursor.Version)),
+					table.Product.ID.GT(pg.Int32(int32(cursor.ID))),
+				),
+			),
+			pg.NOT(running),
+		)).
+		ORDER_BY(table.Product.ChangedVersion.ASC(), table.Product.ID.ASC()).
+		LIMIT(int64(limit)).
+		QueryContext(ctx, p.db, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("can't get product changes: %w", err)
+	}
+
+	products, err := withShippings(ctx, p.db, stored)
+	if err != nil {
+		return nil, fmt.Errorf("can't get product changes: %w", err)
+	}
+
+	changes := make([]models.ChangedProduct, 0, len(products))
+	for ix := range products {
+		changes = append(changes, models.ChangedProduct{
+			Type:    changeType(&stored[ix], cursor.SinceVersion),
+			Version: *stored[ix].ChangedVersion,
+			Product: products[ix],
+		})
+	}
+
+	return changes, nil
+}
+
+// changeType returns type of product's change since provided version.
+// Products created before their created version was stored are treated as updated ones.
+func changeType(product *pgmodels.Product, sinceVersion int64) models.ChangeType {
+	switch {
+	case product.DeletedAt != nil:
+		return models.ChangeDeleted
+	case product.CreatedVersion != nil && *product.CreatedVersion > sinceVersion:
+		return models.ChangeCreated
+	default:
+		return models.ChangeUpdated
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// GetShopConfig returns shop's parsing configuration, values not set for the shop are nil.
+// Returns empty configuration if nothing is set for the shop.
+func (p Postgres) GetShopConfig(ctx context.Context, shopID int) (models.ShopConfig, error) {
+	var config pgmodels.ShopConfig
+	err := table.ShopConfig.SELECT(table.ShopConfig.AllColumns).
+		WHERE(table.ShopConfig.ShopID.EQ(pg.Int32(int32(shopID)))).
+		QueryContext(ctx, p.db, &config)
+	if errors.Is(err, qrm.ErrNoRows) {
+		return models.ShopConfig{}, nil
+	}
+
+	if err != nil {
+		return models.ShopConfig{}, fmt.Errorf("can't get shop config: %w", err)
+	}
+
+	return fromDBShopConfig(&config), nil
+}
+
+// SetShopConfig replaces shop's parsing configuration, nil values unset shop's values, so defaults are used.
+// Parse timeout is stored in whole seconds, rounded down.
+// Returns platform.ErrShopNotFound if the shop doesn't exist.
+func (p Postgres) SetShopConfig(ctx context.Context, shopID int, config models.ShopConfig) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		if _, err := getShopByID(ctx, tx, shopID); err != nil {
+			return err
+		}
+
+		columns := table.ShopConfig.AllColumns.Except(table.ShopConfig.UpdatedAt)
+		updated := table.ShopConfig.EXCLUDED.AllColumns.Except(table.ShopConfig.ShopID, table.ShopConfig.UpdatedAt)
+
+		excludedExpressions := make([]pg.Expression, 0, len(updated)) // converting to expression
+		for _, col := range updated {
+			excludedExpressions = append(excludedExpressions, col)
+		}
+
+		_, err := table.ShopConfig.INSERT(columns).
+			MODEL(toDBShopConfig(shopID, &config)).
+			ON_CONFLICT(table.ShopConfig.ShopID).
+			DO_UPDATE(pg.SET(
+				table.ShopConfig.AllColumns.
+					Except(table.ShopConfig.ShopID, table.ShopConfig.UpdatedAt).
+					SET(pg.ROW(excludedExpressions...)),
+				table.ShopConfig.UpdatedAt.SET(pg.TimestampzT(time.Now())),
+			)).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't save shop config: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func toDBShopConfig(shopID int, config *models.ShopConfig) pgmodels.ShopConfig {
+	dbConfig := pgmodels.ShopConfig{
+		ShopID:             int32(shopID),
+		StorageWorkers:     toDBInt(config.StorageWorkers),
+		Decoder:            config.Decoder,
+		MaxDeletionPercent: config.MaxDeletionPercent,
+		MaxFailedProducts:  toDBInt(config.MaxFailedProducts),
+		MaxFailureRatio:    config.MaxFailureRatio,
+	}
+
+	if config.BatchSize != nil {
+		dbConfig.BatchSize = lo.ToPtr(int32(*config.BatchSize))
+	}
+
+	if config.ParseTimeout != nil {
+		dbConfig.ParseTimeoutSeconds = lo.ToPtr(int32(*config.ParseTimeout / time.Second))
+	}
+
+	if config.RemovalAvailabilities != nil {
+		dbConfig.RemovalAvailabilities = lo.ToPtr(strings.Join(config.RemovalAvailabilities, "\n"))
+	}
+
+	return dbConfig
+}
+
+func fromDBShopConfig(config *pgmodels.ShopConfig) models.ShopConfig {
+	shopConfig := models.ShopConfig{
+		StorageWorkers:     fromDBInt(config.StorageWorkers),
+		Decoder:            config.Decoder,
+		MaxDeletionPercent: config.MaxDeletionPercent,
+		MaxFailedProducts:  fromDBInt(config.MaxFailedProducts),
+		MaxFailureRatio:    config.MaxFailureRatio,
+	}
+
+	if config.BatchSize != nil {
+		shopConfig.BatchSize = lo.ToPtr(uint(*config.BatchSize))
+	}
+
+	if config.ParseTimeoutSeconds != nil {
+		shopConfig.ParseTimeout = lo.ToPtr(time.Duration(*config.ParseTimeoutSeconds) * time.Second)
+	}
+
+	// empty list is set, so no availability marks products as removed.
+	if config.RemovalAvailabilities != nil {
+		shopConfig.RemovalAvailabilities = []string{}
+		if *config.RemovalAvailabilities != "" {
+			shopConfig.RemovalAvailabilities = strings.Split(*config.RemovalAvailabilities, "\n")
+		}
+	}
+
+	return shopConfig
+}
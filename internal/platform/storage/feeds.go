@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// SetSupplementalFeeds replaces shop's supplemental feeds with provided feed urls.
+// Order of urls is preserved, feeds later on the list override attributes from earlier feeds.
+func (p Postgres) SetSupplementalFeeds(ctx context.Context, shopID int, feedURLs []string) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		_, err := table.SupplementalFeed.DELETE().
+			WHERE(table.SupplementalFeed.ShopID.EQ(pg.Int32(int32(shopID)))).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't delete supplemental feeds: %w", err)
+		}
+
+		if len(feedURLs) == 0 {
+			return nil
+		}
+
+		feeds := make([]pgmodels.SupplementalFeed, 0, len(feedURLs))
+		for ix := range feedURLs {
+			feeds = append(feeds, pgmodels.SupplementalFeed{
+				ShopID:   int32(shopID),
+				URL:      feedURLs[ix],
+				Position: int32(ix),
+			})
+		}
+
+		_, err = table.SupplementalFeed.INSERT(
+			table.SupplementalFeed.ShopID,
+			table.SupplementalFeed.URL,
+			table.SupplementalFeed.Position,
+		).
+			MODELS(feeds).
+			ON_CONFLICT(table.SupplementalFeed.ShopID, table.SupplementalFeed.URL).
+			DO_NOTHING().
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't insert supplemental feeds: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetSupplementalFeeds returns urls of shop's supplemental feeds in order in which they should be applied.
+func (p Postgres) GetSupplementalFeeds(ctx context.Context, shopID int) ([]string, error) {
+	var feeds []pgmodels.SupplementalFeed
+	err := table.SupplementalFeed.SELECT(table.SupplementalFeed.URL).
+		WHERE(table.SupplementalFeed.ShopID.EQ(pg.Int32(int32(shopID)))).
+		ORDER_BY(table.SupplementalFeed.Position.ASC()).
+		QueryContext(ctx, p.db, &feeds)
+	if err != nil {
+		return nil, fmt.Errorf("can't get supplemental feeds: %w", err)
+	}
+
+	urls := make([]string, 0, len(feeds))
+	for ix := range feeds {
+		urls = append(urls, feeds[ix].URL)
+	}
+
+	return urls, nil
+}
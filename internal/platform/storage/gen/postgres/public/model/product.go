@@ -36,4 +36,9 @@ type Product struct {
 	AgeGroup          *string
 	CreatedAt         time.Time
 	DeletedAt         *time.Time
+	ContentHash       *string
+	SalePrice         *string
+	PriceAmount       *float64
+	ChangedVersion    *int64
+	CreatedVersion    *int64
 }
@@ -12,15 +12,21 @@ import (
 )
 
 type Run struct {
-	ID              int32 `sql:"primary_key"`
-	ShopID          int32
-	ProductsVersion int64
-	CreatedProducts *int32
-	UpdatedProducts *int32
-	DeletedProducts *int32
-	FailedProducts  *int32
-	Success         *bool
-	StatusMessage   *string
-	CreatedAt       time.Time
-	FinishedAt      *time.Time
+	ID                int32 `sql:"primary_key"`
+	ShopID            int32
+	ProductsVersion   int64
+	CreatedProducts   *int32
+	UpdatedProducts   *int32
+	DeletedProducts   *int32
+	FailedProducts    *int32
+	Success           *bool
+	StatusMessage     *string
+	CreatedAt         time.Time
+	FinishedAt        *time.Time
+	Incremental       bool
+	Status            string
+	HeartbeatAt       time.Time
+	DryRun            bool
+	UnchangedProducts *int32
+	ChangesSample     *string
 }
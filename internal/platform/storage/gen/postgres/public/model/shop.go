@@ -12,7 +12,13 @@ import (
 )
 
 type Shop struct {
-	ID        int32 `sql:"primary_key"`
-	URL       string
-	CreatedAt time.Time
+	ID                           int32 `sql:"primary_key"`
+	URL                          string
+	CreatedAt                    time.Time
+	DeletedProductsRetentionDays *int32
+	RunsRetentionDays            *int32
+	KeptRuns                     *int32
+	Name                         string
+	Disabled                     bool
+	DeletedAt                    *time.Time
 }
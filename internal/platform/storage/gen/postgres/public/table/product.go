@@ -41,6 +41,11 @@ type productTable struct {
 	AgeGroup          postgres.ColumnString
 	CreatedAt         postgres.ColumnTimestampz
 	DeletedAt         postgres.ColumnTimestampz
+	ContentHash       postgres.ColumnString
+	SalePrice         postgres.ColumnString
+	PriceAmount       postgres.ColumnFloat
+	ChangedVersion    postgres.ColumnInteger
+	CreatedVersion    postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -105,8 +110,13 @@ func newProductTableImpl(schemaName, tableName, alias string) productTable {
 		AgeGroupColumn          = postgres.StringColumn("age_group")
 		CreatedAtColumn         = postgres.TimestampzColumn("created_at")
 		DeletedAtColumn         = postgres.TimestampzColumn("deleted_at")
-		allColumns              = postgres.ColumnList{IDColumn, ShopIDColumn, VersionColumn, ProductIDColumn, TitleColumn, DescriptionColumn, URLColumn, ImgURLColumn, AdditionalImgUrlsColumn, ConditionColumn, AvailabilityColumn, PriceColumn, BrandColumn, GtinColumn, MpnColumn, ProductCategoryColumn, ProductTypeColumn, ColorColumn, SizeColumn, ItemGroupIDColumn, GenderColumn, AgeGroupColumn, CreatedAtColumn, DeletedAtColumn}
-		mutableColumns          = postgres.ColumnList{ShopIDColumn, VersionColumn, ProductIDColumn, TitleColumn, DescriptionColumn, URLColumn, ImgURLColumn, AdditionalImgUrlsColumn, ConditionColumn, AvailabilityColumn, PriceColumn, BrandColumn, GtinColumn, MpnColumn, ProductCategoryColumn, ProductTypeColumn, ColorColumn, SizeColumn, ItemGroupIDColumn, GenderColumn, AgeGroupColumn, CreatedAtColumn, DeletedAtColumn}
+		ContentHashColumn       = postgres.StringColumn("content_hash")
+		SalePriceColumn         = postgres.StringColumn("sale_price")
+		PriceAmountColumn       = postgres.FloatColumn("price_amount")
+		ChangedVersionColumn    = postgres.IntegerColumn("changed_version")
+		CreatedVersionColumn    = postgres.IntegerColumn("created_version")
+		allColumns              = postgres.ColumnList{IDColumn, ShopIDColumn, VersionColumn, ProductIDColumn, TitleColumn, DescriptionColumn, URLColumn, ImgURLColumn, AdditionalImgUrlsColumn, ConditionColumn, AvailabilityColumn, PriceColumn, BrandColumn, GtinColumn, MpnColumn, ProductCategoryColumn, ProductTypeColumn, ColorColumn, SizeColumn, ItemGroupIDColumn, GenderColumn, AgeGroupColumn, CreatedAtColumn, DeletedAtColumn, ContentHashColumn, SalePriceColumn, PriceAmountColumn, ChangedVersionColumn, CreatedVersionColumn}
+		mutableColumns          = postgres.ColumnList{ShopIDColumn, VersionColumn, ProductIDColumn, TitleColumn, DescriptionColumn, URLColumn, ImgURLColumn, AdditionalImgUrlsColumn, ConditionColumn, AvailabilityColumn, PriceColumn, BrandColumn, GtinColumn, MpnColumn, ProductCategoryColumn, ProductTypeColumn, ColorColumn, SizeColumn, ItemGroupIDColumn, GenderColumn, AgeGroupColumn, CreatedAtColumn, DeletedAtColumn, ContentHashColumn, SalePriceColumn, PriceAmountColumn, ChangedVersionColumn, CreatedVersionColumn}
 	)
 
 	return productTable{
@@ -137,6 +147,11 @@ func newProductTableImpl(schemaName, tableName, alias string) productTable {
 		AgeGroup:          AgeGroupColumn,
 		CreatedAt:         CreatedAtColumn,
 		DeletedAt:         DeletedAtColumn,
+		ContentHash:       ContentHashColumn,
+		SalePrice:         SalePriceColumn,
+		PriceAmount:       PriceAmountColumn,
+		ChangedVersion:    ChangedVersionColumn,
+		CreatedVersion:    CreatedVersionColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,17 +17,23 @@ type runTable struct {
 	postgres.Table
 
 	// Columns
-	ID              postgres.ColumnInteger
-	ShopID          postgres.ColumnInteger
-	ProductsVersion postgres.ColumnInteger
-	CreatedProducts postgres.ColumnInteger
-	UpdatedProducts postgres.ColumnInteger
-	DeletedProducts postgres.ColumnInteger
-	FailedProducts  postgres.ColumnInteger
-	Success         postgres.ColumnBool
-	StatusMessage   postgres.ColumnString
-	CreatedAt       postgres.ColumnTimestampz
-	FinishedAt      postgres.ColumnTimestampz
+	ID                postgres.ColumnInteger
+	ShopID            postgres.ColumnInteger
+	ProductsVersion   postgres.ColumnInteger
+	CreatedProducts   postgres.ColumnInteger
+	UpdatedProducts   postgres.ColumnInteger
+	DeletedProducts   postgres.ColumnInteger
+	FailedProducts    postgres.ColumnInteger
+	Success           postgres.ColumnBool
+	StatusMessage     postgres.ColumnString
+	CreatedAt         postgres.ColumnTimestampz
+	FinishedAt        postgres.ColumnTimestampz
+	Incremental       postgres.ColumnBool
+	Status            postgres.ColumnString
+	HeartbeatAt       postgres.ColumnTimestampz
+	DryRun            postgres.ColumnBool
+	UnchangedProducts postgres.ColumnInteger
+	ChangesSample     postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -68,36 +74,48 @@ func newRunTable(schemaName, tableName, alias string) *RunTable {
 
 func newRunTableImpl(schemaName, tableName, alias string) runTable {
 	var (
-		IDColumn              = postgres.IntegerColumn("id")
-		ShopIDColumn          = postgres.IntegerColumn("shop_id")
-		ProductsVersionColumn = postgres.IntegerColumn("products_version")
-		CreatedProductsColumn = postgres.IntegerColumn("created_products")
-		UpdatedProductsColumn = postgres.IntegerColumn("updated_products")
-		DeletedProductsColumn = postgres.IntegerColumn("deleted_products")
-		FailedProductsColumn  = postgres.IntegerColumn("failed_products")
-		SuccessColumn         = postgres.BoolColumn("success")
-		StatusMessageColumn   = postgres.StringColumn("status_message")
-		CreatedAtColumn       = postgres.TimestampzColumn("created_at")
-		FinishedAtColumn      = postgres.TimestampzColumn("finished_at")
-		allColumns            = postgres.ColumnList{IDColumn, ShopIDColumn, ProductsVersionColumn, CreatedProductsColumn, UpdatedProductsColumn, DeletedProductsColumn, FailedProductsColumn, SuccessColumn, StatusMessageColumn, CreatedAtColumn, FinishedAtColumn}
-		mutableColumns        = postgres.ColumnList{ShopIDColumn, ProductsVersionColumn, CreatedProductsColumn, UpdatedProductsColumn, DeletedProductsColumn, FailedProductsColumn, SuccessColumn, StatusMessageColumn, CreatedAtColumn, FinishedAtColumn}
+		IDColumn                = postgres.IntegerColumn("id")
+		ShopIDColumn            = postgres.IntegerColumn("shop_id")
+		ProductsVersionColumn   = postgres.IntegerColumn("products_version")
+		CreatedProductsColumn   = postgres.IntegerColumn("created_products")
+		UpdatedProductsColumn   = postgres.IntegerColumn("updated_products")
+		DeletedProductsColumn   = postgres.IntegerColumn("deleted_products")
+		FailedProductsColumn    = postgres.IntegerColumn("failed_products")
+		SuccessColumn           = postgres.BoolColumn("success")
+		StatusMessageColumn     = postgres.StringColumn("status_message")
+		CreatedAtColumn         = postgres.TimestampzColumn("created_at")
+		FinishedAtColumn        = postgres.TimestampzColumn("finished_at")
+		IncrementalColumn       = postgres.BoolColumn("incremental")
+		StatusColumn            = postgres.StringColumn("status")
+		HeartbeatAtColumn       = postgres.TimestampzColumn("heartbeat_at")
+		DryRunColumn            = postgres.BoolColumn("dry_run")
+		UnchangedProductsColumn = postgres.IntegerColumn("unchanged_products")
+		ChangesSampleColumn     = postgres.StringColumn("changes_sample")
+		allColumns              = postgres.ColumnList{IDColumn, ShopIDColumn, ProductsVersionColumn, CreatedProductsColumn, UpdatedProductsColumn, DeletedProductsColumn, FailedProductsColumn, SuccessColumn, StatusMessageColumn, CreatedAtColumn, FinishedAtColumn, IncrementalColumn, StatusColumn, HeartbeatAtColumn, DryRunColumn, UnchangedProductsColumn, ChangesSampleColumn}
+		mutableColumns          = postgres.ColumnList{ShopIDColumn, ProductsVersionColumn, CreatedProductsColumn, UpdatedProductsColumn, DeletedProductsColumn, FailedProductsColumn, SuccessColumn, StatusMessageColumn, CreatedAtColumn, FinishedAtColumn, IncrementalColumn, StatusColumn, HeartbeatAtColumn, DryRunColumn, UnchangedProductsColumn, ChangesSampleColumn}
 	)
 
 	return runTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:              IDColumn,
-		ShopID:          ShopIDColumn,
-		ProductsVersion: ProductsVersionColumn,
-		CreatedProducts: CreatedProductsColumn,
-		UpdatedProducts: UpdatedProductsColumn,
-		DeletedProducts: DeletedProductsColumn,
-		FailedProducts:  FailedProductsColumn,
-		Success:         SuccessColumn,
-		StatusMessage:   StatusMessageColumn,
-		CreatedAt:       CreatedAtColumn,
-		FinishedAt:      FinishedAtColumn,
+		ID:                IDColumn,
+		ShopID:            ShopIDColumn,
+		ProductsVersion:   ProductsVersionColumn,
+		CreatedProducts:   CreatedProductsColumn,
+		UpdatedProducts:   UpdatedProductsColumn,
+		DeletedProducts:   DeletedProductsColumn,
+		FailedProducts:    FailedProductsColumn,
+		Success:           SuccessColumn,
+		StatusMessage:     StatusMessageColumn,
+		CreatedAt:         CreatedAtColumn,
+		FinishedAt:        FinishedAtColumn,
+		Incremental:       IncrementalColumn,
+		Status:            StatusColumn,
+		HeartbeatAt:       HeartbeatAtColumn,
+		DryRun:            DryRunColumn,
+		UnchangedProducts: UnchangedProductsColumn,
+		ChangesSample:     ChangesSampleColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -17,9 +17,15 @@ type shopTable struct {
 	postgres.Table
 
 	// Columns
-	ID        postgres.ColumnInteger
-	URL       postgres.ColumnString
-	CreatedAt postgres.ColumnTimestampz
+	ID                           postgres.ColumnInteger
+	URL                          postgres.ColumnString
+	CreatedAt                    postgres.ColumnTimestampz
+	DeletedProductsRetentionDays postgres.ColumnInteger
+	RunsRetentionDays            postgres.ColumnInteger
+	KeptRuns                     postgres.ColumnInteger
+	Name                         postgres.ColumnString
+	Disabled                     postgres.ColumnBool
+	DeletedAt                    postgres.ColumnTimestampz
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -60,20 +66,32 @@ func newShopTable(schemaName, tableName, alias string) *ShopTable {
 
 func newShopTableImpl(schemaName, tableName, alias string) shopTable {
 	var (
-		IDColumn        = postgres.IntegerColumn("id")
-		URLColumn       = postgres.StringColumn("url")
-		CreatedAtColumn = postgres.TimestampzColumn("created_at")
-		allColumns      = postgres.ColumnList{IDColumn, URLColumn, CreatedAtColumn}
-		mutableColumns  = postgres.ColumnList{URLColumn, CreatedAtColumn}
+		IDColumn                           = postgres.IntegerColumn("id")
+		URLColumn                          = postgres.StringColumn("url")
+		CreatedAtColumn                    = postgres.TimestampzColumn("created_at")
+		DeletedProductsRetentionDaysColumn = postgres.IntegerColumn("deleted_products_retention_days")
+		RunsRetentionDaysColumn            = postgres.IntegerColumn("runs_retention_days")
+		KeptRunsColumn                     = postgres.IntegerColumn("kept_runs")
+		NameColumn                         = postgres.StringColumn("name")
+		DisabledColumn                     = postgres.BoolColumn("disabled")
+		DeletedAtColumn                    = postgres.TimestampzColumn("deleted_at")
+		allColumns                         = postgres.ColumnList{IDColumn, URLColumn, CreatedAtColumn, DeletedProductsRetentionDaysColumn, RunsRetentionDaysColumn, KeptRunsColumn, NameColumn, DisabledColumn, DeletedAtColumn}
+		mutableColumns                     = postgres.ColumnList{URLColumn, CreatedAtColumn, DeletedProductsRetentionDaysColumn, RunsRetentionDaysColumn, KeptRunsColumn, NameColumn, DisabledColumn, DeletedAtColumn}
 	)
 
 	return shopTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:        IDColumn,
-		URL:       URLColumn,
-		CreatedAt: CreatedAtColumn,
+		ID:                           IDColumn,
+		URL:                          URLColumn,
+		CreatedAt:                    CreatedAtColumn,
+		DeletedProductsRetentionDays: DeletedProductsRetentionDaysColumn,
+		RunsRetentionDays:            RunsRetentionDaysColumn,
+		KeptRuns:                     KeptRunsColumn,
+		Name:                         NameColumn,
+		Disabled:                     DisabledColumn,
+		DeletedAt:                    DeletedAtColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
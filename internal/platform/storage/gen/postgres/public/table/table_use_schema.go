@@ -10,8 +10,17 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	Outbox = Outbox.FromSchema(schema)
+	PriceSeries = PriceSeries.FromSchema(schema)
 	Product = Product.FromSchema(schema)
+	ProductHistory = ProductHistory.FromSchema(schema)
+	ProductStaging = ProductStaging.FromSchema(schema)
 	Run = Run.FromSchema(schema)
 	Shipping = Shipping.FromSchema(schema)
+	ShippingStaging = ShippingStaging.FromSchema(schema)
 	Shop = Shop.FromSchema(schema)
+	ShopConfig = ShopConfig.FromSchema(schema)
+	ShopSchedule = ShopSchedule.FromSchema(schema)
+	ShopURLHistory = ShopURLHistory.FromSchema(schema)
+	SupplementalFeed = SupplementalFeed.FromSchema(schema)
 }
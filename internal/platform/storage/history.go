@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// fieldChange is JSON representation of models.FieldChange.
+type fieldChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"oldValue"`
+	NewValue any    `json:"newValue"`
+}
+
+// GetProductHistory returns timeline of updates and deletions of shop's product with provided product ID,
+// from the oldest to the newest change.
+func (p Postgres) GetProductHistory(
+	ctx context.Context,
+	shopID int,
+	productID string,
+) ([]models.ProductHistoryEntry, error) {
+	var history []pgmodels.ProductHistory
+	err := pg.SELECT(table.ProductHistory.AllColumns).
+		FROM(table.ProductHistory.INNER_JOIN(table.Product, table.Product.ID.EQ(table.ProductHistory.ProductID))).
+		WHERE(pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.ProductID.EQ(pg.String(productID)),
+		)).
+		ORDER_BY(table.ProductHistory.ID.ASC()).
+		QueryContext(ctx, p.db, &history)
+	if err != nil {
+		return nil, fmt.Errorf("can't get product history: %w", err)
+	}
+
+	entries := make([]models.ProductHistoryEntry, 0, len(history))
+	for ix := range history {
+		entry, err := fromDBHistory(&history[ix])
+		if err != nil {
+			return nil, fmt.Errorf("can't decode product history: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// updatedHistory returns history entries of updated products with attributes changed since previous versions.
+// Products without any changed attribute are skipped.
+func updatedHistory(products []models.Product, previous []models.Product, runID int) ([]pgmodels.ProductHistory, error) {
+	previousByID := make(map[string]*models.Product, len(previous))
+	for ix := range previous {
+		previousByID[previous[ix].ProductID] = &previous[ix]
+	}
+
+	history := make([]pgmodels.ProductHistory, 0, len(products))
+	for ix := range products {
+		previousProduct, ok := previousByID[products[ix].ProductID]
+		if !ok {
+			continue
+		}
+
+		changes := models.FieldChanges(previousProduct, &products[ix])
+		if len(changes) == 0 {
+			continue
+		}
+
+		entry, err := toDBHistory(previousProduct.ID, runID, products[ix].Version, models.ChangeUpdated, changes)
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// deletedHistory returns history entries of products deleted at provided time.
+func deletedHistory(products []pgmodels.Product, runID int, now time.Time) ([]pgmodels.ProductHistory, error) {
+	history := make([]pgmodels.ProductHistory, 0, len(products))
+	for ix := range products {
+		entry, err := toDBHistory(int(products[ix].ID), runID, products[ix].Version, models.ChangeDeleted,
+			[]models.FieldChange{{Field: "deleted_at", NewValue: now}})
+		if err != nil {
+			return nil, err
+		}
+
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+func insertHistory(ctx context.Context, db qrm.DB, history []pgmodels.ProductHistory) error {
+	if len(history) == 0 {
+		return nil
+	}
+
+	_, err := table.ProductHistory.INSERT(table.ProductHistory.AllColumns.Except(
+		table.ProductHistory.ID,
+		table.ProductHistory.CreatedAt,
+	)).
+		MODELS(history).
+		ExecContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("can't insert products history: %w", err)
+	}
+
+	return nil
+}
+
+func toDBHistory(
+	productID int,
+	runID int,
+	version int64,
+	changeType models.ChangeType,
+	changes []models.FieldChange,
+) (pgmodels.ProductHistory, error) {
+	dbChanges := lo.Map(changes, func(change models.FieldChange, _ int) fieldChange {
+		return fieldChange(change)
+	})
+
+	payload, err := json.Marshal(dbChanges)
+	if err != nil {
+		return pgmodels.ProductHistory{}, fmt.Errorf("can't marshal product changes: %w", err)
+	}
+
+	return pgmodels.ProductHistory{
+		ProductID:  int32(productID),
+		RunID:      lo.ToPtr(int32(runID)),
+		Version:    version,
+		ChangeType: string(changeType),
+		Changes:    string(payload),
+	}, nil
+}
+
+func fromDBHistory(history *pgmodels.ProductHistory) (models.ProductHistoryEntry, error) {
+	var changes []fieldChange
+	if err := json.Unmarshal([]byte(history.Changes), &changes); err != nil {
+		return models.ProductHistoryEntry{}, err
+	}
+
+	var runID *int
+	if history.RunID != nil {
+		runID = lo.ToPtr(int(*history.RunID))
+	}
+
+	return models.ProductHistoryEntry{
+		ID:      history.ID,
+		RunID:   runID,
+		Version: history.Version,
+		Type:    models.ChangeType(history.ChangeType),
+		Changes: lo.Map(changes, func(change fieldChange, _ int) models.FieldChange {
+			return models.FieldChange(change)
+		}),
+		CreatedAt: history.CreatedAt,
+	}, nil
+}
@@ -1,32 +1,169 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/samber/lo"
 
 	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
 )
 
 //go:generate make -C ../../../ generate-db
 
+// productChange is JSON representation of models.ProductChange.
+type productChange struct {
+	ProductID     string   `json:"productId"`
+	Type          string   `json:"type"`
+	ChangedFields []string `json:"changedFields,omitempty"`
+}
+
 func toDBRun(run *models.Run) *pgmodels.Run {
 	return &pgmodels.Run{
-		ProductsVersion: run.ProductsVersion,
-		ShopID:          int32(run.ShopID),
-		FinishedAt:      run.FinishedAt,
-		Success:         run.IsSuccess,
-		StatusMessage:   run.StatusMessage,
-		CreatedProducts: run.CreatedProducts,
-		UpdatedProducts: run.UpdatedProducts,
-		DeletedProducts: run.DeletedProducts,
-		FailedProducts:  run.FailedProducts,
+		ProductsVersion:   run.ProductsVersion,
+		ShopID:            int32(run.ShopID),
+		FinishedAt:        run.FinishedAt,
+		Success:           run.IsSuccess,
+		StatusMessage:     run.StatusMessage,
+		CreatedProducts:   run.CreatedProducts,
+		UpdatedProducts:   run.UpdatedProducts,
+		DeletedProducts:   run.DeletedProducts,
+		FailedProducts:    run.FailedProducts,
+		Incremental:       run.IsIncremental,
+		Status:            string(run.Status),
+		DryRun:            run.IsDryRun,
+		UnchangedProducts: run.UnchangedProducts,
+		ChangesSample:     toDBChanges(run.ChangesSample),
 	}
 }
 
+// FromDBRun converts postgres run model into models.Run.
+func FromDBRun(run *pgmodels.Run) (*models.Run, error) {
+	changes, err := fromDBChanges(run.ChangesSample)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode run's changes sample: %w", err)
+	}
+
+	return &models.Run{
+		ID:                int(run.ID),
+		ShopID:            int(run.ShopID),
+		CreatedAt:         run.CreatedAt,
+		FinishedAt:        run.FinishedAt,
+		IsSuccess:         run.Success,
+		StatusMessage:     run.StatusMessage,
+		CreatedProducts:   run.CreatedProducts,
+		UpdatedProducts:   run.UpdatedProducts,
+		DeletedProducts:   run.DeletedProducts,
+		FailedProducts:    run.FailedProducts,
+		ProductsVersion:   run.ProductsVersion,
+		IsIncremental:     run.Incremental,
+		Status:            models.RunStatus(run.Status),
+		IsDryRun:          run.DryRun,
+		UnchangedProducts: run.UnchangedProducts,
+		ChangesSample:     changes,
+	}, nil
+}
+
+func toDBChanges(changes []models.ProductChange) *string {
+	if changes == nil {
+		return nil
+	}
+
+	dbChanges := make([]productChange, 0, len(changes))
+	for ix := range changes {
+		dbChanges = append(dbChanges, productChange{
+			ProductID:     changes[ix].ProductID,
+			Type:          string(changes[ix].Type),
+			ChangedFields: changes[ix].ChangedFields,
+		})
+	}
+
+	// marshalling of plain strings can't fail
+	encoded, _ := json.Marshal(dbChanges)
+
+	return lo.ToPtr(string(encoded))
+}
+
+func fromDBChanges(encoded *string) ([]models.ProductChange, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+
+	var dbChanges []productChange
+	if err := json.Unmarshal([]byte(*encoded), &dbChanges); err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.ProductChange, 0, len(dbChanges))
+	for ix := range dbChanges {
+		changes = append(changes, models.ProductChange{
+			ProductID:     dbChanges[ix].ProductID,
+			Type:          models.ChangeType(dbChanges[ix].Type),
+			ChangedFields: dbChanges[ix].ChangedFields,
+		})
+	}
+
+	return changes, nil
+}
+
+// FromDBProduct converts postgres product model with its shippings into models.Product.
+func FromDBProduct(product *pgmodels.Product, shippings []pgmodels.Shipping) models.Product {
+	return models.Product{
+		ID:                  int(product.ID),
+		Version:             product.Version,
+		CreatedAt:           product.CreatedAt,
+		DeletedAt:           product.DeletedAt,
+		ProductID:           product.ProductID,
+		Title:               product.Title,
+		Description:         product.Description,
+		URL:                 product.URL,
+		ImageURL:            product.ImgURL,
+		AdditionalImageURLs: fromDBAdditionalImageURLs(product.AdditionalImgUrls),
+		Condition:           product.Condition,
+		Availability:        product.Availability,
+		Price:               product.Price,
+		SalePrice:           product.SalePrice,
+		Shippings:           fromDBShippings(shippings),
+		Brand:               product.Brand,
+		GTIN:                product.Gtin,
+		MPN:                 product.Mpn,
+		ProductCategory:     product.ProductCategory,
+		ProductType:         product.ProductType,
+		Color:               product.Color,
+		Size:                product.Size,
+		ItemGroupID:         product.ItemGroupID,
+		Gender:              product.Gender,
+		AgeGroup:            product.AgeGroup,
+	}
+}
+
+func fromDBShippings(shippings []pgmodels.Shipping) []models.Shipping {
+	if len(shippings) == 0 {
+		return nil
+	}
+
+	result := make([]models.Shipping, 0, len(shippings))
+	for ix := range shippings {
+		result = append(result, models.Shipping{
+			Country: shippings[ix].Country,
+			Service: shippings[ix].Service,
+			Price:   shippings[ix].Price,
+		})
+	}
+	return result
+}
+
 // ToDBProduct converts models.Product into postgres product model.
+// Product's version is set as its changed and created version, so it should be used only for created
+// or updated products. Created version of stored products isn't updated.
 func ToDBProduct(product *models.Product, shopID int64, id *int32) *pgmodels.Product {
+	priceAmount, _ := models.ParsePrice(product.Price)
+
 	dbProduct := pgmodels.Product{
 		Version:           product.Version,
 		ShopID:            int32(shopID),
@@ -39,6 +176,7 @@ func ToDBProduct(product *models.Product, shopID int64, id *int32) *pgmodels.Pro
 		Condition:         product.Condition,
 		Availability:      product.Availability,
 		Price:             product.Price,
+		SalePrice:         product.SalePrice,
 		Brand:             product.Brand,
 		Gtin:              product.GTIN,
 		Mpn:               product.MPN,
@@ -50,6 +188,10 @@ func ToDBProduct(product *models.Product, shopID int64, id *int32) *pgmodels.Pro
 		Gender:            product.Gender,
 		AgeGroup:          product.AgeGroup,
 		DeletedAt:         product.DeletedAt,
+		ContentHash:       lo.ToPtr(ContentHash(product)),
+		PriceAmount:       priceAmount,
+		ChangedVersion:    lo.ToPtr(product.Version),
+		CreatedVersion:    lo.ToPtr(product.Version),
 	}
 
 	if id != nil {
@@ -77,6 +219,14 @@ func ToDBShippings(productID int32, shippings []models.Shipping) []pgmodels.Ship
 	return dbShipping
 }
 
+func fromDBAdditionalImageURLs(urls string) []string {
+	if urls == "" {
+		return nil
+	}
+
+	return strings.Split(urls, "\n")
+}
+
 func toDBAdditionalImageURLs(urls []string) string {
 	if len(urls) == 0 {
 		return ""
@@ -92,3 +242,58 @@ func toDBAdditionalImageURLs(urls []string) string {
 	}
 	return result.String()
 }
+
+// contentHashVersion prefixes hashed product content. It must be changed whenever hashed fields
+// or their encoding change, as stored hashes of all products become outdated then.
+const contentHashVersion = "product-content-v1"
+
+// ContentHash returns stable hash of product's feed content - its attributes and shippings.
+// Fields are hashed explicitly in fixed order, so changes of product model don't change stored hashes.
+// Product's ID, version, creation and deletion time are not included, so the hash changes only with feed content.
+func ContentHash(product *models.Product) string {
+	hash := sha256.New()
+	// every value is prefixed with its length, so boundaries between values are unambiguous.
+	write := func(value string) {
+		_, _ = fmt.Fprintf(hash, "%d:%s;", len(value), value)
+	}
+	writeOptional := func(value *string) {
+		if value == nil {
+			_, _ = hash.Write([]byte("-;"))
+			return
+		}
+		write(*value)
+	}
+
+	write(contentHashVersion)
+	write(product.ProductID)
+	write(product.Title)
+	write(product.Description)
+	write(product.URL)
+	write(product.ImageURL)
+	write(strconv.Itoa(len(product.AdditionalImageURLs)))
+	for _, url := range product.AdditionalImageURLs {
+		write(url)
+	}
+	write(product.Condition)
+	write(product.Availability)
+	write(product.Price)
+	writeOptional(product.SalePrice)
+	write(strconv.Itoa(len(product.Shippings)))
+	for _, shipping := range product.Shippings {
+		write(shipping.Country)
+		write(shipping.Service)
+		write(shipping.Price)
+	}
+	writeOptional(product.Brand)
+	writeOptional(product.GTIN)
+	writeOptional(product.MPN)
+	writeOptional(product.ProductCategory)
+	writeOptional(product.ProductType)
+	writeOptional(product.Color)
+	writeOptional(product.Size)
+	writeOptional(product.ItemGroupID)
+	writeOptional(product.Gender)
+	writeOptional(product.AgeGroup)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
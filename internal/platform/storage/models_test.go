@@ -0,0 +1,100 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitContentHash(t *testing.T) {
+	product := func(modify func(p *models.Product)) models.Product {
+		p := models.Product{
+			ProductID:           "1",
+			Title:               "Shoes",
+			Description:         "Running shoes",
+			URL:                 "https://shop.example/shoes",
+			ImageURL:            "https://shop.example/shoes.jpg",
+			AdditionalImageURLs: []string{"https://shop.example/shoes-2.jpg"},
+			Condition:           "new",
+			Availability:        "in_stock",
+			Price:               "15.00 USD",
+			SalePrice:           lo.ToPtr("12.00 USD"),
+			Shippings:           []models.Shipping{{Country: "US", Service: "Standard", Price: "5.00 USD"}},
+			Brand:               lo.ToPtr("Brand"),
+			GTIN:                lo.ToPtr("0012345678905"),
+			ProductCategory:     lo.ToPtr("Apparel > Shoes"),
+			Color:               lo.ToPtr("red"),
+			Size:                lo.ToPtr("42"),
+		}
+		if modify != nil {
+			modify(&p)
+		}
+
+		return p
+	}
+
+	base := product(nil)
+	// stored hashes must not change, otherwise all stored products are updated in the next run.
+	wantHash := "34cb80ab1742fb8bc61b30a28c91a31baa9b36c2134db021f3fe9f9792abf359"
+
+	tests := map[string]struct {
+		product  models.Product
+		wantSame bool
+	}{
+		"storage attributes": {
+			product: product(func(p *models.Product) {
+				p.ID = 10
+				p.Version = 20
+				p.CreatedAt = time.Now()
+				p.DeletedAt = lo.ToPtr(time.Now())
+			}),
+			wantSame: true,
+		},
+		"without additional image urls and shippings": {
+			product: product(func(p *models.Product) {
+				p.AdditionalImageURLs = []string{}
+				p.Shippings = []models.Shipping{}
+			}),
+		},
+		"changed title": {
+			product: product(func(p *models.Product) { p.Title += " changed" }),
+		},
+		"changed shipping": {
+			product: product(func(p *models.Product) { p.Shippings[0].Price = "6.00 USD" }),
+		},
+		"empty instead of missing attribute": {
+			product: product(func(p *models.Product) { p.MPN = lo.ToPtr("") }),
+		},
+		"value moved between attributes": {
+			product: product(func(p *models.Product) { p.Title, p.Description = "Shoes Running", "shoes" }),
+		},
+	}
+
+	assert.Equal(t, wantHash, storage.ContentHash(&base), "should return known hash")
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			hash := storage.ContentHash(&tt.product)
+			if tt.wantSame {
+				assert.Equal(t, wantHash, hash, "should return the same hash")
+			} else {
+				assert.NotEqual(t, wantHash, hash, "should return different hash")
+			}
+		})
+	}
+
+	nilSlices := product(func(p *models.Product) {
+		p.AdditionalImageURLs = nil
+		p.Shippings = nil
+	})
+	emptySlices := product(func(p *models.Product) {
+		p.AdditionalImageURLs = []string{}
+		p.Shippings = []models.Shipping{}
+	})
+	assert.Equal(t, storage.ContentHash(&nilSlices), storage.ContentHash(&emptySlices),
+		"should return the same hash for nil and empty slices")
+}
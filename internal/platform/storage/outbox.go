@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+	"github.com/go-jet/jet/v2/qrm"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// PublishOutboxEvents passes up to limit oldest outbox events to publish function and removes published events.
+// Publishing stops at the first failed event. Events locked by other service instance are skipped,
+// so many instances can publish events at the same time, but then events order isn't guaranteed.
+// Returns number of published events.
+func (p Postgres) PublishOutboxEvents(
+	ctx context.Context,
+	limit int,
+	publish func(ctx context.Context, event models.OutboxEvent) error,
+) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		var events []pgmodels.Outbox
+		err := table.Outbox.SELECT(table.Outbox.ID, table.Outbox.EventType, table.Outbox.Payload).
+			ORDER_BY(table.Outbox.ID.ASC()).
+			LIMIT(int64(limit)).
+			FOR(pg.UPDATE().SKIP_LOCKED()).
+			QueryContext(ctx, tx, &events)
+		if err != nil {
+			return fmt.Errorf("can't get outbox events: %w", err)
+		}
+
+		ids := make([]pg.Expression, 0, len(events))
+		for ix := range events {
+			publishErr = publish(ctx, models.OutboxEvent{
+				ID:      events[ix].ID,
+				Type:    events[ix].EventType,
+				Payload: []byte(events[ix].Payload),
+			})
+			if publishErr != nil {
+				break
+			}
+			ids = append(ids, pg.Int64(events[ix].ID))
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		_, err = table.Outbox.DELETE().
+			WHERE(table.Outbox.ID.IN(ids...)).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't delete published outbox events: %w", err)
+		}
+
+		published = len(ids)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if publishErr != nil {
+		return published, fmt.Errorf("can't publish outbox event: %w", publishErr)
+	}
+
+	return published, nil
+}
+
+// insertProductEvents saves product events into outbox, so they're published only if transaction is committed.
+func insertProductEvents(ctx context.Context, db qrm.DB, events []commander.ProductEvent) error {
+	outbox := make([]pgmodels.Outbox, 0, len(events))
+	for ix := range events {
+		event, err := toOutbox(events[ix].Type, events[ix])
+		if err != nil {
+			return fmt.Errorf("can't marshal product event: %w", err)
+		}
+
+		outbox = append(outbox, event)
+	}
+
+	if err := insertOutbox(ctx, db, outbox); err != nil {
+		return fmt.Errorf("can't insert product events into outbox: %w", err)
+	}
+
+	return nil
+}
+
+// insertRunEvent saves run event into outbox, so it's published only if transaction is committed.
+func insertRunEvent(ctx context.Context, db qrm.DB, eventType string, shopURL string, run *models.Run) error {
+	event := commander.RunEvent{
+		Type:    eventType,
+		ShopID:  run.ShopID,
+		ShopURL: shopURL,
+	}
+	if eventType != commander.RunSkipped {
+		event.Run = toEventRun(run)
+	}
+
+	outbox, err := toOutbox(eventType, event)
+	if err != nil {
+		return fmt.Errorf("can't marshal run event: %w", err)
+	}
+
+	if err := insertOutbox(ctx, db, []pgmodels.Outbox{outbox}); err != nil {
+		return fmt.Errorf("can't insert run event into outbox: %w", err)
+	}
+
+	return nil
+}
+
+// finishedRunEvent returns type of event of finished run.
+func finishedRunEvent(run *models.Run) string {
+	if run.IsSuccess != nil && *run.IsSuccess {
+		return commander.RunFinished
+	}
+
+	return commander.RunFailed
+}
+
+func toOutbox(eventType string, event any) (pgmodels.Outbox, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return pgmodels.Outbox{}, err
+	}
+
+	return pgmodels.Outbox{
+		EventType: eventType,
+		Payload:   string(payload),
+	}, nil
+}
+
+func insertOutbox(ctx context.Context, db qrm.DB, outbox []pgmodels.Outbox) error {
+	if len(outbox) == 0 {
+		return nil
+	}
+
+	_, err := table.Outbox.INSERT(table.Outbox.EventType, table.Outbox.Payload).
+		MODELS(outbox).
+		ExecContext(ctx, db)
+
+	return err
+}
+
+func toEventRun(run *models.Run) *commander.Run {
+	return &commander.Run{
+		ID:                run.ID,
+		CreatedAt:         run.CreatedAt,
+		FinishedAt:        run.FinishedAt,
+		Status:            string(run.Status),
+		StatusMessage:     run.StatusMessage,
+		IsSuccess:         run.IsSuccess,
+		IsIncremental:     run.IsIncremental,
+		IsDryRun:          run.IsDryRun,
+		ProductsVersion:   run.ProductsVersion,
+		CreatedProducts:   run.CreatedProducts,
+		UpdatedProducts:   run.UpdatedProducts,
+		UnchangedProducts: run.UnchangedProducts,
+		DeletedProducts:   run.DeletedProducts,
+		FailedProducts:    run.FailedProducts,
+	}
+}
+
+// createdEvents returns events of created products.
+func createdEvents(products []models.Product, shopID int) []commander.ProductEvent {
+	events := make([]commander.ProductEvent, 0, len(products))
+	for ix := range products {
+		events = append(events, commander.ProductEvent{
+			Type:      commander.ProductCreated,
+			ShopID:    shopID,
+			ProductID: products[ix].ProductID,
+			Version:   products[ix].Version,
+		})
+	}
+
+	return events
+}
+
+// updatedEvents returns events of updated products with attributes changed since stored versions.
+// Products without any changed attribute are skipped.
+func updatedEvents(products []models.Product, stored []models.Product, shopID int) []commander.ProductEvent {
+	storedByID := make(map[string]*models.Product, len(stored))
+	for ix := range stored {
+		storedByID[stored[ix].ProductID] = &stored[ix]
+	}
+
+	events := make([]commander.ProductEvent, 0, len(products))
+	for ix := range products {
+		storedProduct, ok := storedByID[products[ix].ProductID]
+		if !ok {
+			continue
+		}
+
+		fields := models.ChangedFields(storedProduct, &products[ix])
+		if len(fields) == 0 {
+			continue
+		}
+
+		events = append(events, commander.ProductEvent{
+			Type:          commander.ProductUpdated,
+			ShopID:        shopID,
+			ProductID:     products[ix].ProductID,
+			Version:       products[ix].Version,
+			ChangedFields: fields,
+		})
+	}
+
+	return events
+}
+
+// deletedEvents returns events of deleted products.
+func deletedEvents(products []pgmodels.Product) []commander.ProductEvent {
+	events := make([]commander.ProductEvent, 0, len(products))
+	for ix := range products {
+		events = append(events, commander.ProductEvent{
+			Type:      commander.ProductDeleted,
+			ShopID:    int(products[ix].ShopID),
+			ProductID: products[ix].ProductID,
+			Version:   products[ix].Version,
+		})
+	}
+
+	return events
+}
@@ -5,12 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"sync/atomic"
 	"time"
 
 	"github.com/MichalMitros/google-feed-parser/internal/platform"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
 	"github.com/samber/lo"
 	"golang.org/x/sync/errgroup"
 
@@ -19,33 +21,75 @@ import (
 	"github.com/go-jet/jet/v2/qrm"
 )
 
+// DefaultRunLease is default duration after which running run without lease renewal is considered abandoned.
+const DefaultRunLease = 5 * time.Minute
+
+// Option is custom configuration of Postgres.
+type Option func(p *Postgres)
+
 // Postgres is storage for shops, runs, products and shippings.
 type Postgres struct {
 	db            *sql.DB
 	parallelLimit int
+	runLease      time.Duration
+	bulkLoading   bool
 }
 
 // NewPostgres returns new Postgres.
-func NewPostgres(db *sql.DB) Postgres {
-	return Postgres{
+func NewPostgres(db *sql.DB, ops ...Option) Postgres {
+	post := Postgres{
 		db:            db,
 		parallelLimit: 5,
+		runLease:      DefaultRunLease,
+	}
+
+	for _, op := range ops {
+		op(&post)
 	}
+
+	return post
 }
 
-// StartRun creates new unfinished run in database and returns it.
-// It returns ErrAlreadyRunning if previous run is not finished yet.
-func (p Postgres) StartRun(ctx context.Context, shopURL string, version int64) (*models.Run, error) {
-	run := &models.Run{
-		ProductsVersion: version,
+// WithRunLease sets duration after which running run without lease renewal is considered abandoned.
+func WithRunLease(lease time.Duration) Option {
+	return func(p *Postgres) {
+		p.runLease = lease
 	}
+}
 
+// WithBulkLoading makes Postgres store products by copying them into unlogged staging tables
+// and merging them with set-based queries, instead of multi-row inserts.
+func WithBulkLoading() Option {
+	return func(p *Postgres) {
+		p.bulkLoading = true
+	}
+}
+
+// StartRun inserts provided run as new unfinished run of the shop in database and sets its ID, shop and creation time.
+// Shop is added if it doesn't exist yet, unless the run is a dry run, which returns ErrShopNotFound instead.
+// Shop isn't added for previous url of not deleted shop, ErrShopURLChanged is returned instead.
+// It returns ErrAlreadyRunning if previous run is not finished yet,
+// and ErrShopDisabled or ErrShopDeleted if the shop is disabled or deleted.
+// Events of started, skipped and abandoned runs are saved to outbox.
+func (p Postgres) StartRun(ctx context.Context, shopURL string, run *models.Run) error {
+	run.Status = models.RunStatusRunning
+
+	var shopID int32
 	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
-		shop, err := getShop(ctx, tx, shopURL)
+		shop, err := getShop(ctx, tx, shopURL, !run.IsDryRun)
 		if err != nil {
 			return fmt.Errorf("can't get shop from database: %w", err)
 		}
 
+		if shop.DeletedAt != nil {
+			return platform.ErrShopDeleted
+		}
+
+		if shop.Disabled {
+			return platform.ErrShopDisabled
+		}
+
+		shopID = shop.ID
 		run.ShopID = int(shop.ID)
 
 		lastRun, err := getLastRun(ctx, tx, int64(shop.ID))
@@ -55,99 +99,200 @@ func (p Postgres) StartRun(ctx context.Context, shopURL string, version int64) (
 		}
 
 		if lastRun != nil && lastRun.FinishedAt == nil && lastRun.Success == nil {
-			return platform.ErrAlreadyRunning
+			if lastRun.HeartbeatAt.After(time.Now().Add(-p.runLease)) {
+				return platform.ErrAlreadyRunning
+			}
+
+			if err := closeAbandonedRun(ctx, tx, lastRun.ID, shopURL); err != nil {
+				return fmt.Errorf("can't close abandoned run: %w", err)
+			}
 		}
 
 		newRun := toDBRun(run)
 		err = table.Run.INSERT(
 			table.Run.ProductsVersion,
 			table.Run.ShopID,
+			table.Run.Incremental,
+			table.Run.DryRun,
 		).
 			MODEL(newRun).
-			RETURNING(table.Run.ID).
+			RETURNING(table.Run.ID, table.Run.CreatedAt).
 			QueryContext(ctx, tx, newRun)
 		if err != nil {
 			return fmt.Errorf("can't insert run into database: %w", err)
 		}
 
 		run.ID = int(newRun.ID)
+		run.CreatedAt = newRun.CreatedAt
 
-		return nil
+		return insertRunEvent(ctx, tx, commander.RunStarted, shopURL, run)
 	})
+
+	// skipped run isn't saved, so its event is saved alone.
+	if errors.Is(err, platform.ErrAlreadyRunning) {
+		skipped := &models.Run{ShopID: int(shopID)}
+		if evErr := insertRunEvent(ctx, p.db, commander.RunSkipped, shopURL, skipped); evErr != nil {
+			return fmt.Errorf("can't add run: %w (skip reason: %w)", evErr, err)
+		}
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("can't add run: %w", err)
+		return fmt.Errorf("can't add run: %w", err)
 	}
 
-	return run, nil
+	return nil
 }
 
-// FinishRun sets run as finished and updates run's statistics.
+// FinishRun sets run as finished, updates run's statistics and saves event of finished or failed run to outbox.
 func (p Postgres) FinishRun(ctx context.Context, run *models.Run) error {
-	columnList := table.Run.AllColumns.Except(table.Run.ID, table.Run.CreatedAt, table.Run.ProductsVersion)
+	columnList := table.Run.AllColumns.Except(
+		table.Run.ID,
+		table.Run.CreatedAt,
+		table.Run.ProductsVersion,
+		table.Run.HeartbeatAt,
+	)
 
-	result, err := table.Run.UPDATE(columnList).
-		MODEL(toDBRun(run)).
-		WHERE(table.Run.ID.EQ(pg.Int32(int32(run.ID)))).
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		result, err := table.Run.UPDATE(columnList).
+			MODEL(toDBRun(run)).
+			WHERE(table.Run.ID.EQ(pg.Int32(int32(run.ID)))).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't update run: %w", err)
+		}
+
+		if rowsAffected, err := result.RowsAffected(); rowsAffected == 0 || err != nil {
+			return fmt.Errorf("can't update run: %w", err)
+		}
+
+		var shop pgmodels.Shop
+		err = table.Shop.SELECT(table.Shop.URL).
+			WHERE(table.Shop.ID.EQ(pg.Int32(int32(run.ShopID)))).
+			QueryContext(ctx, tx, &shop)
+		if err != nil {
+			return fmt.Errorf("can't get run's shop: %w", err)
+		}
+
+		return insertRunEvent(ctx, tx, finishedRunEvent(run), shop.URL, run)
+	})
+}
+
+// RenewRunLease updates heartbeat of running run, so it's not considered abandoned.
+// It returns ErrRunNotRunning if the run is already finished.
+func (p Postgres) RenewRunLease(ctx context.Context, runID int) error {
+	result, err := table.Run.UPDATE().
+		SET(
+			table.Run.HeartbeatAt.SET(pg.TimestampzT(time.Now())),
+		).
+		WHERE(pg.AND(
+			table.Run.ID.EQ(pg.Int32(int32(runID))),
+			table.Run.FinishedAt.IS_NULL(),
+		)).
 		ExecContext(ctx, p.db)
 	if err != nil {
-		return fmt.Errorf("can't update run: %w", err)
+		return fmt.Errorf("can't update run heartbeat: %w", err)
 	}
 
-	if rowsAffected, err := result.RowsAffected(); rowsAffected == 0 || err != nil {
-		return fmt.Errorf("can't update run: %w", err)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("can't update run heartbeat: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return platform.ErrRunNotRunning
 	}
 
 	return nil
 }
 
 // Update products upserts products and their shippings.
-// It returns number of new products and number of updated products or error.
-func (p Postgres) UpdateProducts(ctx context.Context, products []models.Product, shopID int) (int32, int32, error) {
+// Products with the same content hash as stored ones only get new version.
+// Events of created and updated products are saved to outbox and changes of updated products are saved
+// to products history of provided run in the same transaction.
+// It returns number of new, updated and unchanged products or error.
+func (p Postgres) UpdateProducts(
+	ctx context.Context,
+	products []models.Product,
+	shopID int,
+	runID int,
+) (int32, int32, int32, error) {
 	createdProductsNumber := lo.ToPtr(int32(0))
 	updatedProductsNumber := lo.ToPtr(int32(0))
+	unchangedProductsNumber := lo.ToPtr(int32(0))
 
 	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
 		productIDs := lo.Map(products, func(_ models.Product, ix int) string {
 			return products[ix].ProductID
 		})
-		storedVersions, err := getProductsVersions(ctx, tx, int64(shopID), productIDs)
+		storedProducts, err := getStoredProducts(ctx, tx, int64(shopID), productIDs)
 		if err != nil {
 			return fmt.Errorf("can't get existing products: %w", err)
 		}
 
-		newProducts, updatedProducts := compareProducts(products, storedVersions)
+		newProducts, updatedProducts, unchangedProducts := compareProducts(products, storedProducts)
 
-		if newProducts, err = upsertProducts(ctx, tx, newProducts, int32(shopID)); err != nil {
-			return fmt.Errorf("can't insert new products: %w", err)
+		updatedIDs := lo.Map(updatedProducts, func(_ models.Product, ix int) string {
+			return updatedProducts[ix].ProductID
+		})
+		previousProducts, err := getProducts(ctx, tx, shopID, updatedIDs)
+		if err != nil {
+			return fmt.Errorf("can't get updated products: %w", err)
+		}
+
+		events := append(createdEvents(newProducts, shopID), updatedEvents(updatedProducts, previousProducts, shopID)...)
+		if err = insertProductEvents(ctx, tx, events); err != nil {
+			return err
+		}
+
+		history, err := updatedHistory(updatedProducts, previousProducts, runID)
+		if err != nil {
+			return err
 		}
 
-		if updatedProducts, err = upsertProducts(ctx, tx, updatedProducts, int32(shopID)); err != nil {
-			return fmt.Errorf("can't insert new products: %w", err)
+		if err = insertHistory(ctx, tx, history); err != nil {
+			return err
 		}
 
-		if err = insertShippings(ctx, tx, newProducts); err != nil {
-			return fmt.Errorf("can't create new products shippings: %w", err)
+		if err = updateVersions(ctx, tx, unchangedProducts); err != nil {
+			return fmt.Errorf("can't update unchanged products versions: %w", err)
+		}
+
+		if p.bulkLoading {
+			err = bulkUpsertProducts(ctx, tx, slices.Concat(newProducts, updatedProducts), int32(shopID))
+		} else {
+			err = upsertAll(ctx, tx, newProducts, updatedProducts, int32(shopID))
+		}
+		if err != nil {
+			return err
 		}
 
-		if err = insertShippings(ctx, tx, updatedProducts); err != nil {
-			return fmt.Errorf("can't update products shippings: %w", err)
+		if err = insertPriceSeries(ctx, tx, shopID, runID, newProducts, updatedProducts, previousProducts); err != nil {
+			return err
 		}
 
 		*createdProductsNumber = int32(len(newProducts))
 		*updatedProductsNumber = int32(len(updatedProducts))
+		*unchangedProductsNumber = int32(len(unchangedProducts))
 
 		return nil
 	})
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, 0, err
 	}
 
-	return *createdProductsNumber, *updatedProductsNumber, nil
+	return *createdProductsNumber, *updatedProductsNumber, *unchangedProductsNumber, nil
 }
 
 // DeleteOldProducts updates DeletedAt field of shop products with version lower than provided.
+// Products are deleted in batches, each batch is saved together with its deletion events and history of provided run.
 // Returns number of deleted products or error.
-func (p Postgres) DeleteOldProducts(ctx context.Context, shopID int, version int64, batchSize uint) (int32, error) {
+func (p Postgres) DeleteOldProducts(
+	ctx context.Context,
+	shopID int,
+	runID int,
+	version int64,
+	batchSize uint,
+) (int32, error) {
 	deletedProductsNumber := int32(0)
 
 	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
@@ -160,7 +305,7 @@ func (p Postgres) DeleteOldProducts(ctx context.Context, shopID int, version int
 		})
 
 		errGroup.Go(func() error {
-			deletedCount, err := deleteProductsAsync(egCtx, p.db, toDelete)
+			deletedCount, err := deleteProductsAsync(egCtx, p.db, runID, toDelete)
 			if err == nil {
 				atomic.AddInt32(&deletedProductsNumber, int32(deletedCount))
 			}
@@ -176,17 +321,138 @@ func (p Postgres) DeleteOldProducts(ctx context.Context, shopID int, version int
 	return deletedProductsNumber, nil
 }
 
-func compareProducts(parsed []models.Product, storedVersions map[string]int64) ([]models.Product, []models.Product) {
+// DeleteProducts updates DeletedAt field of not-deleted shop products with provided product IDs
+// and saves their deletion events and history of provided run.
+// Returns number of deleted products or error.
+func (p Postgres) DeleteProducts(ctx context.Context, shopID int, runID int, productIDs []string) (int32, error) {
+	if len(productIDs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]pg.Expression, 0, len(productIDs))
+	for ix := range productIDs {
+		ids = append(ids, pg.String(productIDs[ix]))
+	}
+
+	deleted := 0
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		var err error
+		deleted, err = deleteProducts(ctx, tx, pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.ProductID.IN(ids...),
+			table.Product.DeletedAt.IS_NULL(),
+		), runID, time.Now())
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can't delete products: %w", err)
+	}
+
+	return int32(deleted), nil
+}
+
+// CountProducts returns number of not-deleted shop products and number of those with version lower than provided.
+func (p Postgres) CountProducts(ctx context.Context, shopID int, version int64) (int32, int32, error) {
+	var counts struct {
+		Active   int32
+		Outdated int32
+	}
+
+	err := table.Product.SELECT(
+		pg.COUNT(table.Product.ID).AS("active"),
+		pg.COUNT(
+			pg.CASE().
+				WHEN(table.Product.Version.LT(pg.Int64(version))).
+				THEN(table.Product.ID),
+		).AS("outdated"),
+	).
+		WHERE(pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.DeletedAt.IS_NULL(),
+		)).
+		QueryContext(ctx, p.db, &counts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't count products: %w", err)
+	}
+
+	return counts.Active, counts.Outdated, nil
+}
+
+// compareProducts splits parsed products into new, updated and unchanged ones.
+// Stored products with not-lower version are skipped, unchanged products get stored products IDs.
+func compareProducts(
+	parsed []models.Product,
+	stored map[string]pgmodels.Product,
+) ([]models.Product, []models.Product, []models.Product) {
 	newProducts := make([]models.Product, 0, len(parsed))
+	unchangedProducts := make([]models.Product, 0, len(parsed))
 	updatedProducts := lo.Filter(parsed, func(_ models.Product, ix int) bool {
-		if version, ok := storedVersions[parsed[ix].ProductID]; ok {
-			return parsed[ix].Version > version
+		storedProduct, ok := stored[parsed[ix].ProductID]
+		if !ok {
+			newProducts = append(newProducts, parsed[ix])
+			return false
+		}
+
+		if parsed[ix].Version <= storedProduct.Version {
+			return false
+		}
+
+		if storedProduct.DeletedAt == nil && storedProduct.ContentHash != nil &&
+			*storedProduct.ContentHash == ContentHash(&parsed[ix]) {
+			unchangedProducts = append(unchangedProducts, parsed[ix])
+			unchangedProducts[len(unchangedProducts)-1].ID = int(storedProduct.ID)
+			return false
 		}
-		newProducts = append(newProducts, parsed[ix])
-		return false
+
+		return true
 	})
 
-	return newProducts, updatedProducts
+	return newProducts, updatedProducts, unchangedProducts
+}
+
+// updateVersions sets new versions of unchanged products without rewriting their attributes and shippings.
+func updateVersions(ctx context.Context, db qrm.DB, products []models.Product) error {
+	byVersion := lo.GroupBy(products, func(product models.Product) int64 { return product.Version })
+
+	for version, versionProducts := range byVersion {
+		ids := make([]pg.Expression, 0, len(versionProducts))
+		for ix := range versionProducts {
+			ids = append(ids, pg.Int32(int32(versionProducts[ix].ID)))
+		}
+
+		_, err := table.Product.UPDATE().
+			SET(table.Product.Version.SET(pg.Int64(version))).
+			WHERE(table.Product.ID.IN(ids...)).
+			ExecContext(ctx, db)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// upsertAll upserts new and updated products and replaces their shippings with multi-row inserts.
+func upsertAll(ctx context.Context, db qrm.DB, newProducts, updatedProducts []models.Product, shopID int32) error {
+	newProducts, err := upsertProducts(ctx, db, newProducts, shopID)
+	if err != nil {
+		return fmt.Errorf("can't insert new products: %w", err)
+	}
+
+	if updatedProducts, err = upsertProducts(ctx, db, updatedProducts, shopID); err != nil {
+		return fmt.Errorf("can't insert new products: %w", err)
+	}
+
+	if err = insertShippings(ctx, db, newProducts); err != nil {
+		return fmt.Errorf("can't create new products shippings: %w", err)
+	}
+
+	if err = insertShippings(ctx, db, updatedProducts); err != nil {
+		return fmt.Errorf("can't update products shippings: %w", err)
+	}
+
+	return nil
 }
 
 func upsertProducts(ctx context.Context, db qrm.DB, products []models.Product, shopID int32) ([]models.Product, error) {
@@ -201,17 +467,12 @@ func upsertProducts(ctx context.Context, db qrm.DB, products []models.Product, s
 		dbProducts = append(dbProducts, *ToDBProduct(&products[ix], int64(shopID), nil))
 	}
 
-	excludedExpressions := make([]pg.Expression, 0, len(columnList)) // converting to expression
-	for _, col := range table.Product.EXCLUDED.AllColumns.Except(table.Product.ID, table.Product.CreatedAt) {
-		excludedExpressions = append(excludedExpressions, col)
-	}
-
 	_, err := table.Product.INSERT(columnList).
 		MODELS(dbProducts).
 		ON_CONFLICT(table.Product.ShopID, table.Product.ProductID).
 		DO_UPDATE(
 			pg.SET(
-				columnList.SET(pg.ROW(excludedExpressions...)),
+				updatedColumns().SET(pg.ROW(excludedColumns()...)),
 			),
 		).
 		ExecContext(ctx, db)
@@ -248,12 +509,26 @@ func upsertProducts(ctx context.Context, db qrm.DB, products []models.Product, s
 	return upsertedProducts, nil
 }
 
-func insertShippings(ctx context.Context, db qrm.DB, products []models.Product) error {
-	shippings := []pgmodels.Shipping{}
-	for ix := range products {
-		shippings = append(shippings, ToDBShippings(int32(products[ix].ID), products[ix].Shippings)...)
+// updatedColumns returns product's columns updated when stored product is upserted.
+func updatedColumns() pg.ColumnList {
+	return table.Product.AllColumns.Except(table.Product.ID, table.Product.CreatedAt, table.Product.CreatedVersion)
+}
+
+// excludedColumns returns excluded row's columns matching updatedColumns.
+func excludedColumns() []pg.Expression {
+	columns := table.Product.EXCLUDED.AllColumns.
+		Except(table.Product.ID, table.Product.CreatedAt, table.Product.CreatedVersion)
+
+	expressions := make([]pg.Expression, 0, len(columns)) // converting to expression
+	for _, col := range columns {
+		expressions = append(expressions, col)
 	}
-	if len(shippings) == 0 {
+
+	return expressions
+}
+
+func insertShippings(ctx context.Context, db qrm.DB, products []models.Product) error {
+	if len(products) == 0 {
 		return nil
 	}
 
@@ -269,6 +544,14 @@ func insertShippings(ctx context.Context, db qrm.DB, products []models.Product)
 		return fmt.Errorf("can't delete outdated products shippings from database: %w", err)
 	}
 
+	shippings := []pgmodels.Shipping{}
+	for ix := range products {
+		shippings = append(shippings, ToDBShippings(int32(products[ix].ID), products[ix].Shippings)...)
+	}
+	if len(shippings) == 0 {
+		return nil
+	}
+
 	_, err = table.Shipping.INSERT(table.Shipping.AllColumns.Except(table.Shipping.ID)).
 		MODELS(shippings).
 		ExecContext(ctx, db)
@@ -279,12 +562,24 @@ func insertShippings(ctx context.Context, db qrm.DB, products []models.Product)
 	return nil
 }
 
-func getShop(ctx context.Context, db qrm.DB, url string) (*pgmodels.Shop, error) {
+// getShop returns shop with provided url. Missing shop is added if create is set,
+// otherwise platform.ErrShopNotFound is returned. Returns platform.ErrShopURLChanged for previous url of a shop.
+func getShop(ctx context.Context, db qrm.DB, url string, create bool) (*pgmodels.Shop, error) {
 	var shop pgmodels.Shop
 	err := table.Shop.SELECT(table.Shop.AllColumns).
 		WHERE(table.Shop.URL.EQ(pg.String(url))).
 		QueryContext(ctx, db, &shop)
 
+	if errors.Is(err, qrm.ErrNoRows) {
+		if err := checkURLHistory(ctx, db, url); err != nil {
+			return nil, err
+		}
+	}
+
+	if errors.Is(err, qrm.ErrNoRows) && !create {
+		return nil, platform.ErrShopNotFound
+	}
+
 	if errors.Is(err, qrm.ErrNoRows) {
 		return insertShop(ctx, db, url)
 	}
@@ -296,6 +591,33 @@ func getShop(ctx context.Context, db qrm.DB, url string) (*pgmodels.Shop, error)
 	return &shop, nil
 }
 
+// checkURLHistory returns platform.ErrShopURLChanged if provided url is previous url of not deleted shop,
+// so requests with outdated url don't add new empty shop. Previous urls of deleted shops can be used by new shops.
+// The error names the shop and its current url, so the operator knows which url should be used instead.
+func checkURLHistory(ctx context.Context, db qrm.DB, url string) error {
+	var shops []pgmodels.Shop
+	err := table.ShopURLHistory.
+		INNER_JOIN(table.Shop, table.Shop.ID.EQ(table.ShopURLHistory.ShopID)).
+		SELECT(table.Shop.ID, table.Shop.URL).
+		WHERE(pg.AND(
+			table.ShopURLHistory.URL.EQ(pg.String(url)),
+			table.Shop.DeletedAt.IS_NULL(),
+		)).
+		ORDER_BY(table.ShopURLHistory.ID.DESC()).
+		LIMIT(1).
+		QueryContext(ctx, db, &shops)
+	if err != nil {
+		return fmt.Errorf("can't get shop url history: %w", err)
+	}
+
+	if len(shops) > 0 {
+		return fmt.Errorf("%w: url was replaced in shop %d with %s, use its current url or delete the shop",
+			platform.ErrShopURLChanged, shops[0].ID, shops[0].URL)
+	}
+
+	return nil
+}
+
 func insertShop(ctx context.Context, db qrm.DB, url string) (*pgmodels.Shop, error) {
 	shop := pgmodels.Shop{
 		URL: url,
@@ -322,11 +644,13 @@ func insertShop(ctx context.Context, db qrm.DB, url string) (*pgmodels.Shop, err
 func getLastRun(ctx context.Context, db qrm.DB, shopID int64) (*pgmodels.Run, error) {
 	var run pgmodels.Run
 	err := table.Run.SELECT(
+		table.Run.ID,
 		table.Run.CreatedAt,
 		table.Run.FinishedAt,
 		table.Run.Success,
 		table.Run.StatusMessage,
 		table.Run.FailedProducts,
+		table.Run.HeartbeatAt,
 	).
 		WHERE(table.Run.ShopID.EQ(pg.Int(shopID))).
 		ORDER_BY(table.Run.CreatedAt.DESC()).
@@ -339,6 +663,81 @@ func getLastRun(ctx context.Context, db qrm.DB, shopID int64) (*pgmodels.Run, er
 	return &run, nil
 }
 
+// CloseAbandonedRuns finishes up to limit unfinished runs which lease expired as failed and saves their events
+// to outbox, so abandoned runs are closed even if their shops aren't parsed again.
+// Runs locked by other transactions are skipped. Returns number of closed runs.
+func (p Postgres) CloseAbandonedRuns(ctx context.Context, limit int) (int, error) {
+	closed := 0
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		var abandoned []struct {
+			pgmodels.Run
+			Shop pgmodels.Shop
+		}
+		err := pg.SELECT(table.Run.ID, table.Shop.URL).
+			FROM(table.Run.INNER_JOIN(table.Shop, table.Shop.ID.EQ(table.Run.ShopID))).
+			WHERE(pg.AND(
+				table.Run.FinishedAt.IS_NULL(),
+				table.Run.Success.IS_NULL(),
+				table.Run.HeartbeatAt.LT_EQ(pg.TimestampzT(time.Now().Add(-p.runLease))),
+			)).
+			ORDER_BY(table.Run.ID.ASC()).
+			LIMIT(int64(limit)).
+			FOR(pg.UPDATE().OF(table.Run).SKIP_LOCKED()).
+			QueryContext(ctx, tx, &abandoned)
+		if err != nil {
+			return fmt.Errorf("can't get abandoned runs: %w", err)
+		}
+
+		for ix := range abandoned {
+			if err := closeAbandonedRun(ctx, tx, abandoned[ix].ID, abandoned[ix].Shop.URL); err != nil {
+				return fmt.Errorf("can't close abandoned run: %w", err)
+			}
+		}
+
+		closed = len(abandoned)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return closed, nil
+}
+
+// closeAbandonedRun finishes run which lease expired as failed and saves event of failed run.
+// Already finished run is left unchanged.
+func closeAbandonedRun(ctx context.Context, db qrm.DB, runID int32, shopURL string) error {
+	var abandoned pgmodels.Run
+	err := table.Run.UPDATE().
+		SET(
+			table.Run.FinishedAt.SET(pg.TimestampzT(time.Now())),
+			table.Run.Success.SET(pg.Bool(false)),
+			table.Run.Status.SET(pg.String(string(models.RunStatusFailed))),
+			table.Run.StatusMessage.SET(pg.String("run abandoned, its lease expired")),
+		).
+		WHERE(pg.AND(
+			table.Run.ID.EQ(pg.Int32(runID)),
+			table.Run.FinishedAt.IS_NULL(),
+		)).
+		RETURNING(table.Run.AllColumns).
+		QueryContext(ctx, db, &abandoned)
+	if errors.Is(err, qrm.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	run, err := FromDBRun(&abandoned)
+	if err != nil {
+		return err
+	}
+
+	return insertRunEvent(ctx, db, commander.RunFailed, shopURL, run)
+}
+
 func getOutdatedProductsAsync(
 	ctx context.Context,
 	db qrm.DB,
@@ -385,7 +784,7 @@ func getOutdatedProductsAsync(
 	}
 }
 
-func deleteProductsAsync(ctx context.Context, db qrm.DB, toDelete chan []int32) (int, error) {
+func deleteProductsAsync(ctx context.Context, db *sql.DB, runID int, toDelete chan []int32) (int, error) {
 	deletedCount := 0
 	now := time.Now()
 	for batch := range toDelete {
@@ -394,28 +793,74 @@ func deleteProductsAsync(ctx context.Context, db qrm.DB, toDelete chan []int32)
 			ids = append(ids, pg.Int32(id))
 		}
 
-		_, err := table.Product.UPDATE().
-			SET(
-				table.Product.DeletedAt.SET(pg.TimestampzT(now)),
-			).
-			WHERE(table.Product.ID.IN(ids...)).
-			ExecContext(ctx, db)
+		deleted := 0
+		err := runInTransaction(ctx, db, func(tx *sql.Tx) error {
+			var err error
+			deleted, err = deleteProducts(ctx, tx, table.Product.ID.IN(ids...), runID, now)
+			return err
+		})
 		if err != nil {
 			return deletedCount, err
 		}
-		deletedCount += len(batch)
+		deletedCount += deleted
 	}
 	return deletedCount, nil
 }
 
-func getProductsVersions(ctx context.Context, db qrm.DB, shopID int64, productIDs []string) (map[string]int64, error) {
+// deleteProducts sets deletion time of products matching condition and their changed version to products version
+// of provided run, saves their deletion events to outbox and their deletion to products history of the run.
+// Returns number of deleted products.
+func deleteProducts(ctx context.Context, db qrm.DB, condition pg.BoolExpression, runID int, now time.Time) (int, error) {
+	var deleted []pgmodels.Product
+	err := table.Product.UPDATE().
+		SET(
+			table.Product.DeletedAt.SET(pg.TimestampzT(now)),
+			table.Product.ChangedVersion.SET(pg.IntExp(
+				table.Run.SELECT(table.Run.ProductsVersion).WHERE(table.Run.ID.EQ(pg.Int(int64(runID)))),
+			)),
+		).
+		WHERE(condition).
+		RETURNING(table.Product.ID, table.Product.ShopID, table.Product.ProductID, table.Product.Version).
+		QueryContext(ctx, db, &deleted)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertProductEvents(ctx, db, deletedEvents(deleted)); err != nil {
+		return 0, err
+	}
+
+	history, err := deletedHistory(deleted, runID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertHistory(ctx, db, history); err != nil {
+		return 0, err
+	}
+
+	return len(deleted), nil
+}
+
+func getStoredProducts(
+	ctx context.Context,
+	db qrm.DB,
+	shopID int64,
+	productIDs []string,
+) (map[string]pgmodels.Product, error) {
 	ids := make([]pg.Expression, 0, len(productIDs))
 	for ix := range productIDs {
 		ids = append(ids, pg.String(productIDs[ix]))
 	}
 
 	products := make([]pgmodels.Product, 0, len(productIDs))
-	err := table.Product.SELECT(table.Product.AllColumns).
+	err := table.Product.SELECT(
+		table.Product.ID,
+		table.Product.ProductID,
+		table.Product.Version,
+		table.Product.ContentHash,
+		table.Product.DeletedAt,
+	).
 		WHERE(pg.AND(
 			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
 			table.Product.ProductID.IN(ids...),
@@ -425,12 +870,7 @@ func getProductsVersions(ctx context.Context, db qrm.DB, shopID int64, productID
 		return nil, err
 	}
 
-	result := make(map[string]int64, len(products))
-	for ix := range products {
-		result[products[ix].ProductID] = products[ix].Version
-	}
-
-	return result, nil
+	return lo.KeyBy(products, func(product pgmodels.Product) string { return product.ProductID }), nil
 }
 
 func runInTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
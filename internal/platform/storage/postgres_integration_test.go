@@ -3,8 +3,11 @@ package storage_test
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -14,8 +17,11 @@ import (
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models/modelstesting"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/storage"
 	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/storagetesting"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
 	"github.com/go-faker/faker/v4"
+	pg "github.com/go-jet/jet/v2/postgres"
 	_ "github.com/lib/pq"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -60,12 +66,18 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 	tests := map[string]struct {
 		storedShop *pgmodels.Shop
 		storedRuns []pgmodels.Run
+		dryRun     bool
 		wantRun    *models.Run
 		wantErr    error
 	}{
+		"dry run of new shop error": {
+			dryRun:  true,
+			wantErr: platform.ErrShopNotFound,
+		},
 		"new shop": {
 			wantRun: &models.Run{
 				ProductsVersion: version,
+				Status:          models.RunStatusRunning,
 			},
 		},
 		"first run": {
@@ -76,6 +88,7 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 			wantRun: &models.Run{
 				ShopID:          123,
 				ProductsVersion: version,
+				Status:          models.RunStatusRunning,
 			},
 		},
 		"after successful run": {
@@ -94,6 +107,7 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 			wantRun: &models.Run{
 				ShopID:          123,
 				ProductsVersion: version,
+				Status:          models.RunStatusRunning,
 			},
 		},
 		"after failed run": {
@@ -112,6 +126,7 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 			wantRun: &models.Run{
 				ShopID:          123,
 				ProductsVersion: version,
+				Status:          models.RunStatusRunning,
 			},
 		},
 		"already running error": {
@@ -123,10 +138,45 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 				{
 					ShopID:          123,
 					ProductsVersion: version - 1,
+					HeartbeatAt:     time.Now(),
 				},
 			},
 			wantErr: platform.ErrAlreadyRunning,
 		},
+		"disabled shop error": {
+			storedShop: &pgmodels.Shop{
+				ID:       123,
+				URL:      shopURL,
+				Disabled: true,
+			},
+			wantErr: platform.ErrShopDisabled,
+		},
+		"deleted shop error": {
+			storedShop: &pgmodels.Shop{
+				ID:        123,
+				URL:       shopURL,
+				DeletedAt: lo.ToPtr(time.Now()),
+			},
+			wantErr: platform.ErrShopDeleted,
+		},
+		"after abandoned run": {
+			storedShop: &pgmodels.Shop{
+				ID:  123,
+				URL: shopURL,
+			},
+			storedRuns: []pgmodels.Run{
+				{
+					ShopID:          123,
+					ProductsVersion: version - 1,
+					HeartbeatAt:     time.Now().Add(-storage.DefaultRunLease - time.Minute),
+				},
+			},
+			wantRun: &models.Run{
+				ShopID:          123,
+				ProductsVersion: version,
+				Status:          models.RunStatusRunning,
+			},
+		},
 	}
 
 	for name, tt := range tests {
@@ -143,7 +193,12 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 
 			post := storage.NewPostgres(s.DB)
 
-			run, err := post.StartRun(context.TODO(), shopURL, version)
+			run := &models.Run{ProductsVersion: version, IsDryRun: tt.dryRun}
+			err := post.StartRun(context.TODO(), shopURL, run)
+
+			if tt.dryRun && tt.storedShop == nil {
+				s.Zero(storagetesting.GetShopID(s.T(), s.DB, shopURL), "shouldn't add shop in dry run")
+			}
 
 			if tt.wantErr == nil {
 				s.Require().NoError(err, "shouldn't return any error")
@@ -155,6 +210,144 @@ func (s *PostgresTestSuite) TestIntegrationStartRun() {
 	}
 }
 
+func (s *PostgresTestSuite) TestIntegrationRunLease() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopURL := faker.Word()
+	version := rand.Int63()
+	lease := time.Hour
+
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: 1, URL: shopURL})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{
+		ID:              1,
+		ShopID:          1,
+		ProductsVersion: version - 1,
+		Status:          string(models.RunStatusRunning),
+		HeartbeatAt:     time.Now().Add(-lease - time.Minute),
+	})
+
+	post := storage.NewPostgres(s.DB, storage.WithRunLease(lease))
+
+	run := &models.Run{ProductsVersion: version}
+	err := post.StartRun(context.TODO(), shopURL, run)
+	s.Require().NoError(err, "should start run after abandoned run")
+
+	runs := storagetesting.GetRuns(s.T(), s.DB)
+	abandoned, ok := lo.Find(runs, func(r pgmodels.Run) bool { return r.ID == 1 })
+	s.Require().True(ok, "should keep abandoned run")
+	s.NotNil(abandoned.FinishedAt, "should finish abandoned run")
+	s.Equal(lo.ToPtr(false), abandoned.Success, "should mark abandoned run as failed")
+	s.Equal(string(models.RunStatusFailed), abandoned.Status, "should set failed status")
+
+	err = post.StartRun(context.TODO(), shopURL, &models.Run{ProductsVersion: version + 1})
+	s.Require().ErrorIs(err, platform.ErrAlreadyRunning, "shouldn't start run while new run is running")
+
+	s.Require().NoError(post.RenewRunLease(context.TODO(), run.ID), "should renew lease of running run")
+	s.Require().ErrorIs(post.RenewRunLease(context.TODO(), 1), platform.ErrRunNotRunning,
+		"shouldn't renew lease of finished run",
+	)
+}
+
+func (s *PostgresTestSuite) TestIntegrationCloseAbandonedRuns() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	lease := time.Hour
+	expired := time.Now().Add(-lease - time.Minute)
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: 1, URL: faker.Word()},
+		pgmodels.Shop{ID: 2, URL: faker.Word()},
+		pgmodels.Shop{ID: 3, URL: faker.Word()},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: 1, Status: string(models.RunStatusRunning), HeartbeatAt: expired},
+		pgmodels.Run{ID: 2, ShopID: 2, Status: string(models.RunStatusRunning), HeartbeatAt: expired},
+		pgmodels.Run{ID: 3, ShopID: 3, Status: string(models.RunStatusRunning), HeartbeatAt: time.Now()},
+	)
+
+	post := storage.NewPostgres(s.DB, storage.WithRunLease(lease))
+
+	closed, err := post.CloseAbandonedRuns(context.TODO(), 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, closed, "should close up to limit runs")
+
+	closed, err = post.CloseAbandonedRuns(context.TODO(), 10)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, closed, "should close remaining abandoned run")
+
+	runs := storagetesting.GetRuns(s.T(), s.DB)
+	for _, run := range runs {
+		if run.ID == 3 {
+			s.Nil(run.FinishedAt, "shouldn't close running run")
+			continue
+		}
+
+		s.NotNil(run.FinishedAt, "should finish abandoned run")
+		s.Equal(string(models.RunStatusFailed), run.Status, "should set failed status")
+	}
+
+	published, err := post.PublishOutboxEvents(context.TODO(), 10, func(context.Context, models.OutboxEvent) error {
+		return nil
+	})
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(2, published, "should save events of closed runs")
+}
+
+func (s *PostgresTestSuite) TestIntegrationRunEvents() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopURL := faker.Word()
+	version := rand.Int63()
+
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: 1, URL: shopURL})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{
+		ID:              1,
+		ShopID:          1,
+		ProductsVersion: version - 1,
+		Status:          string(models.RunStatusRunning),
+		HeartbeatAt:     time.Now().Add(-storage.DefaultRunLease - time.Minute),
+	})
+
+	post := storage.NewPostgres(s.DB)
+
+	run := &models.Run{ProductsVersion: version, IsDryRun: true}
+	s.Require().NoError(post.StartRun(context.TODO(), shopURL, run), "should start run")
+	err := post.StartRun(context.TODO(), shopURL, &models.Run{ProductsVersion: version + 1})
+	s.Require().ErrorIs(err, platform.ErrAlreadyRunning, "should skip run")
+
+	run.FinishedAt = lo.ToPtr(time.Now())
+	run.IsSuccess = lo.ToPtr(true)
+	run.Status = models.RunStatusSucceeded
+	run.CreatedProducts = lo.ToPtr(int32(3))
+	s.Require().NoError(post.FinishRun(context.TODO(), run), "should finish run")
+
+	var published []commander.RunEvent
+	_, err = post.PublishOutboxEvents(context.TODO(), 10, func(_ context.Context, event models.OutboxEvent) error {
+		var runEvent commander.RunEvent
+		s.Require().NoError(json.Unmarshal(event.Payload, &runEvent), "should store valid event payload")
+		published = append(published, runEvent)
+		return nil
+	})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	s.Require().Len(published, 4, "should publish all run events")
+	s.Equal(commander.RunFailed, published[0].Type, "should publish event of abandoned run")
+	s.Equal(1, published[0].Run.ID, "should publish abandoned run")
+	s.Equal(string(models.RunStatusFailed), published[0].Run.Status, "should publish abandoned run's status")
+	s.Equal(commander.RunStarted, published[1].Type, "should publish event of started run")
+	s.Equal(run.ID, published[1].Run.ID, "should publish started run")
+	s.True(published[1].Run.IsDryRun, "should publish started run's mode")
+	s.Equal(commander.RunEvent{Type: commander.RunSkipped, ShopID: 1, ShopURL: shopURL}, published[2],
+		"should publish event of skipped run",
+	)
+	s.Equal(commander.RunFinished, published[3].Type, "should publish event of finished run")
+	s.Equal(shopURL, published[3].ShopURL, "should publish finished run's shop")
+	s.Equal(lo.ToPtr(int32(3)), published[3].Run.CreatedProducts, "should publish finished run's statistics")
+}
+
 func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 	storagetesting.CleanupData(s.T(), s.DB)
 	version := rand.Int63()
@@ -168,6 +361,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 			ShopID:          int32(shopID),
 			CreatedAt:       createdAt,
 			ProductsVersion: version,
+			Status:          string(models.RunStatusRunning),
 		},
 		{
 			ID:              2,
@@ -178,6 +372,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 			UpdatedProducts: lo.ToPtr(rand.Int31()),
 			DeletedProducts: lo.ToPtr(rand.Int31()),
 			Success:         lo.ToPtr(true),
+			Status:          string(models.RunStatusSucceeded),
 		},
 		{
 			ID:              3,
@@ -188,6 +383,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 			UpdatedProducts: lo.ToPtr(rand.Int31()),
 			DeletedProducts: lo.ToPtr(rand.Int31()),
 			Success:         lo.ToPtr(false),
+			Status:          string(models.RunStatusFailed),
 		},
 	}
 
@@ -212,6 +408,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 				CreatedProducts: &createdProducts,
 				UpdatedProducts: &updatedProducts,
 				DeletedProducts: &deletedProducts,
+				Status:          models.RunStatusSucceeded,
 			},
 			storedRuns: runsState[0:1],
 			wantRunsState: []pgmodels.Run{
@@ -225,6 +422,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 					CreatedProducts: &createdProducts,
 					UpdatedProducts: &updatedProducts,
 					DeletedProducts: &deletedProducts,
+					Status:          string(models.RunStatusSucceeded),
 				},
 			},
 		},
@@ -239,6 +437,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 				CreatedProducts: &createdProducts,
 				UpdatedProducts: &updatedProducts,
 				DeletedProducts: &deletedProducts,
+				Status:          models.RunStatusSucceeded,
 			},
 			storedRuns: runsState,
 			wantRunsState: []pgmodels.Run{
@@ -252,6 +451,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 					CreatedProducts: &createdProducts,
 					UpdatedProducts: &updatedProducts,
 					DeletedProducts: &deletedProducts,
+					Status:          string(models.RunStatusSucceeded),
 				},
 				runsState[1],
 				runsState[2],
@@ -268,6 +468,7 @@ func (s *PostgresTestSuite) TestIntegrationFinishRun() {
 				CreatedProducts: &createdProducts,
 				UpdatedProducts: &updatedProducts,
 				DeletedProducts: &deletedProducts,
+				Status:          models.RunStatusSucceeded,
 			},
 			storedRuns: runsState,
 			wantErr:    true,
@@ -301,6 +502,7 @@ func (s *PostgresTestSuite) TestIntegrationUpdateProducts() {
 	createdAt := time.Date(2024, time.April, 1, 1, 1, 1, 0, loc)
 	deletedAt := time.Date(2024, time.April, 1, 2, 1, 1, 0, loc)
 	shopID := int32(1)
+	runID := int32(1)
 
 	setProductData := func(product *models.Product) {
 		product.CreatedAt = createdAt
@@ -337,6 +539,7 @@ func (s *PostgresTestSuite) TestIntegrationUpdateProducts() {
 		wantProducts    []models.Product
 		wantCreated     int32
 		wantUpdated     int32
+		wantUnchanged   int32
 		wantErr         bool
 	}{
 		"ok": {
@@ -389,27 +592,123 @@ func (s *PostgresTestSuite) TestIntegrationUpdateProducts() {
 			wantCreated: 3,
 			wantUpdated: 1,
 		},
+		"skip unchanged": {
+			storedProducts: []pgmodels.Product{
+				{
+					ProductID:   "1",
+					ShopID:      shopID,
+					Version:     version - 10,
+					CreatedAt:   createdAt,
+					ContentHash: lo.ToPtr(storage.ContentHash(&products[0])),
+				},
+				{
+					ProductID:   "4",
+					ShopID:      shopID,
+					Version:     version - 10,
+					CreatedAt:   createdAt,
+					DeletedAt:   &deletedAt,
+					ContentHash: lo.ToPtr(storage.ContentHash(&products[3])),
+				},
+			},
+			wantProducts: []models.Product{
+				{
+					ProductID: "1",
+					Version:   version,
+					CreatedAt: createdAt,
+				},
+				products[1],
+				products[2],
+				products[3],
+				products[4],
+			},
+			wantCreated:   3,
+			wantUpdated:   1,
+			wantUnchanged: 1,
+		},
 	}
 
-	for name, tt := range tests {
-		s.Run(name, func() {
-			defer storagetesting.CleanupData(s.T(), s.DB)
+	loadingModes := map[string][]storage.Option{
+		"insert": nil,
+		"copy":   {storage.WithBulkLoading()},
+	}
 
-			storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: shopID, URL: faker.Word()})
-			storagetesting.InsertProducts(s.T(), s.DB, tt.storedProducts...)
+	for mode, ops := range loadingModes {
+		for name, tt := range tests {
+			s.Run(mode+" "+name, func() {
+				defer storagetesting.CleanupData(s.T(), s.DB)
 
-			post := storage.NewPostgres(s.DB)
+				storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: shopID, URL: faker.Word()})
+				storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: runID, ShopID: shopID, ProductsVersion: version})
+				storagetesting.InsertProducts(s.T(), s.DB, tt.storedProducts...)
 
-			created, updated, err := post.UpdateProducts(context.TODO(), products, int(shopID))
+				post := storage.NewPostgres(s.DB, ops...)
 
-			if tt.wantErr {
-				s.Require().Error(err, "should return error")
-			} else {
-				s.Require().NoError(err, "shouldn't return any error")
-				s.Equal(tt.wantCreated, created, "should return correct number of created products")
-				s.Equal(tt.wantUpdated, updated, "should return correct number of updated products")
-				assertProducts(s.T(), tt.wantProducts, storagetesting.GetProducts(s.T(), s.DB), int64(shopID))
-				assertShippings(s.T(), tt.wantProducts, storagetesting.GetShippings(s.T(), s.DB))
+				created, updated, unchanged, err := post.UpdateProducts(context.TODO(), products, int(shopID), int(runID))
+
+				if tt.wantErr {
+					s.Require().Error(err, "should return error")
+				} else {
+					s.Require().NoError(err, "shouldn't return any error")
+					s.Equal(tt.wantCreated, created, "should return correct number of created products")
+					s.Equal(tt.wantUpdated, updated, "should return correct number of updated products")
+					s.Equal(tt.wantUnchanged, unchanged, "should return correct number of unchanged products")
+					assertProducts(s.T(), tt.wantProducts, storagetesting.GetProducts(s.T(), s.DB), int64(shopID))
+					assertShippings(s.T(), tt.wantProducts, storagetesting.GetShippings(s.T(), s.DB))
+					for _, stored := range storagetesting.GetProducts(s.T(), s.DB) {
+						if stored.ProductID == products[1].ProductID {
+							s.Equal(storage.ContentHash(&products[1]), *stored.ContentHash, "should store product's content hash")
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkIntegrationUpdateProducts compares storing products with multi-row inserts and with COPY.
+// Every iteration updates all products of a batch with new content.
+func BenchmarkIntegrationUpdateProducts(b *testing.B) {
+	db := storagetesting.Open(b)
+	defer db.Close()
+
+	shopID := int32(1)
+	runID := int32(1)
+	products := lo.Times(1000, func(ix int) models.Product {
+		return modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = strconv.Itoa(ix)
+			p.DeletedAt = nil
+		})
+	})
+
+	benchmarks := []struct {
+		name string
+		ops  []storage.Option
+	}{
+		{name: "insert"},
+		{name: "copy", ops: []storage.Option{storage.WithBulkLoading()}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			storagetesting.CleanupData(b, db)
+			defer storagetesting.CleanupData(b, db)
+			storagetesting.InsertShops(b, db, pgmodels.Shop{ID: shopID, URL: faker.Word()})
+			storagetesting.InsertRuns(b, db, pgmodels.Run{ID: runID, ShopID: shopID})
+
+			post := storage.NewPostgres(db, bm.ops...)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for ix := range products {
+					products[ix].Version = int64(i + 1)
+					products[ix].Title = fmt.Sprintf("title %d", i)
+				}
+				b.StartTimer()
+
+				if _, _, _, err := post.UpdateProducts(context.TODO(), products, int(shopID), int(runID)); err != nil {
+					b.Fatal("can't update products", err)
+				}
 			}
 		})
 	}
@@ -491,11 +790,12 @@ func (s *PostgresTestSuite) TestIntegrationDeleteOldProducts() {
 	}
 
 	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: shopID, URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: 1, ShopID: shopID, ProductsVersion: version})
 	storagetesting.InsertProducts(s.T(), s.DB, storageState...)
 
 	post := storage.NewPostgres(s.DB)
 
-	deleted, err := post.DeleteOldProducts(context.TODO(), int(shopID), version, 1)
+	deleted, err := post.DeleteOldProducts(context.TODO(), int(shopID), 1, version, 1)
 
 	s.Require().NoError(err, "shouldn't return any error")
 	s.Equal(int32(2), deleted, "should return correct number of deleted products")
@@ -508,97 +808,1191 @@ func (s *PostgresTestSuite) TestIntegrationDeleteOldProducts() {
 	assertProducts(s.T(), wantState, state, int64(shopID))
 }
 
-// assertProducts is a helper test function to assert products slice.
-func assertProducts(t *testing.T, expected []models.Product, actual []pgmodels.Product, shopID int64) {
-	t.Helper()
+func (s *PostgresTestSuite) TestIntegrationDeleteProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
 
-	require.Len(t, actual, len(expected), "products slice should have correct length")
+	version := rand.Int63()
+	createdAt := time.Date(2024, time.April, 1, 1, 1, 1, 0, loc)
+	deletedAt := time.Date(2024, time.April, 1, 2, 1, 1, 0, loc)
+	shopID := int32(1)
 
-	exp := make([]pgmodels.Product, 0, len(expected))
-	for ix := range expected {
-		exp = append(exp, *storage.ToDBProduct(&expected[ix], shopID, nil))
+	storageState := []pgmodels.Product{
+		{
+			ProductID: "1",
+			ShopID:    shopID,
+			Version:   version,
+			CreatedAt: createdAt,
+		},
+		{
+			ProductID: "2",
+			ShopID:    shopID,
+			Version:   version,
+			CreatedAt: createdAt,
+			DeletedAt: &deletedAt,
+		},
+		{
+			ProductID: "3",
+			ShopID:    shopID,
+			Version:   version,
+			CreatedAt: createdAt,
+		},
 	}
 
-	slices.SortFunc(exp, func(a, b pgmodels.Product) int { return strings.Compare(a.ProductID, b.ProductID) })
-	slices.SortFunc(
-		actual,
-		func(a, b pgmodels.Product) int {
-			return strings.Compare(a.ProductID, b.ProductID)
+	wantState := []models.Product{
+		{
+			ProductID: "1",
+			Version:   version,
+			CreatedAt: createdAt,
+			DeletedAt: &deletedAt,
+		},
+		{
+			ProductID: "2",
+			Version:   version,
+			CreatedAt: createdAt,
+			DeletedAt: &deletedAt,
+		},
+		{
+			ProductID: "3",
+			Version:   version,
+			CreatedAt: createdAt,
 		},
-	)
-	lo.ForEach(actual, func(_ pgmodels.Product, ix int) {
-		actual[ix].ID = 0
-		actual[ix].CreatedAt = time.Time{}
-		exp[ix].CreatedAt = time.Time{}
-	})
-
-	for ix := range actual {
-		assert.EqualValues(t, exp[ix], actual[ix], "product at index %d has incorrect values", ix)
 	}
-}
 
-// assertRuns is a helper test function to assert runs slice.
-func assertRuns(t *testing.T, expected, actual []pgmodels.Run) {
-	t.Helper()
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: shopID, URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: 1, ShopID: shopID, ProductsVersion: version})
+	storagetesting.InsertProducts(s.T(), s.DB, storageState...)
 
-	require.Len(t, actual, len(expected), "should have correct length")
+	post := storage.NewPostgres(s.DB)
 
-	slices.SortFunc(expected, func(a, b pgmodels.Run) int { return int(b.ID - a.ID) })
-	slices.SortFunc(actual, func(a, b pgmodels.Run) int { return int(b.ID - a.ID) })
+	deleted, err := post.DeleteProducts(context.TODO(), int(shopID), 1, []string{"1", "2", "4"})
 
-	for ix := range expected {
-		assert.Equalf(t, expected[ix], actual[ix], "run  at index %d has incorrect values", ix)
-	}
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(int32(1), deleted, "should return correct number of deleted products")
+	state := storagetesting.GetProducts(s.T(), s.DB)
+	lo.ForEach(state, func(_ pgmodels.Product, ix int) {
+		if state[ix].DeletedAt != nil {
+			state[ix].DeletedAt = &deletedAt
+		}
+	})
+	assertProducts(s.T(), wantState, state, int64(shopID))
 }
 
-// assertRun is a helper test function to assert run.
-func assertRun(t *testing.T, expected, actual *models.Run) {
-	t.Helper()
+func (s *PostgresTestSuite) TestIntegrationCountProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
 
-	if expected == nil {
-		require.Nil(t, actual, "run should be nil")
-		return
-	}
+	version := rand.Int63()
+	createdAt := time.Date(2024, time.April, 1, 1, 1, 1, 0, loc)
+	deletedAt := time.Date(2024, time.April, 1, 2, 1, 1, 0, loc)
+	shopID := int32(1)
+	otherShopID := int32(2)
 
-	require.NotNil(t, actual, "run should not be nil")
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: shopID, URL: faker.Word()},
+		pgmodels.Shop{ID: otherShopID, URL: faker.Word()},
+	)
+	storagetesting.InsertProducts(s.T(), s.DB,
+		pgmodels.Product{ProductID: "1", ShopID: shopID, Version: version, CreatedAt: createdAt},
+		pgmodels.Product{ProductID: "2", ShopID: shopID, Version: version - 1, CreatedAt: createdAt},
+		pgmodels.Product{ProductID: "3", ShopID: shopID, Version: version - 1, CreatedAt: createdAt},
+		pgmodels.Product{ProductID: "4", ShopID: shopID, Version: version - 1, CreatedAt: createdAt, DeletedAt: &deletedAt},
+		pgmodels.Product{ProductID: "5", ShopID: otherShopID, Version: version - 1, CreatedAt: createdAt},
+	)
 
-	require.NotZero(t, actual.ShopID, "run should have new shop id")
-	require.NotZero(t, actual.ID, "run should have id")
-	require.NotZero(t, actual.CreatedAt.UnixMilli(), "run should have \"created at\" set")
+	post := storage.NewPostgres(s.DB)
 
-	actual.CreatedAt = time.Time{}
-	actual.ID = 0
-	if expected.ShopID == 0 {
-		actual.ShopID = 0
-	}
+	active, outdated, err := post.CountProducts(context.TODO(), int(shopID), version)
 
-	assert.Equal(t, *expected, *actual, "run has incorrect values")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(int32(3), active, "should return correct number of active products")
+	s.Equal(int32(2), outdated, "should return correct number of outdated products")
 }
 
-// assertShippings is a helper test function to assert shippings slice.
-func assertShippings(t *testing.T, expected []models.Product, actual []pgmodels.Shipping) {
-	t.Helper()
-
-	exp := []pgmodels.Shipping{}
-	for ix := range expected {
-		exp = append(exp, storage.ToDBShippings(0, expected[ix].Shippings)...)
-	}
+func (s *PostgresTestSuite) TestIntegrationGetProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
 
-	require.Len(t, actual, len(exp), "shippings slice should have correct length")
+	shopID := 1
+	otherShopID := 2
 
-	slices.SortFunc(exp, func(a, b pgmodels.Shipping) int { return strings.Compare(a.Country, b.Country) })
-	slices.SortFunc(
-		actual,
-		func(a, b pgmodels.Shipping) int {
-			return strings.Compare(a.Country, b.Country)
-		},
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: int32(shopID), URL: faker.Word()},
+		pgmodels.Shop{ID: int32(otherShopID), URL: faker.Word()},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID)},
+		pgmodels.Run{ID: 2, ShopID: int32(otherShopID)},
 	)
-	lo.ForEach(actual, func(_ pgmodels.Shipping, ix int) {
-		actual[ix].ID = 0
-		actual[ix].ProductID = 0
-	})
 
-	for ix := range actual {
-		assert.EqualValues(t, exp[ix], actual[ix], "shipping at index %d has incorrect values", ix)
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "1"
+			p.AdditionalImageURLs = []string{faker.Word(), faker.Word()}
+			p.Shippings = []models.Shipping{modelstesting.FakeShipping(), modelstesting.FakeShipping()}
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "2"
+			p.AdditionalImageURLs = nil
+			p.Shippings = nil
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3" }),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{modelstesting.FakeProduct(func(p *models.Product) {
+		p.ProductID = "1"
+	})}, otherShopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	stored, err := post.GetProducts(context.TODO(), shopID, []string{"1", "2", "4"})
+
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(stored, 2, "should return only shop's products with provided IDs")
+	for ix := range stored {
+		s.NotZero(stored[ix].ID, "should return product's ID")
+		s.NotZero(stored[ix].CreatedAt, "should return product's creation time")
+		stored[ix].ID = 0
+		stored[ix].CreatedAt = time.Time{}
 	}
+	s.Equal(products[:2], stored, "should return correct products")
+
+	stored, err = post.GetProducts(context.TODO(), shopID, nil)
+
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Empty(stored, "shouldn't return any product")
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetUnchangedProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	version := rand.Int63n(1000)
+
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: 1, ShopID: int32(shopID)})
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3"; p.Version = version }),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 1, []string{"3"})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	parsed := append([]models.Product{}, products...)
+	parsed = append(parsed, modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "4" }))
+	for ix := range parsed {
+		parsed[ix].Version = version + 1
+	}
+	parsed[1].Title += " changed"
+
+	unchanged, err := post.GetUnchangedProducts(context.TODO(), shopID, parsed)
+
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]string{"1"}, unchanged, "should return only not-deleted stored products with the same content")
+}
+
+func (s *PostgresTestSuite) TestIntegrationProductEvents() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	version := rand.Int63n(1000)
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: version},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), ProductsVersion: version + 1},
+	)
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3"; p.Version = version }),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	// second run changes product 1, product 2 is unchanged and product 3 is deleted.
+	lo.ForEach(products, func(_ models.Product, ix int) { products[ix].Version = version + 1 })
+	products[0].Title += " changed"
+	products[0].Price += " changed"
+	_, _, _, err = post.UpdateProducts(context.TODO(), products[:2], shopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteOldProducts(context.TODO(), shopID, 2, version+1, 10)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	var published []commander.ProductEvent
+	count, err := post.PublishOutboxEvents(context.TODO(), 10, func(_ context.Context, event models.OutboxEvent) error {
+		var productEvent commander.ProductEvent
+		s.Require().NoError(json.Unmarshal(event.Payload, &productEvent), "should store valid event payload")
+		s.Equal(productEvent.Type, event.Type, "should store event type")
+		published = append(published, productEvent)
+		return nil
+	})
+
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(5, count, "should return number of published events")
+	s.Equal([]commander.ProductEvent{
+		{Type: commander.ProductCreated, ShopID: shopID, ProductID: "1", Version: version},
+		{Type: commander.ProductCreated, ShopID: shopID, ProductID: "2", Version: version},
+		{Type: commander.ProductCreated, ShopID: shopID, ProductID: "3", Version: version},
+		{
+			Type:          commander.ProductUpdated,
+			ShopID:        shopID,
+			ProductID:     "1",
+			Version:       version + 1,
+			ChangedFields: []string{"title", "price"},
+		},
+		{Type: commander.ProductDeleted, ShopID: shopID, ProductID: "3", Version: version},
+	}, published, "should publish events in correct order")
+
+	count, err = post.PublishOutboxEvents(context.TODO(), 10, func(context.Context, models.OutboxEvent) error {
+		return assert.AnError
+	})
+
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Zero(count, "shouldn't publish events again")
+}
+
+func (s *PostgresTestSuite) TestIntegrationProductHistory() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	version := rand.Int63n(1000)
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: version},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), ProductsVersion: version + 1},
+	)
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3"; p.Version = version }),
+	}
+	oldTitle, oldPrice := products[0].Title, products[0].Price
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	// second run changes product 1, product 2 is unchanged and product 3 is deleted.
+	lo.ForEach(products, func(_ models.Product, ix int) { products[ix].Version = version + 1 })
+	products[0].Title += " changed"
+	products[0].Price += " changed"
+	_, _, _, err = post.UpdateProducts(context.TODO(), products[:2], shopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteOldProducts(context.TODO(), shopID, 2, version+1, 10)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	history, err := post.GetProductHistory(context.TODO(), shopID, "1")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(history, 1, "should return update of changed product")
+	s.Equal(lo.ToPtr(2), history[0].RunID, "should return ID of updating run")
+	s.Equal(version+1, history[0].Version, "should return version of updated product")
+	s.Equal(models.ChangeUpdated, history[0].Type, "should return type of the change")
+	s.Equal([]models.FieldChange{
+		{Field: "title", OldValue: oldTitle, NewValue: products[0].Title},
+		{Field: "price", OldValue: oldPrice, NewValue: products[0].Price},
+	}, history[0].Changes, "should return changed fields with old and new values")
+
+	history, err = post.GetProductHistory(context.TODO(), shopID, "2")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Empty(history, "shouldn't return any change of unchanged product")
+
+	history, err = post.GetProductHistory(context.TODO(), shopID, "3")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(history, 1, "should return deletion of deleted product")
+	s.Equal(lo.ToPtr(2), history[0].RunID, "should return ID of deleting run")
+	s.Equal(version, history[0].Version, "should return last version of deleted product")
+	s.Equal(models.ChangeDeleted, history[0].Type, "should return type of the change")
+	s.Require().Len(history[0].Changes, 1, "should return deletion time change")
+	s.Equal("deleted_at", history[0].Changes[0].Field, "should return deletion time change")
+	s.Nil(history[0].Changes[0].OldValue, "shouldn't return deletion time before deletion")
+	s.NotNil(history[0].Changes[0].NewValue, "should return deletion time")
+}
+
+func (s *PostgresTestSuite) TestIntegrationPriceSeries() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	version := rand.Int63n(1000)
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: version},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), ProductsVersion: version + 1},
+	)
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "1"
+			p.Version = version
+			p.Price = "15.00 USD"
+			p.SalePrice = nil
+			p.Availability = "in_stock"
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "2"
+			p.Version = version
+			p.Price = "20.00 USD"
+			p.SalePrice = lo.ToPtr("18.00 USD")
+			p.Availability = "in_stock"
+		}),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	// second run changes price and availability of product 1 and only title of product 2.
+	lo.ForEach(products, func(_ models.Product, ix int) { products[ix].Version = version + 1 })
+	products[0].Price = "12.50 USD"
+	products[0].Availability = "out_of_stock"
+	products[1].Title += " changed"
+	_, _, _, err = post.UpdateProducts(context.TODO(), products, shopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	series, err := post.GetPriceSeries(context.TODO(), shopID, "1")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(series, 2, "should return points of created and changed product")
+	s.Equal(lo.ToPtr(1), series[0].RunID, "should return ID of creating run")
+	s.Equal(lo.ToPtr(15.0), series[0].Price, "should return parsed price")
+	s.Equal(lo.ToPtr("USD"), series[0].Currency, "should return parsed currency")
+	s.Nil(series[0].SalePrice, "shouldn't return missing sale price")
+	s.Equal("in_stock", series[0].Availability, "should return availability")
+	s.Equal(lo.ToPtr(2), series[1].RunID, "should return ID of updating run")
+	s.Equal(lo.ToPtr(12.5), series[1].Price, "should return changed price")
+	s.Equal("out_of_stock", series[1].Availability, "should return changed availability")
+
+	series, err = post.GetPriceSeries(context.TODO(), shopID, "2")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(series, 1, "shouldn't return point of product with unchanged price")
+	s.Equal(lo.ToPtr(18.0), series[0].SalePrice, "should return parsed sale price")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	stats, err := post.GetDailyPriceStats(context.TODO(), shopID, today, today.Add(24*time.Hour))
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(stats, 1, "should return stats of today")
+	s.True(today.Equal(stats[0].Day), "should return UTC day")
+	s.Equal(models.DailyPriceStats{
+		Day:             stats[0].Day,
+		ChangedProducts: 2,
+		PriceDrops:      1,
+		PriceRises:      0,
+		StockOuts:       1,
+	}, stats[0], "should return aggregated changes")
+}
+
+func (s *PostgresTestSuite) TestIntegrationShopLifecycle() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	post := storage.NewPostgres(s.DB)
+
+	shop := &models.Shop{Name: faker.Word(), URL: faker.URL()}
+	s.Require().NoError(post.CreateShop(context.TODO(), shop), "shouldn't return any error")
+	s.NotZero(shop.ID, "should set shop's ID")
+	s.NotZero(shop.CreatedAt, "should set shop's creation time")
+
+	err := post.CreateShop(context.TODO(), &models.Shop{URL: shop.URL})
+	s.ErrorIs(err, platform.ErrShopURLTaken, "shouldn't create shop with used url")
+
+	other := &models.Shop{URL: faker.URL()}
+	s.Require().NoError(post.CreateShop(context.TODO(), other), "shouldn't return any error")
+
+	// products are kept when url changes.
+	version := rand.Int63n(1000)
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: 1, ShopID: int32(shop.ID), ProductsVersion: version})
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+	}, shop.ID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	oldURL := shop.URL
+	shop.Name = faker.Word()
+	shop.URL = faker.URL()
+	shop.Disabled = true
+	s.Require().NoError(post.UpdateShop(context.TODO(), *shop), "shouldn't return any error")
+
+	stored, err := post.GetShop(context.TODO(), shop.ID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(shop.Name, stored.Name, "should update name")
+	s.Equal(shop.URL, stored.URL, "should update url")
+	s.True(stored.Disabled, "should disable shop")
+
+	history, err := post.GetShopURLHistory(context.TODO(), shop.ID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(history, 1, "should save previous url")
+	s.Equal(oldURL, history[0].URL, "should save previous url")
+	s.ErrorIs(post.StartRun(context.TODO(), oldURL, &models.Run{ProductsVersion: version}), platform.ErrShopURLChanged,
+		"should reject run of previous url")
+	s.Zero(storagetesting.GetShopID(s.T(), s.DB, oldURL), "shouldn't add shop for previous url")
+
+	s.Len(storagetesting.GetProductsByShopID(s.T(), s.DB, shop.ID), 1, "should keep shop's products")
+
+	s.ErrorIs(post.UpdateShop(context.TODO(), models.Shop{ID: shop.ID, URL: other.URL}), platform.ErrShopURLTaken,
+		"shouldn't update url to url of other shop")
+	s.ErrorIs(post.StartRun(context.TODO(), shop.URL, &models.Run{ProductsVersion: version}), platform.ErrShopDisabled,
+		"should reject run of disabled shop")
+
+	s.Require().NoError(post.DeleteShop(context.TODO(), shop.ID), "shouldn't return any error")
+	s.Require().NoError(post.DeleteShop(context.TODO(), shop.ID), "shouldn't return error for deleted shop")
+
+	stored, err = post.GetShop(context.TODO(), shop.ID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.NotNil(stored.DeletedAt, "should soft-delete shop")
+
+	s.ErrorIs(post.UpdateShop(context.TODO(), *shop), platform.ErrShopDeleted, "shouldn't update deleted shop")
+	s.ErrorIs(post.DeleteShop(context.TODO(), 0), platform.ErrShopNotFound, "should return error for missing shop")
+
+	// previous url of deleted shop can be used by new shop.
+	run := &models.Run{ProductsVersion: version}
+	s.Require().NoError(post.StartRun(context.TODO(), oldURL, run), "shouldn't reject previous url of deleted shop")
+	s.NotEqual(shop.ID, run.ShopID, "should add new shop for previous url of deleted shop")
+
+	_, err = post.GetShop(context.TODO(), 0)
+	s.ErrorIs(err, platform.ErrShopNotFound, "should return error for missing shop")
+}
+
+func (s *PostgresTestSuite) TestIntegrationShopConfig() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+
+	post := storage.NewPostgres(s.DB)
+
+	config, err := post.GetShopConfig(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(models.ShopConfig{}, config, "should return empty config if nothing is set")
+
+	want := models.ShopConfig{
+		BatchSize:             lo.ToPtr(uint(500)),
+		StorageWorkers:        lo.ToPtr(4),
+		ParseTimeout:          lo.ToPtr(30 * time.Minute),
+		Decoder:               lo.ToPtr("xml"),
+		RemovalAvailabilities: []string{"removed", "discontinued"},
+		MaxDeletionPercent:    lo.ToPtr(20.0),
+		MaxFailedProducts:     lo.ToPtr(100),
+		MaxFailureRatio:       lo.ToPtr(0.1),
+	}
+	s.Require().NoError(post.SetShopConfig(context.TODO(), shopID, want), "shouldn't return any error")
+
+	config, err = post.GetShopConfig(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(want, config, "should return saved config")
+
+	want = models.ShopConfig{BatchSize: lo.ToPtr(uint(10)), RemovalAvailabilities: []string{}}
+	s.Require().NoError(post.SetShopConfig(context.TODO(), shopID, want), "shouldn't return any error")
+
+	config, err = post.GetShopConfig(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(want, config, "should replace saved config")
+
+	err = post.SetShopConfig(context.TODO(), shopID+1, want)
+	s.ErrorIs(err, platform.ErrShopNotFound, "should return error for not existing shop")
+}
+
+func (s *PostgresTestSuite) TestIntegrationRetentionPolicies() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: 1, URL: faker.Word()},
+		pgmodels.Shop{ID: 2, URL: faker.Word()},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	policy := models.RetentionPolicy{
+		ShopID:          1,
+		DeletedProducts: lo.ToPtr(30 * 24 * time.Hour),
+		Runs:            lo.ToPtr(7 * 24 * time.Hour),
+		KeptRuns:        lo.ToPtr(5),
+	}
+	s.Require().NoError(post.SetRetentionPolicy(context.TODO(), policy), "shouldn't return any error")
+
+	policies, err := post.GetRetentionPolicies(context.TODO())
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]models.RetentionPolicy{policy, {ShopID: 2}}, policies, "should return policies of all shops")
+
+	err = post.SetRetentionPolicy(context.TODO(), models.RetentionPolicy{ShopID: 3})
+	s.ErrorIs(err, platform.ErrShopNotFound, "should return error for not existing shop")
+
+	err = post.SetRetentionPolicy(context.TODO(), models.RetentionPolicy{ShopID: 1, Runs: lo.ToPtr(12 * time.Hour)})
+	s.ErrorIs(err, platform.ErrInvalidRetention, "should return error for retention shorter than a day")
+
+	policies, err = post.GetRetentionPolicies(context.TODO())
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(policy, policies[0], "shouldn't change policy with invalid retention")
+}
+
+func (s *PostgresTestSuite) TestIntegrationPurgeDeletedProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	version := rand.Int63n(1000)
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB, pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: version})
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "2"; p.Version = version }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3"; p.Version = version }),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 1, []string{"1", "2"})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	purged, err := post.PurgeDeletedProducts(context.TODO(), shopID, time.Now().Add(-time.Hour), 10)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Zero(purged, "shouldn't purge products deleted after provided time")
+
+	purged, err = post.PurgeDeletedProducts(context.TODO(), shopID, time.Now().Add(time.Hour), 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, purged, "should purge up to limit products")
+
+	purged, err = post.PurgeDeletedProducts(context.TODO(), shopID, time.Now().Add(time.Hour), 10)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, purged, "should purge remaining deleted products")
+
+	stored := storagetesting.GetProducts(s.T(), s.DB)
+	s.Require().Len(stored, 1, "should keep not deleted product")
+	s.Equal("3", stored[0].ProductID, "should keep not deleted product")
+
+	shippings := storagetesting.GetShippings(s.T(), s.DB)
+	s.Len(shippings, len(products[2].Shippings), "should delete shippings of purged products")
+	for _, shipping := range shippings {
+		s.Equal(stored[0].ID, shipping.ProductID, "should keep shippings of not deleted product")
+	}
+
+	history, err := post.GetProductHistory(context.TODO(), shopID, "1")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Empty(history, "should delete history of purged products")
+}
+
+func (s *PostgresTestSuite) TestIntegrationPruneRuns() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	otherShopID := 2
+	version := rand.Int63n(1000)
+	createdAt := time.Now().Add(-10 * 24 * time.Hour)
+	finishedAt := lo.ToPtr(createdAt.Add(time.Minute))
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: int32(shopID), URL: faker.Word()},
+		pgmodels.Shop{ID: int32(otherShopID), URL: faker.Word()},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), CreatedAt: createdAt, FinishedAt: finishedAt},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), CreatedAt: createdAt, FinishedAt: finishedAt},
+		pgmodels.Run{ID: 3, ShopID: int32(otherShopID), CreatedAt: createdAt, FinishedAt: finishedAt},
+		pgmodels.Run{ID: 4, ShopID: int32(shopID), CreatedAt: createdAt, FinishedAt: finishedAt},
+		pgmodels.Run{ID: 5, ShopID: int32(shopID), CreatedAt: createdAt, FinishedAt: finishedAt},
+		pgmodels.Run{ID: 6, ShopID: int32(shopID), CreatedAt: createdAt},
+		pgmodels.Run{ID: 7, ShopID: int32(shopID), CreatedAt: time.Now(), FinishedAt: lo.ToPtr(time.Now())},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	// history of product references pruned run.
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "1"; p.Version = version }),
+	}
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 1, []string{"1"})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	pruned, err := post.PruneRuns(context.TODO(), shopID, time.Now().Add(-24*time.Hour), 3, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(2, pruned, "should prune up to limit runs")
+
+	pruned, err = post.PruneRuns(context.TODO(), shopID, time.Now().Add(-24*time.Hour), 3, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, pruned, "should prune remaining old runs")
+
+	runIDs := lo.Map(storagetesting.GetRuns(s.T(), s.DB), func(run pgmodels.Run, _ int) int32 { return run.ID })
+	s.ElementsMatch([]int32{3, 5, 6, 7}, runIDs,
+		"should keep runs of other shops, kept newest runs, running runs and new runs")
+
+	history, err := post.GetProductHistory(context.TODO(), shopID, "1")
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(history, 1, "should keep history of pruned run")
+	s.Nil(history[0].RunID, "should unset ID of pruned run")
+}
+
+func (s *PostgresTestSuite) TestIntegrationSupplementalFeeds() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	otherShopID := 2
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: int32(shopID), URL: faker.Word()},
+		pgmodels.Shop{ID: int32(otherShopID), URL: faker.Word()},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	feeds, err := post.GetSupplementalFeeds(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Empty(feeds, "shouldn't return any feed")
+
+	s.Require().NoError(post.SetSupplementalFeeds(context.TODO(), shopID, []string{"c", "a", "b"}))
+	s.Require().NoError(post.SetSupplementalFeeds(context.TODO(), otherShopID, []string{"d"}))
+
+	feeds, err = post.GetSupplementalFeeds(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]string{"c", "a", "b"}, feeds, "should return feeds in correct order")
+
+	s.Require().NoError(post.SetSupplementalFeeds(context.TODO(), shopID, []string{"b", "e"}))
+
+	feeds, err = post.GetSupplementalFeeds(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]string{"b", "e"}, feeds, "should replace feeds")
+
+	s.Require().NoError(post.SetSupplementalFeeds(context.TODO(), shopID, []string{}))
+
+	feeds, err = post.GetSupplementalFeeds(context.TODO(), shopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Empty(feeds, "should remove all feeds")
+
+	feeds, err = post.GetSupplementalFeeds(context.TODO(), otherShopID)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]string{"d"}, feeds, "shouldn't change other shop feeds")
+}
+
+// assertProducts is a helper test function to assert products slice.
+func assertProducts(t *testing.T, expected []models.Product, actual []pgmodels.Product, shopID int64) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected), "products slice should have correct length")
+
+	exp := make([]pgmodels.Product, 0, len(expected))
+	for ix := range expected {
+		exp = append(exp, *storage.ToDBProduct(&expected[ix], shopID, nil))
+	}
+
+	slices.SortFunc(exp, func(a, b pgmodels.Product) int { return strings.Compare(a.ProductID, b.ProductID) })
+	slices.SortFunc(
+		actual,
+		func(a, b pgmodels.Product) int {
+			return strings.Compare(a.ProductID, b.ProductID)
+		},
+	)
+	lo.ForEach(actual, func(_ pgmodels.Product, ix int) {
+		actual[ix].ID = 0
+		actual[ix].CreatedAt = time.Time{}
+		actual[ix].ContentHash = nil
+		exp[ix].CreatedAt = time.Time{}
+		exp[ix].ContentHash = nil
+	})
+
+	for ix := range actual {
+		assert.EqualValues(t, exp[ix], actual[ix], "product at index %d has incorrect values", ix)
+	}
+}
+
+// assertRuns is a helper test function to assert runs slice.
+func assertRuns(t *testing.T, expected, actual []pgmodels.Run) {
+	t.Helper()
+
+	require.Len(t, actual, len(expected), "should have correct length")
+
+	slices.SortFunc(expected, func(a, b pgmodels.Run) int { return int(b.ID - a.ID) })
+	slices.SortFunc(actual, func(a, b pgmodels.Run) int { return int(b.ID - a.ID) })
+
+	for ix := range expected {
+		assert.Equalf(t, expected[ix], actual[ix], "run  at index %d has incorrect values", ix)
+	}
+}
+
+// assertRun is a helper test function to assert run.
+func assertRun(t *testing.T, expected, actual *models.Run) {
+	t.Helper()
+
+	if expected == nil {
+		require.Nil(t, actual, "run should be nil")
+		return
+	}
+
+	require.NotNil(t, actual, "run should not be nil")
+
+	require.NotZero(t, actual.ShopID, "run should have new shop id")
+	require.NotZero(t, actual.ID, "run should have id")
+	require.NotZero(t, actual.CreatedAt.UnixMilli(), "run should have \"created at\" set")
+
+	actual.CreatedAt = time.Time{}
+	actual.ID = 0
+	if expected.ShopID == 0 {
+		actual.ShopID = 0
+	}
+
+	assert.Equal(t, *expected, *actual, "run has incorrect values")
+}
+
+// assertShippings is a helper test function to assert shippings slice.
+func assertShippings(t *testing.T, expected []models.Product, actual []pgmodels.Shipping) {
+	t.Helper()
+
+	exp := []pgmodels.Shipping{}
+	for ix := range expected {
+		exp = append(exp, storage.ToDBShippings(0, expected[ix].Shippings)...)
+	}
+
+	require.Len(t, actual, len(exp), "shippings slice should have correct length")
+
+	slices.SortFunc(exp, func(a, b pgmodels.Shipping) int { return strings.Compare(a.Country, b.Country) })
+	slices.SortFunc(
+		actual,
+		func(a, b pgmodels.Shipping) int {
+			return strings.Compare(a.Country, b.Country)
+		},
+	)
+	lo.ForEach(actual, func(_ pgmodels.Shipping, ix int) {
+		actual[ix].ID = 0
+		actual[ix].ProductID = 0
+	})
+
+	for ix := range actual {
+		assert.EqualValues(t, exp[ix], actual[ix], "shipping at index %d has incorrect values", ix)
+	}
+}
+
+func (s *PostgresTestSuite) TestIntegrationShopSchedules() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	now := time.Now().Truncate(time.Second)
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: 1, URL: "shop1"},
+		pgmodels.Shop{ID: 2, URL: "shop2"},
+		pgmodels.Shop{ID: 3, URL: "shop3", Disabled: true},
+		pgmodels.Shop{ID: 4, URL: "shop4"},
+		pgmodels.Shop{ID: 5, URL: "shop5"},
+		pgmodels.Shop{ID: 6, URL: "shop6"},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: 1, CreatedAt: now, HeartbeatAt: now},
+		// abandoned run
+		pgmodels.Run{ID: 2, ShopID: 2, CreatedAt: now.Add(-time.Hour), HeartbeatAt: now.Add(-time.Hour)},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	for shopID := 1; shopID <= 6; shopID++ {
+		err := post.SetShopSchedule(context.TODO(), models.ShopSchedule{ShopID: shopID, Interval: lo.ToPtr(time.Hour)})
+		s.Require().NoError(err, "shouldn't return any error")
+	}
+
+	err := post.SetShopSchedule(context.TODO(), models.ShopSchedule{ShopID: 7, Interval: lo.ToPtr(time.Hour)})
+	s.ErrorIs(err, platform.ErrShopNotFound, "should return error for not existing shop")
+
+	nextRunAts := map[int32]time.Time{
+		1: now.Add(-3 * time.Minute),
+		2: now.Add(-2 * time.Minute),
+		3: now.Add(-2 * time.Minute),
+		4: now.Add(time.Minute),
+		6: now.Add(-time.Minute),
+	}
+	for shopID, nextRunAt := range nextRunAts {
+		_, err = table.ShopSchedule.UPDATE(table.ShopSchedule.NextRunAt).
+			SET(pg.TimestampzT(nextRunAt)).
+			WHERE(table.ShopSchedule.ShopID.EQ(pg.Int32(shopID))).
+			Exec(s.DB)
+		s.Require().NoError(err, "shouldn't return any error")
+	}
+
+	// shop's schedule locked by other scheduler
+	tx, err := s.DB.Begin()
+	s.Require().NoError(err, "shouldn't return any error")
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = table.ShopSchedule.SELECT(table.ShopSchedule.ShopID).
+		WHERE(table.ShopSchedule.ShopID.EQ(pg.Int32(6))).
+		FOR(pg.UPDATE()).
+		Exec(tx)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	var processed []models.ShopSchedule
+	process := func(_ context.Context, schedule models.ShopSchedule) (time.Time, error) {
+		processed = append(processed, schedule)
+		return now.Add(time.Hour), nil
+	}
+
+	count, err := post.ProcessDueSchedules(context.TODO(), now, 10, process)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(3, count, "should return number of processed schedules")
+	s.Require().Len(processed, 3, "should process due schedules of enabled shops")
+	s.Equal([]int{5, 1, 2}, lo.Map(processed, func(schedule models.ShopSchedule, _ int) int {
+		return schedule.ShopID
+	}), "should process schedules without next run time first")
+	s.Nil(processed[0].NextRunAt, "should return schedule without next run time")
+	s.Equal("shop1", processed[1].ShopURL, "should return shop's url")
+	s.True(processed[1].Running, "should mark shop with running run")
+	s.False(processed[2].Running, "shouldn't mark shop with abandoned run")
+	s.Equal(lo.ToPtr(time.Hour), processed[2].Interval, "should return schedule's interval")
+
+	s.Require().NoError(tx.Rollback(), "shouldn't return any error")
+
+	processed = nil
+	count, err = post.ProcessDueSchedules(context.TODO(), now, 10, process)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(1, count, "should process schedule which is no longer locked")
+	s.Equal(6, processed[0].ShopID, "should process schedule which is no longer locked")
+
+	schedule, err := post.GetShopSchedule(context.TODO(), 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().NotNil(schedule.NextRunAt, "should save next run time")
+	s.True(now.Add(time.Hour).Equal(*schedule.NextRunAt), "should save next run time")
+
+	_, err = post.ProcessDueSchedules(context.TODO(), now, 10,
+		func(_ context.Context, _ models.ShopSchedule) (time.Time, error) {
+			return time.Time{}, assert.AnError
+		})
+	s.NoError(err, "shouldn't process anything when nothing is due")
+
+	err = post.SetShopSchedule(context.TODO(), models.ShopSchedule{ShopID: 1, Cron: lo.ToPtr("0 * * * *")})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	schedule, err = post.GetShopSchedule(context.TODO(), 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(&models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Cron: lo.ToPtr("0 * * * *")}, schedule,
+		"should replace schedule and reset its next run time")
+
+	count, err = post.ProcessDueSchedules(context.TODO(), now, 10,
+		func(_ context.Context, _ models.ShopSchedule) (time.Time, error) {
+			return time.Time{}, assert.AnError
+		})
+	s.ErrorIs(err, assert.AnError, "should return process error")
+	s.Zero(count, "shouldn't count failed schedule")
+
+	s.Require().NoError(post.DeleteShopSchedule(context.TODO(), 1), "shouldn't return any error")
+
+	_, err = post.GetShopSchedule(context.TODO(), 1)
+	s.ErrorIs(err, platform.ErrShopScheduleNotFound, "should return error for deleted schedule")
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetShops() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: 2, URL: "shop2", Name: "Shop 2", Disabled: true},
+		pgmodels.Shop{ID: 1, URL: "shop1", Name: "Shop 1"},
+		pgmodels.Shop{ID: 3, URL: "shop3", DeletedAt: lo.ToPtr(time.Now())},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	shops, err := post.GetShops(context.TODO(), false)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]int{1, 2}, lo.Map(shops, func(shop models.Shop, _ int) int { return shop.ID }),
+		"should return not deleted shops ordered by ID")
+	s.Equal("Shop 2", shops[1].Name, "should return shop's name")
+	s.True(shops[1].Disabled, "should return shop's disabled flag")
+
+	shops, err = post.GetShops(context.TODO(), true)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]int{1, 2, 3}, lo.Map(shops, func(shop models.Shop, _ int) int { return shop.ID }),
+		"should return all shops")
+	s.NotNil(shops[2].DeletedAt, "should return shop's deletion time")
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetRuns() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: 1, URL: faker.Word()},
+		pgmodels.Shop{ID: 2, URL: faker.Word()},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: 1, Status: string(models.RunStatusSucceeded), CreatedProducts: lo.ToPtr(int32(5))},
+		pgmodels.Run{ID: 2, ShopID: 2, Status: string(models.RunStatusSucceeded)},
+		pgmodels.Run{ID: 3, ShopID: 1, Status: string(models.RunStatusFailed), StatusMessage: lo.ToPtr("failed")},
+		pgmodels.Run{ID: 4, ShopID: 1, Status: string(models.RunStatusRunning)},
+	)
+
+	post := storage.NewPostgres(s.DB)
+
+	runs, err := post.GetRuns(context.TODO(), 1, 0, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]int{4, 3}, lo.Map(runs, func(run models.Run, _ int) int { return run.ID }),
+		"should return the newest shop's runs")
+	s.Equal(lo.ToPtr("failed"), runs[1].StatusMessage, "should return run's status message")
+
+	runs, err = post.GetRuns(context.TODO(), 1, 3, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]int{1}, lo.Map(runs, func(run models.Run, _ int) int { return run.ID }),
+		"should return runs older than the cursor")
+	s.Equal(lo.ToPtr(int32(5)), runs[0].CreatedProducts, "should return run's statistics")
+
+	run, err := post.GetRun(context.TODO(), 4)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal(models.RunStatusRunning, run.Status, "should return run's status")
+	s.Equal(1, run.ShopID, "should return run's shop")
+
+	_, err = post.GetRun(context.TODO(), 5)
+	s.ErrorIs(err, platform.ErrRunNotFound, "should return error for not existing run")
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetShopProducts() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	otherShopID := 2
+
+	storagetesting.InsertShops(s.T(), s.DB,
+		pgmodels.Shop{ID: int32(shopID), URL: faker.Word()},
+		pgmodels.Shop{ID: int32(otherShopID), URL: faker.Word()},
+	)
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID)},
+		pgmodels.Run{ID: 2, ShopID: int32(otherShopID)},
+	)
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "1"
+			p.Shippings = []models.Shipping{modelstesting.FakeShipping(), modelstesting.FakeShipping()}
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "2"
+			p.Shippings = nil
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "3" }),
+		modelstesting.FakeProduct(func(p *models.Product) { p.ProductID = "4" }),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{modelstesting.FakeProduct()}, otherShopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 1, []string{"3"})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	firstPage, err := post.GetShopProducts(context.TODO(), shopID, models.ProductFilter{}, 0, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(firstPage, 2, "should return full page")
+
+	secondPage, err := post.GetShopProducts(context.TODO(), shopID, models.ProductFilter{}, firstPage[1].ID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Require().Len(secondPage, 1, "should return not deleted shop's products after the cursor")
+
+	stored := append(firstPage, secondPage...)
+	s.Less(stored[0].ID, stored[1].ID, "should return products ordered by ID")
+	for ix := range stored {
+		stored[ix].ID = 0
+		stored[ix].CreatedAt = time.Time{}
+	}
+	s.Equal([]models.Product{products[0], products[1], products[3]}, stored, "should return products with shippings")
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetShopProductsFilter() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: 1},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), ProductsVersion: 2},
+	)
+
+	products := []models.Product{
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "shoes"
+			p.Version = 1
+			p.Availability = "in stock"
+			p.Price = "10.00 PLN"
+			p.Brand = lo.ToPtr("Brand")
+			p.ProductCategory = lo.ToPtr("Apparel > Shoes")
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "apparel"
+			p.Version = 1
+			p.Availability = "out of stock"
+			p.Price = "25.50 PLN"
+			p.Brand = lo.ToPtr("Brand")
+			p.ProductCategory = lo.ToPtr("Apparel")
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "similar category"
+			p.Version = 1
+			p.Availability = "in stock"
+			p.Price = "invalid"
+			p.Brand = lo.ToPtr("Other")
+			p.ProductCategory = lo.ToPtr("Apparel Accessories")
+		}),
+		modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = "deleted"
+			p.Version = 1
+			p.Availability = "in stock"
+			p.Price = "15.00 PLN"
+		}),
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), products, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	updated := products[0]
+	updated.Version = 2
+	updated.Title = faker.Word()
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{updated}, shopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 2, []string{"deleted"})
+	s.Require().NoError(err, "shouldn't return any error")
+
+	tests := map[string]struct {
+		filter models.ProductFilter
+		want   []string
+	}{
+		"no filter": {
+			want: []string{"shoes", "apparel", "similar category"},
+		},
+		"include deleted": {
+			filter: models.ProductFilter{IncludeDeleted: true},
+			want:   []string{"shoes", "apparel", "similar category", "deleted"},
+		},
+		"availability": {
+			filter: models.ProductFilter{Availability: lo.ToPtr("in stock")},
+			want:   []string{"shoes", "similar category"},
+		},
+		"brand": {
+			filter: models.ProductFilter{Brand: lo.ToPtr("Brand")},
+			want:   []string{"shoes", "apparel"},
+		},
+		"category with subcategories": {
+			filter: models.ProductFilter{Category: lo.ToPtr("Apparel")},
+			want:   []string{"shoes", "apparel"},
+		},
+		"price range": {
+			filter: models.ProductFilter{MinPrice: lo.ToPtr(10.0), MaxPrice: lo.ToPtr(20.0), IncludeDeleted: true},
+			want:   []string{"shoes", "deleted"},
+		},
+		"updated since": {
+			filter: models.ProductFilter{UpdatedSince: lo.ToPtr(int64(1)), IncludeDeleted: true},
+			want:   []string{"shoes", "deleted"},
+		},
+	}
+
+	for name, tt := range tests {
+		s.Run(name, func() {
+			got, err := post.GetShopProducts(context.TODO(), shopID, tt.filter, 0, 10)
+			s.Require().NoError(err, "shouldn't return any error")
+
+			productIDs := lo.Map(got, func(product models.Product, _ int) string {
+				return product.ProductID
+			})
+			s.Equal(tt.want, productIDs, "should return products matching filter")
+		})
+	}
+}
+
+func (s *PostgresTestSuite) TestIntegrationGetProductChanges() {
+	defer storagetesting.CleanupData(s.T(), s.DB)
+	storagetesting.CleanupData(s.T(), s.DB)
+
+	shopID := 1
+	finishedAt := time.Now()
+
+	storagetesting.InsertShops(s.T(), s.DB, pgmodels.Shop{ID: int32(shopID), URL: faker.Word()})
+	storagetesting.InsertRuns(s.T(), s.DB,
+		pgmodels.Run{ID: 1, ShopID: int32(shopID), ProductsVersion: 1, FinishedAt: &finishedAt},
+		pgmodels.Run{ID: 2, ShopID: int32(shopID), ProductsVersion: 2, FinishedAt: &finishedAt},
+		pgmodels.Run{ID: 3, ShopID: int32(shopID), ProductsVersion: 3, HeartbeatAt: time.Now()},
+	)
+
+	fakeProduct := func(productID string, version int64) models.Product {
+		return modelstesting.FakeProduct(func(p *models.Product) {
+			p.ProductID = productID
+			p.Version = version
+		})
+	}
+
+	post := storage.NewPostgres(s.DB)
+
+	_, _, _, err := post.UpdateProducts(context.TODO(), []models.Product{
+		fakeProduct("1", 1), fakeProduct("2", 1), fakeProduct("3", 1),
+	}, shopID, 1)
+	s.Require().NoError(err, "shouldn't return any error")
+	updated := fakeProduct("1", 2)
+	updated.Shippings = []models.Shipping{modelstesting.FakeShipping()}
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{updated, fakeProduct("4", 2)}, shopID, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	_, err = post.DeleteProducts(context.TODO(), shopID, 2, []string{"2"})
+	s.Require().NoError(err, "shouldn't return any error")
+	_, _, _, err = post.UpdateProducts(context.TODO(), []models.Product{fakeProduct("5", 3)}, shopID, 3)
+	s.Require().NoError(err, "shouldn't return any error")
+
+	type change struct {
+		productID  string
+		changeType models.ChangeType
+		version    int64
+	}
+	toChanges := func(changed []models.ChangedProduct) []change {
+		return lo.Map(changed, func(c models.ChangedProduct, _ int) change {
+			return change{productID: c.Product.ProductID, changeType: c.Type, version: c.Version}
+		})
+	}
+
+	all, err := post.GetProductChanges(context.TODO(), shopID, models.ChangesCursor{}, 10)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]change{
+		{productID: "3", changeType: models.ChangeCreated, version: 1},
+		{productID: "1", changeType: models.ChangeCreated, version: 2},
+		{productID: "2", changeType: models.ChangeDeleted, version: 2},
+		{productID: "4", changeType: models.ChangeCreated, version: 2},
+	}, toChanges(all), "should return changes of finished runs ordered by version and ID")
+
+	firstPage, err := post.GetProductChanges(context.TODO(), shopID, models.ChangesCursor{SinceVersion: 1, Version: 1}, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]change{
+		{productID: "1", changeType: models.ChangeUpdated, version: 2},
+		{productID: "2", changeType: models.ChangeDeleted, version: 2},
+	}, toChanges(firstPage), "should return changes since the version")
+	s.Equal(updated.Shippings, firstPage[0].Product.Shippings, "should return shippings")
+
+	secondPage, err := post.GetProductChanges(context.TODO(), shopID, models.ChangesCursor{
+		SinceVersion: 1,
+		Version:      firstPage[1].Version,
+		ID:           firstPage[1].Product.ID,
+	}, 2)
+	s.Require().NoError(err, "shouldn't return any error")
+	s.Equal([]change{
+		{productID: "4", changeType: models.ChangeCreated, version: 2},
+	}, toChanges(secondPage), "should return changes after the cursor")
 }
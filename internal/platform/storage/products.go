@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// GetProducts returns shop's stored products, including deleted ones, with provided product IDs and their shippings.
+func (p Postgres) GetProducts(ctx context.Context, shopID int, productIDs []string) ([]models.Product, error) {
+	products, err := getProducts(ctx, p.db, shopID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get products: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetUnchangedProducts returns product IDs of provided products which have the same content hash
+// as stored products, so UpdateProducts would only set their new version.
+func (p Postgres) GetUnchangedProducts(ctx context.Context, shopID int, products []models.Product) ([]string, error) {
+	productIDs := lo.Map(products, func(_ models.Product, ix int) string { return products[ix].ProductID })
+	storedProducts, err := getStoredProducts(ctx, p.db, int64(shopID), productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get unchanged products: %w", err)
+	}
+
+	_, _, unchangedProducts := compareProducts(products, storedProducts)
+
+	return lo.Map(unchangedProducts, func(_ models.Product, ix int) string {
+		return unchangedProducts[ix].ProductID
+	}), nil
+}
+
+// GetShopProducts returns up to limit shop's products matching filter with their shippings, ordered by ID.
+// If afterID is set, only products with greater ID are returned, so products can be read page by page.
+func (p Postgres) GetShopProducts(
+	ctx context.Context,
+	shopID int,
+	filter models.ProductFilter,
+	afterID int,
+	limit int,
+) ([]models.Product, error) {
+	conditions := append(productFilterConditions(filter),
+		table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+		table.Product.ID.GT(pg.Int32(int32(afterID))),
+	)
+
+	var stored []pgmodels.Product
+	err := table.Product.SELECT(table.Product.AllColumns).
+		WHERE(pg.AND(conditions...)).
+		ORDER_BY(table.Product.ID.ASC()).
+		LIMIT(int64(limit)).
+		QueryContext(ctx, p.db, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("can't get shop products: %w", err)
+	}
+
+	products, err := withShippings(ctx, p.db, stored)
+	if err != nil {
+		return nil, fmt.Errorf("can't get shop products: %w", err)
+	}
+
+	return products, nil
+}
+
+// productFilterConditions returns conditions of products matching filter.
+func productFilterConditions(filter models.ProductFilter) []pg.BoolExpression {
+	var conditions []pg.BoolExpression
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, table.Product.DeletedAt.IS_NULL())
+	}
+
+	if filter.Availability != nil {
+		conditions = append(conditions, table.Product.Availability.EQ(pg.String(*filter.Availability)))
+	}
+
+	if filter.Brand != nil {
+		conditions = append(conditions, table.Product.Brand.EQ(pg.String(*filter.Brand)))
+	}
+
+	if filter.Category != nil {
+		conditions = append(conditions, pg.OR(
+			table.Product.ProductCategory.EQ(pg.String(*filter.Category)),
+			table.Product.ProductCategory.LIKE(pg.String(escapeLike(*filter.Category)+" > %")),
+		))
+	}
+
+	if filter.MinPrice != nil {
+		conditions = append(conditions, table.Product.PriceAmount.GT_EQ(pg.Float(*filter.MinPrice)))
+	}
+
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, table.Product.PriceAmount.LT_EQ(pg.Float(*filter.MaxPrice)))
+	}
+
+	if filter.UpdatedSince != nil {
+		conditions = append(conditions, table.Product.ChangedVersion.GT(pg.Int64(*filter.UpdatedSince)))
+	}
+
+	return conditions
+}
+
+// likeReplacer escapes LIKE pattern's special characters with default escape character.
+var likeReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes LIKE pattern's special characters, so value is matched literally.
+func escapeLike(value string) string {
+	return likeReplacer.Replace(value)
+}
+
+// withShippings loads shippings of provided products and converts them into models.Product.
+func withShippings(ctx context.Context, db qrm.DB, stored []pgmodels.Product) ([]models.Product, error) {
+	if len(stored) == 0 {
+		return []models.Product{}, nil
+	}
+
+	ids := lo.Map(stored, func(product pgmodels.Product, _ int) pg.Expression {
+		return pg.Int32(product.ID)
+	})
+
+	var shippings []pgmodels.Shipping
+	err := table.Shipping.SELECT(table.Shipping.AllColumns).
+		WHERE(table.Shipping.ProductID.IN(ids...)).
+		ORDER_BY(table.Shipping.ID.ASC()).
+		QueryContext(ctx, db, &shippings)
+	if err != nil {
+		return nil, err
+	}
+
+	productShippings := lo.GroupBy(shippings, func(shipping pgmodels.Shipping) int32 {
+		return shipping.ProductID
+	})
+
+	products := make([]models.Product, 0, len(stored))
+	for ix := range stored {
+		products = append(products, FromDBProduct(&stored[ix], productShippings[stored[ix].ID]))
+	}
+
+	return products, nil
+}
+
+func getProducts(ctx context.Context, db qrm.DB, shopID int, productIDs []string) ([]models.Product, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]pg.Expression, 0, len(productIDs))
+	for ix := range productIDs {
+		ids = append(ids, pg.String(productIDs[ix]))
+	}
+
+	var stored []struct {
+		pgmodels.Product
+		Shippings []pgmodels.Shipping
+	}
+	err := pg.SELECT(table.Product.AllColumns, table.Shipping.AllColumns).
+		FROM(table.Product.LEFT_JOIN(table.Shipping, table.Shipping.ProductID.EQ(table.Product.ID))).
+		WHERE(pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.ProductID.IN(ids...),
+		)).
+		ORDER_BY(table.Product.ID.ASC(), table.Shipping.ID.ASC()).
+		QueryContext(ctx, db, &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]models.Product, 0, len(stored))
+	for ix := range stored {
+		products = append(products, FromDBProduct(&stored[ix].Product, stored[ix].Shippings))
+	}
+
+	return products, nil
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+const dayDuration = 24 * time.Hour
+
+// GetRetentionPolicies returns retention policies of all shops, values not set for a shop are nil.
+func (p Postgres) GetRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	var shops []pgmodels.Shop
+	err := table.Shop.SELECT(
+		table.Shop.ID,
+		table.Shop.DeletedProductsRetentionDays,
+		table.Shop.RunsRetentionDays,
+		table.Shop.KeptRuns,
+	).
+		ORDER_BY(table.Shop.ID.ASC()).
+		QueryContext(ctx, p.db, &shops)
+	if err != nil {
+		return nil, fmt.Errorf("can't get shops retention policies: %w", err)
+	}
+
+	return lo.Map(shops, func(shop pgmodels.Shop, _ int) models.RetentionPolicy {
+		return models.RetentionPolicy{
+			ShopID:          int(shop.ID),
+			DeletedProducts: fromDBDays(shop.DeletedProductsRetentionDays),
+			Runs:            fromDBDays(shop.RunsRetentionDays),
+			KeptRuns:        fromDBInt(shop.KeptRuns),
+		}
+	}), nil
+}
+
+// SetRetentionPolicy sets retention policy of the shop, nil values unset shop's values, so defaults are used.
+// Retention times are stored in days, so platform.ErrInvalidRetention is returned if they aren't whole days.
+// Returns platform.ErrShopNotFound if the shop doesn't exist.
+func (p Postgres) SetRetentionPolicy(ctx context.Context, policy models.RetentionPolicy) error {
+	deletedProductsDays, err := toDBDays(policy.DeletedProducts)
+	if err != nil {
+		return fmt.Errorf("invalid deleted products retention: %w", err)
+	}
+
+	runsDays, err := toDBDays(policy.Runs)
+	if err != nil {
+		return fmt.Errorf("invalid runs retention: %w", err)
+	}
+
+	result, err := table.Shop.UPDATE(
+		table.Shop.DeletedProductsRetentionDays,
+		table.Shop.RunsRetentionDays,
+		table.Shop.KeptRuns,
+	).
+		MODEL(pgmodels.Shop{
+			DeletedProductsRetentionDays: deletedProductsDays,
+			RunsRetentionDays:            runsDays,
+			KeptRuns:                     toDBInt(policy.KeptRuns),
+		}).
+		WHERE(table.Shop.ID.EQ(pg.Int32(int32(policy.ShopID)))).
+		ExecContext(ctx, p.db)
+	if err != nil {
+		return fmt.Errorf("can't update shop retention policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("can't update shop retention policy: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return platform.ErrShopNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedProducts hard-deletes up to limit shop's products soft-deleted before provided time,
+// together with their shippings, history and price series. Products locked by running parsing are skipped,
+// so purging never waits for it. Each call is a separate short transaction.
+// Returns number of purged products.
+func (p Postgres) PurgeDeletedProducts(ctx context.Context, shopID int, deletedBefore time.Time, limit int) (int, error) {
+	purged := 0
+
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		var products []pgmodels.Product
+		err := table.Product.SELECT(table.Product.ID).
+			WHERE(pg.AND(
+				table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+				table.Product.DeletedAt.LT(pg.TimestampzT(deletedBefore)),
+			)).
+			ORDER_BY(table.Product.ID.ASC()).
+			LIMIT(int64(limit)).
+			FOR(pg.UPDATE().SKIP_LOCKED()).
+			QueryContext(ctx, tx, &products)
+		if err != nil {
+			return fmt.Errorf("can't get purged products: %w", err)
+		}
+
+		if len(products) == 0 {
+			return nil
+		}
+
+		ids := lo.Map(products, func(product pgmodels.Product, _ int) pg.Expression {
+			return pg.Int32(product.ID)
+		})
+
+		if _, err = table.Shipping.DELETE().WHERE(table.Shipping.ProductID.IN(ids...)).ExecContext(ctx, tx); err != nil {
+			return fmt.Errorf("can't delete purged products shippings: %w", err)
+		}
+
+		_, err = table.ProductHistory.DELETE().WHERE(table.ProductHistory.ProductID.IN(ids...)).ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't delete purged products history: %w", err)
+		}
+
+		_, err = table.PriceSeries.DELETE().WHERE(table.PriceSeries.ProductID.IN(ids...)).ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't delete purged products price series: %w", err)
+		}
+
+		if _, err = table.Product.DELETE().WHERE(table.Product.ID.IN(ids...)).ExecContext(ctx, tx); err != nil {
+			return fmt.Errorf("can't delete purged products: %w", err)
+		}
+
+		purged = len(products)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// PruneRuns deletes up to limit shop's finished runs created before provided time, except the newest kept runs.
+// References to pruned runs in products history and price series are set to null.
+// Runs locked by other transactions are skipped. Returns number of pruned runs.
+func (p Postgres) PruneRuns(ctx context.Context, shopID int, createdBefore time.Time, kept int, limit int) (int, error) {
+	pruned := 0
+
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		var runs []pgmodels.Run
+		err := table.Run.SELECT(table.Run.ID).
+			WHERE(pg.AND(
+				table.Run.ShopID.EQ(pg.Int32(int32(shopID))),
+				table.Run.CreatedAt.LT(pg.TimestampzT(createdBefore)),
+				table.Run.FinishedAt.IS_NOT_NULL(),
+				table.Run.ID.NOT_IN(
+					table.Run.SELECT(table.Run.ID).
+						WHERE(table.Run.ShopID.EQ(pg.Int32(int32(shopID)))).
+						ORDER_BY(table.Run.ID.DESC()).
+						LIMIT(int64(kept)),
+				),
+			)).
+			ORDER_BY(table.Run.ID.ASC()).
+			LIMIT(int64(limit)).
+			FOR(pg.UPDATE().SKIP_LOCKED()).
+			QueryContext(ctx, tx, &runs)
+		if err != nil {
+			return fmt.Errorf("can't get pruned runs: %w", err)
+		}
+
+		if len(runs) == 0 {
+			return nil
+		}
+
+		ids := lo.Map(runs, func(run pgmodels.Run, _ int) pg.Expression {
+			return pg.Int32(run.ID)
+		})
+
+		if _, err = table.Run.DELETE().WHERE(table.Run.ID.IN(ids...)).ExecContext(ctx, tx); err != nil {
+			return fmt.Errorf("can't delete pruned runs: %w", err)
+		}
+
+		pruned = len(runs)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+func fromDBDays(days *int32) *time.Duration {
+	if days == nil {
+		return nil
+	}
+
+	return lo.ToPtr(time.Duration(*days) * dayDuration)
+}
+
+// toDBDays converts retention time to days, it returns platform.ErrInvalidRetention if it isn't whole number of days,
+// so retention shorter than a day isn't stored as zero days, which disables purging.
+func toDBDays(duration *time.Duration) (*int32, error) {
+	if duration == nil {
+		return nil, nil
+	}
+
+	if *duration < 0 || *duration%dayDuration != 0 {
+		return nil, fmt.Errorf("%w: %s", platform.ErrInvalidRetention, *duration)
+	}
+
+	return lo.ToPtr(int32(*duration / dayDuration)), nil
+}
+
+func fromDBInt(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+
+	return lo.ToPtr(int(*value))
+}
+
+func toDBInt(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+
+	return lo.ToPtr(int32(*value))
+}
@@ -0,0 +1,32 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSetRetentionPolicyInvalidRetention(t *testing.T) {
+	tests := map[string]models.RetentionPolicy{
+		"deleted products retention shorter than a day": {ShopID: 1, DeletedProducts: lo.ToPtr(12 * time.Hour)},
+		"runs retention not in whole days":              {ShopID: 1, Runs: lo.ToPtr(36 * time.Hour)},
+		"negative retention":                            {ShopID: 1, Runs: lo.ToPtr(-24 * time.Hour)},
+	}
+
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			// policy is validated before it's stored, so database isn't needed.
+			post := storage.NewPostgres(nil)
+
+			err := post.SetRetentionPolicy(context.TODO(), policy)
+
+			require.ErrorIs(t, err, platform.ErrInvalidRetention, "should return correct error")
+		})
+	}
+}
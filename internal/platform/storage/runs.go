@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// GetRun returns run with provided ID. Returns platform.ErrRunNotFound if the run doesn't exist.
+func (p Postgres) GetRun(ctx context.Context, runID int) (*models.Run, error) {
+	var run pgmodels.Run
+	err := table.Run.SELECT(table.Run.AllColumns).
+		WHERE(table.Run.ID.EQ(pg.Int32(int32(runID)))).
+		QueryContext(ctx, p.db, &run)
+	if errors.Is(err, qrm.ErrNoRows) {
+		return nil, platform.ErrRunNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get run: %w", err)
+	}
+
+	return FromDBRun(&run)
+}
+
+// GetRuns returns up to limit shop's runs from the newest one. If beforeID is set,
+// only runs older than the run with this ID are returned, so runs can be read page by page.
+func (p Postgres) GetRuns(ctx context.Context, shopID int, beforeID int, limit int) ([]models.Run, error) {
+	condition := table.Run.ShopID.EQ(pg.Int32(int32(shopID)))
+	if beforeID > 0 {
+		condition = condition.AND(table.Run.ID.LT(pg.Int32(int32(beforeID))))
+	}
+
+	var dbRuns []pgmodels.Run
+	err := table.Run.SELECT(table.Run.AllColumns).
+		WHERE(condition).
+		ORDER_BY(table.Run.ID.DESC()).
+		LIMIT(int64(limit)).
+		QueryContext(ctx, p.db, &dbRuns)
+	if err != nil {
+		return nil, fmt.Errorf("can't get runs: %w", err)
+	}
+
+	runs := make([]models.Run, 0, len(dbRuns))
+	for ix := range dbRuns {
+		run, err := FromDBRun(&dbRuns[ix])
+		if err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, *run)
+	}
+
+	return runs, nil
+}
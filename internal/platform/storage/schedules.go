@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// GetShopSchedule returns shop's parsing schedule.
+// Returns platform.ErrShopScheduleNotFound if the shop doesn't have a schedule.
+func (p Postgres) GetShopSchedule(ctx context.Context, shopID int) (*models.ShopSchedule, error) {
+	var schedule struct {
+		pgmodels.ShopSchedule
+		Shop pgmodels.Shop
+	}
+	err := pg.SELECT(table.ShopSchedule.AllColumns, table.Shop.URL).
+		FROM(table.ShopSchedule.INNER_JOIN(table.Shop, table.Shop.ID.EQ(table.ShopSchedule.ShopID))).
+		WHERE(table.ShopSchedule.ShopID.EQ(pg.Int32(int32(shopID)))).
+		QueryContext(ctx, p.db, &schedule)
+	if errors.Is(err, qrm.ErrNoRows) {
+		return nil, platform.ErrShopScheduleNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get shop schedule: %w", err)
+	}
+
+	return fromDBShopSchedule(&schedule.ShopSchedule, schedule.Shop.URL, false), nil
+}
+
+// SetShopSchedule replaces shop's parsing schedule with provided cron expression or interval
+// and resets its next run time, so it's calculated again by scheduler. Interval is stored in whole seconds.
+// Cron expression isn't validated, so it should be validated by the caller.
+// Returns platform.ErrShopNotFound if the shop doesn't exist.
+func (p Postgres) SetShopSchedule(ctx context.Context, schedule models.ShopSchedule) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		if _, err := getShopByID(ctx, tx, schedule.ShopID); err != nil {
+			return err
+		}
+
+		dbSchedule := pgmodels.ShopSchedule{
+			ShopID: int32(schedule.ShopID),
+			Cron:   schedule.Cron,
+		}
+		if schedule.Interval != nil {
+			dbSchedule.IntervalSeconds = lo.ToPtr(int32(*schedule.Interval / time.Second))
+		}
+
+		_, err := table.ShopSchedule.INSERT(
+			table.ShopSchedule.ShopID,
+			table.ShopSchedule.Cron,
+			table.ShopSchedule.IntervalSeconds,
+		).
+			MODEL(dbSchedule).
+			ON_CONFLICT(table.ShopSchedule.ShopID).
+			DO_UPDATE(pg.SET(
+				table.ShopSchedule.Cron.SET(table.ShopSchedule.EXCLUDED.Cron),
+				table.ShopSchedule.IntervalSeconds.SET(table.ShopSchedule.EXCLUDED.IntervalSeconds),
+				table.ShopSchedule.NextRunAt.SET(pg.TimestampzExp(pg.NULL)),
+				table.ShopSchedule.UpdatedAt.SET(pg.TimestampzT(time.Now())),
+			)).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't save shop schedule: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteShopSchedule removes shop's parsing schedule, so the shop is no longer parsed periodically.
+// Deleting not existing schedule does nothing.
+func (p Postgres) DeleteShopSchedule(ctx context.Context, shopID int) error {
+	_, err := table.ShopSchedule.DELETE().
+		WHERE(table.ShopSchedule.ShopID.EQ(pg.Int32(int32(shopID)))).
+		ExecContext(ctx, p.db)
+	if err != nil {
+		return fmt.Errorf("can't delete shop schedule: %w", err)
+	}
+
+	return nil
+}
+
+// ProcessDueSchedules passes up to limit schedules of enabled shops, which are due at provided time
+// or which next run time isn't calculated yet, to process function in order of their next run time.
+// Schedule's next run time is set to time returned by process function.
+// Schedules are locked until the transaction ends and schedules locked by other transactions are skipped,
+// so each due schedule is processed only once, even by several concurrent schedulers.
+// Processing stops on first process function's error, and next run times of already processed schedules are saved.
+// Returns number of processed schedules.
+func (p Postgres) ProcessDueSchedules(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	process func(ctx context.Context, schedule models.ShopSchedule) (time.Time, error),
+) (int, error) {
+	processed := 0
+	var processErr error
+
+	err := runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		running := pg.EXISTS(
+			table.Run.SELECT(table.Run.ID).
+				WHERE(pg.AND(
+					table.Run.ShopID.EQ(table.ShopSchedule.ShopID),
+					table.Run.FinishedAt.IS_NULL(),
+					table.Run.Success.IS_NULL(),
+					table.Run.HeartbeatAt.GT(pg.TimestampzT(now.Add(-p.runLease))),
+				)),
+		)
+
+		// anonymous struct, so running flag is mapped by field name.
+		var schedules []struct {
+			pgmodels.ShopSchedule
+			Shop    pgmodels.Shop
+			Running bool
+		}
+		err := pg.SELECT(table.ShopSchedule.AllColumns, table.Shop.URL, running.AS("running")).
+			FROM(table.ShopSchedule.INNER_JOIN(table.Shop, table.Shop.ID.EQ(table.ShopSchedule.ShopID))).
+			WHERE(pg.AND(
+				table.Shop.Disabled.IS_FALSE(),
+				table.Shop.DeletedAt.IS_NULL(),
+				pg.OR(
+					table.ShopSchedule.NextRunAt.IS_NULL(),
+					table.ShopSchedule.NextRunAt.LT_EQ(pg.TimestampzT(now)),
+				),
+			)).
+			ORDER_BY(table.ShopSchedule.NextRunAt.ASC().NULLS_FIRST(), table.ShopSchedule.ShopID.ASC()).
+			LIMIT(int64(limit)).
+			FOR(pg.UPDATE().OF(table.ShopSchedule).SKIP_LOCKED()).
+			QueryContext(ctx, tx, &schedules)
+		if err != nil {
+			return fmt.Errorf("can't get due schedules: %w", err)
+		}
+
+		for ix := range schedules {
+			var nextRunAt time.Time
+			nextRunAt, processErr = process(
+				ctx,
+				*fromDBShopSchedule(&schedules[ix].ShopSchedule, schedules[ix].Shop.URL, schedules[ix].Running),
+			)
+			if processErr != nil {
+				break
+			}
+
+			_, err = table.ShopSchedule.UPDATE(table.ShopSchedule.NextRunAt).
+				SET(pg.TimestampzT(nextRunAt)).
+				WHERE(table.ShopSchedule.ShopID.EQ(pg.Int32(schedules[ix].ShopID))).
+				ExecContext(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("can't update schedule next run time: %w", err)
+			}
+
+			processed++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if processErr != nil {
+		return processed, fmt.Errorf("can't process schedule: %w", processErr)
+	}
+
+	return processed, nil
+}
+
+func fromDBShopSchedule(schedule *pgmodels.ShopSchedule, shopURL string, running bool) *models.ShopSchedule {
+	shopSchedule := &models.ShopSchedule{
+		ShopID:    int(schedule.ShopID),
+		ShopURL:   shopURL,
+		Cron:      schedule.Cron,
+		NextRunAt: schedule.NextRunAt,
+		Running:   running,
+	}
+
+	if schedule.IntervalSeconds != nil {
+		shopSchedule.Interval = lo.ToPtr(time.Duration(*schedule.IntervalSeconds) * time.Second)
+	}
+
+	return shopSchedule
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// outOfStock are availability values of products which are out of stock.
+var outOfStock = []string{"out_of_stock", "out of stock"}
+
+// GetPriceSeries returns time series of price and availability of shop's product with provided product ID,
+// from the oldest to the newest point.
+func (p Postgres) GetPriceSeries(ctx context.Context, shopID int, productID string) ([]models.PricePoint, error) {
+	var series []pgmodels.PriceSeries
+	err := pg.SELECT(table.PriceSeries.AllColumns).
+		FROM(table.PriceSeries.INNER_JOIN(table.Product, table.Product.ID.EQ(table.PriceSeries.ProductID))).
+		WHERE(pg.AND(
+			table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+			table.Product.ProductID.EQ(pg.String(productID)),
+		)).
+		ORDER_BY(table.PriceSeries.ID.ASC()).
+		QueryContext(ctx, p.db, &series)
+	if err != nil {
+		return nil, fmt.Errorf("can't get price series: %w", err)
+	}
+
+	return lo.Map(series, func(point pgmodels.PriceSeries, _ int) models.PricePoint {
+		return fromDBPricePoint(&point)
+	}), nil
+}
+
+// GetDailyPriceStats returns price and availability changes of shop's products aggregated per UTC day,
+// for changes created in provided time range [from, to). Days without changes are skipped.
+// First point of a product is compared with nothing, so it's counted only as changed product.
+func (p Postgres) GetDailyPriceStats(
+	ctx context.Context,
+	shopID int,
+	from time.Time,
+	to time.Time,
+) ([]models.DailyPriceStats, error) {
+	changes := pg.CTE("changes")
+
+	effectivePrice := pg.COALESCE(table.PriceSeries.SalePrice, table.PriceSeries.Price)
+	window := pg.PARTITION_BY(table.PriceSeries.ProductID).ORDER_BY(table.PriceSeries.ID)
+
+	productID := pg.IntegerColumn("product_id").From(changes)
+	availability := pg.StringColumn("availability").From(changes)
+	price := pg.FloatColumn("price").From(changes)
+	previousPrice := pg.FloatColumn("previous_price").From(changes)
+	previousAvailability := pg.StringColumn("previous_availability").From(changes)
+
+	outOfStockValues := lo.Map(outOfStock, func(value string, _ int) pg.Expression { return pg.String(value) })
+
+	day := pg.CAST(pg.RawTimestamp("changes.created_at AT TIME ZONE 'UTC'")).AS_DATE()
+
+	// anonymous struct, so columns are mapped by field names only.
+	var stats []struct {
+		Day             time.Time
+		ChangedProducts int32
+		PriceDrops      int32
+		PriceRises      int32
+		StockOuts       int32
+	}
+	err := pg.WITH(
+		changes.AS(
+			pg.SELECT(
+				table.PriceSeries.ProductID.AS("product_id"),
+				table.PriceSeries.CreatedAt.AS("created_at"),
+				table.PriceSeries.Availability.AS("availability"),
+				effectivePrice.AS("price"),
+				pg.LAG(effectivePrice).OVER(window).AS("previous_price"),
+				pg.LAG(table.PriceSeries.Availability).OVER(window).AS("previous_availability"),
+			).
+				FROM(table.PriceSeries.INNER_JOIN(table.Product, table.Product.ID.EQ(table.PriceSeries.ProductID))).
+				WHERE(pg.AND(
+					table.Product.ShopID.EQ(pg.Int32(int32(shopID))),
+					table.PriceSeries.CreatedAt.LT(pg.TimestampzT(to)),
+				)),
+		),
+	)(
+		pg.SELECT(
+			day.AS("day"),
+			pg.COUNT(pg.DISTINCT(productID)).AS("changed_products"),
+			pg.COUNT(pg.CASE().WHEN(price.LT(previousPrice)).THEN(productID)).AS("price_drops"),
+			pg.COUNT(pg.CASE().WHEN(price.GT(previousPrice)).THEN(productID)).AS("price_rises"),
+			pg.COUNT(
+				pg.CASE().
+					WHEN(pg.AND(
+						availability.IN(outOfStockValues...),
+						previousAvailability.NOT_IN(outOfStockValues...),
+					)).
+					THEN(productID),
+			).AS("stock_outs"),
+		).
+			FROM(changes).
+			WHERE(pg.TimestampzColumn("created_at").From(changes).GT_EQ(pg.TimestampzT(from))).
+			GROUP_BY(day).
+			ORDER_BY(day.ASC()),
+	).QueryContext(ctx, p.db, &stats)
+	if err != nil {
+		return nil, fmt.Errorf("can't get daily price stats: %w", err)
+	}
+
+	dailyStats := make([]models.DailyPriceStats, 0, len(stats))
+	for _, dayStats := range stats {
+		dailyStats = append(dailyStats, models.DailyPriceStats(dayStats))
+	}
+
+	return dailyStats, nil
+}
+
+// insertPriceSeries saves price points of new products and of updated products with changed price,
+// sale price or availability. Products have to be already upserted, so new products have IDs.
+func insertPriceSeries(
+	ctx context.Context,
+	db qrm.DB,
+	shopID int,
+	runID int,
+	newProducts []models.Product,
+	updatedProducts []models.Product,
+	previousProducts []models.Product,
+) error {
+	newIDs := lo.Map(newProducts, func(_ models.Product, ix int) string {
+		return newProducts[ix].ProductID
+	})
+	stored, err := getStoredProducts(ctx, db, int64(shopID), newIDs)
+	if err != nil {
+		return fmt.Errorf("can't get new products: %w", err)
+	}
+
+	series := make([]pgmodels.PriceSeries, 0, len(newProducts)+len(updatedProducts))
+	for ix := range newProducts {
+		storedProduct, ok := stored[newProducts[ix].ProductID]
+		if !ok {
+			continue
+		}
+
+		series = append(series, toDBPricePoint(&newProducts[ix], storedProduct.ID, runID))
+	}
+
+	previousByID := lo.KeyBy(previousProducts, func(product models.Product) string { return product.ProductID })
+	for ix := range updatedProducts {
+		previousProduct, ok := previousByID[updatedProducts[ix].ProductID]
+		if !ok {
+			continue
+		}
+
+		point := toDBPricePoint(&updatedProducts[ix], int32(previousProduct.ID), runID)
+		if samePricePoint(&point, lo.ToPtr(toDBPricePoint(&previousProduct, point.ProductID, runID))) {
+			continue
+		}
+
+		series = append(series, point)
+	}
+
+	if len(series) == 0 {
+		return nil
+	}
+
+	_, err = table.PriceSeries.INSERT(table.PriceSeries.AllColumns.Except(
+		table.PriceSeries.ID,
+		table.PriceSeries.CreatedAt,
+	)).
+		MODELS(series).
+		ExecContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("can't insert price series: %w", err)
+	}
+
+	return nil
+}
+
+func samePricePoint(a, b *pgmodels.PriceSeries) bool {
+	return lo.FromPtr(a.Price) == lo.FromPtr(b.Price) && (a.Price == nil) == (b.Price == nil) &&
+		lo.FromPtr(a.SalePrice) == lo.FromPtr(b.SalePrice) && (a.SalePrice == nil) == (b.SalePrice == nil) &&
+		lo.FromPtr(a.Currency) == lo.FromPtr(b.Currency) &&
+		a.Availability == b.Availability
+}
+
+func toDBPricePoint(product *models.Product, productID int32, runID int) pgmodels.PriceSeries {
+	price, currency := models.ParsePrice(product.Price)
+
+	var salePrice *float64
+	if product.SalePrice != nil {
+		salePrice, _ = models.ParsePrice(*product.SalePrice)
+	}
+
+	return pgmodels.PriceSeries{
+		ProductID:    productID,
+		RunID:        lo.ToPtr(int32(runID)),
+		Price:        price,
+		Currency:     lo.EmptyableToPtr(currency),
+		SalePrice:    salePrice,
+		Availability: product.Availability,
+	}
+}
+
+func fromDBPricePoint(point *pgmodels.PriceSeries) models.PricePoint {
+	var runID *int
+	if point.RunID != nil {
+		runID = lo.ToPtr(int(*point.RunID))
+	}
+
+	return models.PricePoint{
+		RunID:        runID,
+		Price:        point.Price,
+		Currency:     point.Currency,
+		SalePrice:    point.SalePrice,
+		Availability: point.Availability,
+		CreatedAt:    point.CreatedAt,
+	}
+}
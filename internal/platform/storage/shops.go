@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/samber/lo"
+
+	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
+	pg "github.com/go-jet/jet/v2/postgres"
+)
+
+// CreateShop inserts provided shop and sets its ID and creation time.
+// Returns platform.ErrShopURLTaken if the url is already used by other shop.
+func (p Postgres) CreateShop(ctx context.Context, shop *models.Shop) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		if err := checkShopURL(ctx, tx, shop.URL, 0); err != nil {
+			return err
+		}
+
+		dbShop := pgmodels.Shop{
+			Name:     shop.Name,
+			URL:      shop.URL,
+			Disabled: shop.Disabled,
+		}
+		err := table.Shop.INSERT(table.Shop.Name, table.Shop.URL, table.Shop.Disabled).
+			MODEL(dbShop).
+			RETURNING(table.Shop.ID, table.Shop.CreatedAt).
+			QueryContext(ctx, tx, &dbShop)
+		if err != nil {
+			return fmt.Errorf("can't insert shop: %w", err)
+		}
+
+		shop.ID = int(dbShop.ID)
+		shop.CreatedAt = dbShop.CreatedAt
+
+		return nil
+	})
+}
+
+// GetShop returns shop with provided ID, including deleted shop.
+// Returns platform.ErrShopNotFound if the shop doesn't exist.
+func (p Postgres) GetShop(ctx context.Context, shopID int) (*models.Shop, error) {
+	shop, err := getShopByID(ctx, p.db, shopID)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDBShop(shop), nil
+}
+
+// GetShops returns all shops ordered by ID, deleted shops are returned only if includeDeleted is set.
+func (p Postgres) GetShops(ctx context.Context, includeDeleted bool) ([]models.Shop, error) {
+	condition := pg.Bool(true)
+	if !includeDeleted {
+		condition = table.Shop.DeletedAt.IS_NULL()
+	}
+
+	var shops []pgmodels.Shop
+	err := table.Shop.SELECT(table.Shop.AllColumns).
+		WHERE(condition).
+		ORDER_BY(table.Shop.ID.ASC()).
+		QueryContext(ctx, p.db, &shops)
+	if err != nil {
+		return nil, fmt.Errorf("can't get shops: %w", err)
+	}
+
+	return lo.Map(shops, func(shop pgmodels.Shop, _ int) models.Shop {
+		return *fromDBShop(&shop)
+	}), nil
+}
+
+// UpdateShop updates name, url and disabled flag of the shop. Shop keeps its ID when its url changes,
+// so its products, runs and their history are kept, and the previous url is saved in shop's url history.
+// Returns platform.ErrShopNotFound if the shop doesn't exist, platform.ErrShopDeleted if it's deleted
+// and platform.ErrShopURLTaken if the url is already used by other shop.
+func (p Postgres) UpdateShop(ctx context.Context, shop models.Shop) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		stored, err := getShopByID(ctx, tx, shop.ID)
+		if err != nil {
+			return err
+		}
+
+		if stored.DeletedAt != nil {
+			return platform.ErrShopDeleted
+		}
+
+		if stored.URL != shop.URL {
+			if err = checkShopURL(ctx, tx, shop.URL, shop.ID); err != nil {
+				return err
+			}
+
+			_, err = table.ShopURLHistory.INSERT(table.ShopURLHistory.ShopID, table.ShopURLHistory.URL).
+				VALUES(stored.ID, stored.URL).
+				ExecContext(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("can't save shop url history: %w", err)
+			}
+		}
+
+		_, err = table.Shop.UPDATE(table.Shop.Name, table.Shop.URL, table.Shop.Disabled).
+			MODEL(pgmodels.Shop{
+				Name:     shop.Name,
+				URL:      shop.URL,
+				Disabled: shop.Disabled,
+			}).
+			WHERE(table.Shop.ID.EQ(pg.Int32(stored.ID))).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't update shop: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteShop soft-deletes the shop, its products and runs are kept. Deleting already deleted shop does nothing.
+// Returns platform.ErrShopNotFound if the shop doesn't exist.
+func (p Postgres) DeleteShop(ctx context.Context, shopID int) error {
+	return runInTransaction(ctx, p.db, func(tx *sql.Tx) error {
+		if _, err := getShopByID(ctx, tx, shopID); err != nil {
+			return err
+		}
+
+		_, err := table.Shop.UPDATE(table.Shop.DeletedAt).
+			SET(pg.TimestampzT(time.Now())).
+			WHERE(pg.AND(
+				table.Shop.ID.EQ(pg.Int32(int32(shopID))),
+				table.Shop.DeletedAt.IS_NULL(),
+			)).
+			ExecContext(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("can't delete shop: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetShopURLHistory returns previous urls of the shop, from the oldest to the newest change.
+func (p Postgres) GetShopURLHistory(ctx context.Context, shopID int) ([]models.ShopURLChange, error) {
+	var history []pgmodels.ShopURLHistory
+	err := table.ShopURLHistory.SELECT(table.ShopURLHistory.AllColumns).
+		WHERE(table.ShopURLHistory.ShopID.EQ(pg.Int32(int32(shopID)))).
+		ORDER_BY(table.ShopURLHistory.ID.ASC()).
+		QueryContext(ctx, p.db, &history)
+	if err != nil {
+		return nil, fmt.Errorf("can't get shop url history: %w", err)
+	}
+
+	return lo.Map(history, func(change pgmodels.ShopURLHistory, _ int) models.ShopURLChange {
+		return models.ShopURLChange{
+			URL:       change.URL,
+			ChangedAt: change.ChangedAt,
+		}
+	}), nil
+}
+
+func getShopByID(ctx context.Context, db qrm.DB, shopID int) (*pgmodels.Shop, error) {
+	var shop pgmodels.Shop
+	err := table.Shop.SELECT(table.Shop.AllColumns).
+		WHERE(table.Shop.ID.EQ(pg.Int32(int32(shopID)))).
+		QueryContext(ctx, db, &shop)
+	if errors.Is(err, qrm.ErrNoRows) {
+		return nil, platform.ErrShopNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get shop: %w", err)
+	}
+
+	return &shop, nil
+}
+
+// checkShopURL returns platform.ErrShopURLTaken if the url is used by other shop than the shop with provided ID.
+func checkShopURL(ctx context.Context, db qrm.DB, url string, shopID int) error {
+	var shops []pgmodels.Shop
+	err := table.Shop.SELECT(table.Shop.ID).
+		WHERE(pg.AND(
+			table.Shop.URL.EQ(pg.String(url)),
+			table.Shop.ID.NOT_EQ(pg.Int32(int32(shopID))),
+		)).
+		QueryContext(ctx, db, &shops)
+	if err != nil {
+		return fmt.Errorf("can't check shop url: %w", err)
+	}
+
+	if len(shops) > 0 {
+		return platform.ErrShopURLTaken
+	}
+
+	return nil
+}
+
+func fromDBShop(shop *pgmodels.Shop) *models.Shop {
+	return &models.Shop{
+		ID:        int(shop.ID),
+		Name:      shop.Name,
+		URL:       shop.URL,
+		Disabled:  shop.Disabled,
+		CreatedAt: shop.CreatedAt,
+		DeletedAt: shop.DeletedAt,
+	}
+}
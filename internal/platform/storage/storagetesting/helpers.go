@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/platform/storage"
 	pgmodels "github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/model"
 	"github.com/MichalMitros/google-feed-parser/internal/platform/storage/gen/postgres/public/table"
 	pg "github.com/go-jet/jet/v2/postgres"
@@ -16,7 +17,7 @@ import (
 )
 
 // Open opens connection to DB.
-func Open(t *testing.T) *sql.DB {
+func Open(t testing.TB) *sql.DB {
 	t.Helper()
 
 	dbURL := os.Getenv("DATABASE_URL")
@@ -51,7 +52,7 @@ func BeginTx(t *testing.T, db *sql.DB) (*sql.Tx, func()) {
 }
 
 // InsertShops is a helper test function to insert jobs.
-func InsertShops(t *testing.T, exc qrm.Executable, shops ...pgmodels.Shop) {
+func InsertShops(t testing.TB, exc qrm.Executable, shops ...pgmodels.Shop) {
 	t.Helper()
 
 	if len(shops) == 0 {
@@ -68,7 +69,7 @@ func InsertShops(t *testing.T, exc qrm.Executable, shops ...pgmodels.Shop) {
 }
 
 // InsertRuns is a helper test function to insert runs.
-func InsertRuns(t *testing.T, exc qrm.Executable, runs ...pgmodels.Run) {
+func InsertRuns(t testing.TB, exc qrm.Executable, runs ...pgmodels.Run) {
 	t.Helper()
 
 	if len(runs) == 0 {
@@ -194,19 +195,12 @@ func GetLatestRun(t *testing.T, queryable qrm.Queryable, shopID int) *models.Run
 		t.Fatal("can't get shop ID", err)
 	}
 
-	return &models.Run{
-		ID:              int(runs[0].ID),
-		ShopID:          int(runs[0].ShopID),
-		CreatedAt:       runs[0].CreatedAt,
-		FinishedAt:      runs[0].FinishedAt,
-		IsSuccess:       runs[0].Success,
-		StatusMessage:   runs[0].StatusMessage,
-		CreatedProducts: runs[0].CreatedProducts,
-		UpdatedProducts: runs[0].UpdatedProducts,
-		DeletedProducts: runs[0].DeletedProducts,
-		FailedProducts:  runs[0].FailedProducts,
-		ProductsVersion: runs[0].ProductsVersion,
+	run, err := storage.FromDBRun(&runs[0])
+	if err != nil {
+		t.Fatal("can't convert run", err)
 	}
+
+	return run
 }
 
 // GetProductsByShopID is a helper test function to get products by shop ID.
@@ -246,7 +240,7 @@ func GetShippingByProductID(t *testing.T, queryable qrm.Queryable, productID int
 }
 
 // InsertShops is a helper test function to insert jobs.
-func CleanupData(t *testing.T, exc qrm.Executable) {
+func CleanupData(t testing.TB, exc qrm.Executable) {
 	t.Helper()
 
 	_, err := table.Shipping.DELETE().WHERE(table.Shipping.ID.IS_NOT_NULL()).Exec(exc)
@@ -254,6 +248,16 @@ func CleanupData(t *testing.T, exc qrm.Executable) {
 		t.Fatal("can't delete shippings data", err)
 	}
 
+	_, err = table.ProductHistory.DELETE().WHERE(table.ProductHistory.ID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete products history data", err)
+	}
+
+	_, err = table.PriceSeries.DELETE().WHERE(table.PriceSeries.ID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete price series data", err)
+	}
+
 	_, err = table.Product.DELETE().WHERE(table.Product.ID.IS_NOT_NULL()).Exec(exc)
 	if err != nil {
 		t.Fatal("can't delete products data", err)
@@ -264,6 +268,31 @@ func CleanupData(t *testing.T, exc qrm.Executable) {
 		t.Fatal("can't delete runs data", err)
 	}
 
+	_, err = table.SupplementalFeed.DELETE().WHERE(table.SupplementalFeed.ID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete supplemental feeds data", err)
+	}
+
+	_, err = table.Outbox.DELETE().WHERE(table.Outbox.ID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete outbox data", err)
+	}
+
+	_, err = table.ShopConfig.DELETE().WHERE(table.ShopConfig.ShopID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete shops config data", err)
+	}
+
+	_, err = table.ShopSchedule.DELETE().WHERE(table.ShopSchedule.ShopID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete shops schedules data", err)
+	}
+
+	_, err = table.ShopURLHistory.DELETE().WHERE(table.ShopURLHistory.ID.IS_NOT_NULL()).Exec(exc)
+	if err != nil {
+		t.Fatal("can't delete shops url history data", err)
+	}
+
 	_, err = table.Shop.DELETE().WHERE(table.Shop.ID.IS_NOT_NULL()).Exec(exc)
 	if err != nil {
 		t.Fatal("can't delete shops data", err)
@@ -0,0 +1,240 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultInterval is default interval of purging expired data.
+	DefaultInterval = time.Hour
+	// DefaultSweepInterval is default interval of closing abandoned runs.
+	DefaultSweepInterval = 5 * time.Minute
+	// DefaultBatchSize is default maximum number of products or runs deleted or closed in one transaction.
+	DefaultBatchSize = 500
+)
+
+//go:generate mockery --name Storage --filename storage.go
+
+// Storage stores shops products and runs.
+type Storage interface {
+	// CloseAbandonedRuns finishes up to limit unfinished runs which lease expired as failed.
+	// Returns number of closed runs.
+	CloseAbandonedRuns(ctx context.Context, limit int) (int, error)
+	// GetRetentionPolicies returns retention policies of all shops.
+	GetRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error)
+	// PurgeDeletedProducts hard-deletes up to limit shop's products soft-deleted before provided time.
+	// Returns number of purged products.
+	PurgeDeletedProducts(ctx context.Context, shopID int, deletedBefore time.Time, limit int) (int, error)
+	// PruneRuns deletes up to limit shop's runs created before provided time, except the newest kept runs.
+	// Returns number of pruned runs.
+	PruneRuns(ctx context.Context, shopID int, createdBefore time.Time, kept int, limit int) (int, error)
+}
+
+// Purger periodically closes abandoned runs, purges shops soft-deleted products and prunes their old runs.
+// Abandoned runs are closed with their own interval, so they don't wait for purging of expired data.
+// Shop's retention policy overrides default retention, zero retention time disables purging.
+type Purger struct {
+	storage                  Storage
+	logger                   *zerolog.Logger
+	interval                 time.Duration
+	sweepInterval            time.Duration
+	batchSize                int
+	deletedProductsRetention time.Duration
+	runsRetention            time.Duration
+	keptRuns                 int
+	now                      func() time.Time
+}
+
+// Option is Purger's option.
+type Option func(p *Purger)
+
+// NewPurger returns new Purger deleting data from storage. By default nothing is purged.
+func NewPurger(storage Storage, logger *zerolog.Logger, ops ...Option) *Purger {
+	purger := &Purger{
+		storage:       storage,
+		logger:        logger,
+		interval:      DefaultInterval,
+		sweepInterval: DefaultSweepInterval,
+		batchSize:     DefaultBatchSize,
+		now:           time.Now,
+	}
+
+	for _, op := range ops {
+		op(purger)
+	}
+
+	return purger
+}
+
+// WithInterval sets interval of purging expired data.
+func WithInterval(interval time.Duration) Option {
+	return func(p *Purger) {
+		p.interval = interval
+	}
+}
+
+// WithSweepInterval sets interval of closing abandoned runs.
+// It should be close to run lease, so abandoned runs are closed shortly after their lease expires.
+func WithSweepInterval(interval time.Duration) Option {
+	return func(p *Purger) {
+		p.sweepInterval = interval
+	}
+}
+
+// WithBatchSize sets maximum number of products or runs deleted or closed in one transaction.
+func WithBatchSize(size int) Option {
+	return func(p *Purger) {
+		p.batchSize = size
+	}
+}
+
+// WithDeletedProductsRetention sets default time after which soft-deleted products are purged.
+func WithDeletedProductsRetention(retention time.Duration) Option {
+	return func(p *Purger) {
+		p.deletedProductsRetention = retention
+	}
+}
+
+// WithRunsRetention sets default time after which runs are pruned and number of the newest runs never pruned.
+func WithRunsRetention(retention time.Duration, kept int) Option {
+	return func(p *Purger) {
+		p.runsRetention = retention
+		p.keptRuns = kept
+	}
+}
+
+// WithClock sets function returning current time.
+func WithClock(now func() time.Time) Option {
+	return func(p *Purger) {
+		p.now = now
+	}
+}
+
+// Run closes abandoned runs and purges expired data until context is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	sweepTicker := time.NewTicker(p.sweepInterval)
+	defer sweepTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sweepTicker.C:
+			if err := p.CloseAbandonedRuns(ctx); err != nil && ctx.Err() == nil {
+				p.logger.Error().
+					Err(err).
+					Msg("can't close abandoned runs")
+			}
+		case <-ticker.C:
+			if err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+				p.logger.Error().
+					Err(err).
+					Msg("can't purge expired data")
+			}
+		}
+	}
+}
+
+// CloseAbandonedRuns closes runs which lease expired as failed, batch by batch.
+func (p *Purger) CloseAbandonedRuns(ctx context.Context) error {
+	closed, err := p.inBatches(func() (int, error) {
+		return p.storage.CloseAbandonedRuns(ctx, p.batchSize)
+	})
+	if closed > 0 {
+		p.logger.Info().
+			Int("closedRuns", closed).
+			Msg("closed abandoned runs")
+	}
+
+	return err
+}
+
+// Purge purges expired products and runs of all shops, batch by batch.
+func (p *Purger) Purge(ctx context.Context) error {
+	policies, err := p.storage.GetRetentionPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := p.now()
+	for _, policy := range policies {
+		if err = p.purgeShop(ctx, policy, now); err != nil {
+			return fmt.Errorf("can't purge shop %d: %w", policy.ShopID, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Purger) purgeShop(ctx context.Context, policy models.RetentionPolicy, now time.Time) error {
+	if retention := p.retention(policy.DeletedProducts, p.deletedProductsRetention); retention > 0 {
+		purged, err := p.inBatches(func() (int, error) {
+			return p.storage.PurgeDeletedProducts(ctx, policy.ShopID, now.Add(-retention), p.batchSize)
+		})
+		if err != nil {
+			return err
+		}
+
+		if purged > 0 {
+			p.logger.Info().
+				Int("shopId", policy.ShopID).
+				Int("purgedProducts", purged).
+				Msg("purged deleted products")
+		}
+	}
+
+	if retention := p.retention(policy.Runs, p.runsRetention); retention > 0 {
+		kept := p.keptRuns
+		if policy.KeptRuns != nil {
+			kept = *policy.KeptRuns
+		}
+
+		pruned, err := p.inBatches(func() (int, error) {
+			return p.storage.PruneRuns(ctx, policy.ShopID, now.Add(-retention), kept, p.batchSize)
+		})
+		if err != nil {
+			return err
+		}
+
+		if pruned > 0 {
+			p.logger.Info().
+				Int("shopId", policy.ShopID).
+				Int("prunedRuns", pruned).
+				Msg("pruned old runs")
+		}
+	}
+
+	return nil
+}
+
+// inBatches calls deleteBatch until it deletes less than batch size. Returns total number of deleted rows.
+func (p *Purger) inBatches(deleteBatch func() (int, error)) (int, error) {
+	total := 0
+	for {
+		deleted, err := deleteBatch()
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < p.batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (p *Purger) retention(shopRetention *time.Duration, defaultRetention time.Duration) time.Duration {
+	if shopRetention != nil {
+		return *shopRetention
+	}
+
+	return defaultRetention
+}
@@ -0,0 +1,215 @@
+package retention_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/retention"
+	"github.com/MichalMitros/google-feed-parser/internal/retention/mocks"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitPurge(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	type purge struct {
+		before time.Time
+		purged []int
+	}
+
+	type prune struct {
+		before time.Time
+		kept   int
+		pruned []int
+	}
+
+	tests := map[string]struct {
+		ops         []retention.Option
+		batchSize   int
+		policy      models.RetentionPolicy
+		purge       *purge
+		prune       *prune
+		storageErr  error
+		policiesErr error
+		wantErr     error
+	}{
+		"nothing purged by default": {
+			policy: models.RetentionPolicy{ShopID: 1},
+		},
+		"default retention": {
+			ops: []retention.Option{
+				retention.WithDeletedProductsRetention(30 * day),
+				retention.WithRunsRetention(90*day, 10),
+			},
+			policy: models.RetentionPolicy{ShopID: 1},
+			purge:  &purge{before: now.Add(-30 * day), purged: []int{1}},
+			prune:  &prune{before: now.Add(-90 * day), kept: 10, pruned: []int{0}},
+		},
+		"shop's retention overrides default": {
+			ops: []retention.Option{
+				retention.WithDeletedProductsRetention(30 * day),
+				retention.WithRunsRetention(90*day, 10),
+			},
+			policy: models.RetentionPolicy{
+				ShopID:          1,
+				DeletedProducts: lo.ToPtr(7 * day),
+				Runs:            lo.ToPtr(14 * day),
+				KeptRuns:        lo.ToPtr(3),
+			},
+			purge: &purge{before: now.Add(-7 * day), purged: []int{0}},
+			prune: &prune{before: now.Add(-14 * day), kept: 3, pruned: []int{0}},
+		},
+		"shop's zero retention disables purging": {
+			ops: []retention.Option{
+				retention.WithDeletedProductsRetention(30 * day),
+				retention.WithRunsRetention(90*day, 10),
+			},
+			policy: models.RetentionPolicy{ShopID: 1, DeletedProducts: lo.ToPtr(time.Duration(0))},
+			prune:  &prune{before: now.Add(-90 * day), kept: 10, pruned: []int{0}},
+		},
+		"many batches": {
+			ops: []retention.Option{
+				retention.WithDeletedProductsRetention(30 * day),
+				retention.WithRunsRetention(90*day, 0),
+			},
+			batchSize: 2,
+			policy:    models.RetentionPolicy{ShopID: 1},
+			purge:     &purge{before: now.Add(-30 * day), purged: []int{2, 2, 1}},
+			prune:     &prune{before: now.Add(-90 * day), pruned: []int{2, 0}},
+		},
+		"storage error": {
+			ops: []retention.Option{
+				retention.WithDeletedProductsRetention(30 * day),
+				retention.WithRunsRetention(90*day, 10),
+			},
+			policy:     models.RetentionPolicy{ShopID: 1},
+			purge:      &purge{before: now.Add(-30 * day), purged: []int{0}},
+			storageErr: assert.AnError,
+			wantErr:    assert.AnError,
+		},
+		"policies error": {
+			policiesErr: assert.AnError,
+			wantErr:     assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			logger := zerolog.Nop()
+
+			ops := append(slices.Clone(tt.ops), retention.WithClock(func() time.Time { return now }))
+			batchSize := retention.DefaultBatchSize
+			if tt.batchSize > 0 {
+				batchSize = tt.batchSize
+				ops = append(ops, retention.WithBatchSize(batchSize))
+			}
+
+			storage.On("GetRetentionPolicies", context.TODO()).
+				Return([]models.RetentionPolicy{tt.policy}, tt.policiesErr).
+				Once()
+
+			if tt.purge != nil {
+				for _, purged := range tt.purge.purged {
+					storage.On("PurgeDeletedProducts", context.TODO(), tt.policy.ShopID, tt.purge.before, batchSize).
+						Return(purged, tt.storageErr).
+						Once()
+				}
+			}
+
+			if tt.prune != nil {
+				for _, pruned := range tt.prune.pruned {
+					storage.On("PruneRuns", context.TODO(), tt.policy.ShopID, tt.prune.before, tt.prune.kept, batchSize).
+						Return(pruned, nil).
+						Once()
+				}
+			}
+
+			purger := retention.NewPurger(storage, &logger, ops...)
+
+			err := purger.Purge(context.TODO())
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitCloseAbandonedRuns(t *testing.T) {
+	tests := map[string]struct {
+		closed     []int
+		storageErr error
+		wantErr    error
+	}{
+		"no abandoned runs": {
+			closed: []int{0},
+		},
+		"abandoned runs closed in batches": {
+			closed: []int{2, 2, 1},
+		},
+		"storage error": {
+			closed:     []int{0},
+			storageErr: assert.AnError,
+			wantErr:    assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			logger := zerolog.Nop()
+
+			for _, closed := range tt.closed {
+				storage.On("CloseAbandonedRuns", context.TODO(), 2).Return(closed, tt.storageErr).Once()
+			}
+
+			purger := retention.NewPurger(storage, &logger, retention.WithBatchSize(2))
+
+			err := purger.CloseAbandonedRuns(context.TODO())
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitRunClosesAbandonedRunsWithSweepInterval(t *testing.T) {
+	storage := mocks.NewStorage(t)
+	logger := zerolog.Nop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// purging interval never elapses, so abandoned runs are closed only by the sweep.
+	swept := make(chan struct{}, 1)
+	storage.On("CloseAbandonedRuns", mock.Anything, retention.DefaultBatchSize).
+		Return(0, nil).
+		Run(func(_ mock.Arguments) {
+			select {
+			case swept <- struct{}{}:
+			default:
+			}
+		})
+
+	purger := retention.NewPurger(
+		storage,
+		&logger,
+		retention.WithInterval(time.Hour),
+		retention.WithSweepInterval(time.Millisecond),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		purger.Run(ctx)
+		close(done)
+	}()
+
+	<-swept
+	cancel()
+	<-done
+}
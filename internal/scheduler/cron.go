@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronYears is number of years searched for next time matching cron expression.
+const maxCronYears = 5
+
+// cronField is range of values of cron expression field.
+type cronField struct {
+	min int
+	max int
+}
+
+var cronFields = []cronField{
+	{min: 0, max: 59}, // minute
+	{min: 0, max: 23}, // hour
+	{min: 1, max: 31}, // day of month
+	{min: 1, max: 12}, // month
+	{min: 0, max: 7},  // day of week, both 0 and 7 are Sunday
+}
+
+// Cron is parsed cron expression with 5 fields: minute, hour, day of month, month and day of week.
+// Fields support `*`, values, ranges (`1-5`), steps (`*/15`, `0-30/10`) and lists of them (`0,30`).
+// Like in standard cron, if both day of month and day of week are restricted, a day matching any of them matches.
+// Cron expressions are evaluated in UTC.
+type Cron struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// anyDay is true if day of month or day of week is `*`, then both must match.
+	anyDay bool
+}
+
+// ParseCron parses cron expression. Returns ErrInvalidCron if the expression is invalid.
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return Cron{}, fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidCron, len(cronFields), len(fields))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		value, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return Cron{}, fmt.Errorf("%w: field %q: %w", ErrInvalidCron, field, err)
+		}
+
+		values[i] = value
+	}
+
+	// Sunday can be set as 7
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+
+	return Cron{
+		minute:     values[0],
+		hour:       values[1],
+		dayOfMonth: values[2],
+		month:      values[3],
+		dayOfWeek:  values[4],
+		anyDay:     strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// Next returns the first time matching the expression after provided time.
+// Returns zero time if no time matches in next years (e.g. for 30th of February).
+func (c Cron) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + maxCronYears
+
+	// every time a field doesn't match, lower fields are reset and the search is restarted when higher field changes
+WRAP:
+	for t.Year() <= yearLimit {
+		for !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue WRAP
+			}
+		}
+
+		for !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+			if t.Day() == 1 {
+				continue WRAP
+			}
+		}
+
+		for !has(c.hour, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			if t.Hour() == 0 {
+				continue WRAP
+			}
+		}
+
+		for !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue WRAP
+			}
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c Cron) matchesDay(t time.Time) bool {
+	dayOfMonth := has(c.dayOfMonth, t.Day())
+	dayOfWeek := has(c.dayOfWeek, int(t.Weekday()))
+
+	if c.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
+
+func has(set uint64, value int) bool {
+	return set&(1<<uint(value)) != 0
+}
+
+// parseCronField parses comma separated list of field's values as bit set.
+func parseCronField(field string, limits cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		value, err := parseCronRange(part, limits)
+		if err != nil {
+			return 0, err
+		}
+
+		set |= value
+	}
+
+	return set, nil
+}
+
+// parseCronRange parses single value, range or `*`, with optional step, as bit set.
+func parseCronRange(part string, limits cronField) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+
+	from, to := limits.min, limits.max
+	if rangePart != "*" {
+		fromPart, toPart, isRange := strings.Cut(rangePart, "-")
+
+		var err error
+		if from, err = parseCronValue(fromPart, limits); err != nil {
+			return 0, err
+		}
+
+		switch {
+		case isRange:
+			if to, err = parseCronValue(toPart, limits); err != nil {
+				return 0, err
+			}
+		case !hasStep:
+			// single value, value with step (e.g. `5/15`) continues to field's maximum
+			to = from
+		}
+
+		if from > to {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	}
+
+	var set uint64
+	for value := from; value <= to; value += step {
+		set |= 1 << uint(value)
+	}
+
+	return set, nil
+}
+
+func parseCronValue(value string, limits cronField) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if number < limits.min || number > limits.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", number, limits.min, limits.max)
+	}
+
+	return number, nil
+}
@@ -0,0 +1,150 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitParseCron(t *testing.T) {
+	tests := map[string]struct {
+		expr    string
+		wantErr error
+	}{
+		"every minute": {
+			expr: "* * * * *",
+		},
+		"lists, ranges and steps": {
+			expr: "0,30 8-18/2 */2 1-6 1-5",
+		},
+		"sunday as 7": {
+			expr: "0 0 * * 7",
+		},
+		"too few fields": {
+			expr:    "* * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"too many fields": {
+			expr:    "* * * * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"value out of range": {
+			expr:    "60 * * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"invalid value": {
+			expr:    "a * * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"reversed range": {
+			expr:    "* 18-8 * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"zero step": {
+			expr:    "*/0 * * * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+		"day of month out of range": {
+			expr:    "0 0 0 * *",
+			wantErr: scheduler.ErrInvalidCron,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := scheduler.ParseCron(tt.expr)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitCronNext(t *testing.T) {
+	// Monday
+	after := time.Date(2026, 10, 19, 12, 34, 56, 0, time.UTC)
+
+	tests := map[string]struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		"every minute": {
+			expr:  "* * * * *",
+			after: after,
+			want:  time.Date(2026, 10, 19, 12, 35, 0, 0, time.UTC),
+		},
+		"next matching time is strictly after": {
+			expr:  "35 12 * * *",
+			after: time.Date(2026, 10, 19, 12, 35, 0, 0, time.UTC),
+			want:  time.Date(2026, 10, 20, 12, 35, 0, 0, time.UTC),
+		},
+		"every 15 minutes": {
+			expr:  "*/15 * * * *",
+			after: after,
+			want:  time.Date(2026, 10, 19, 12, 45, 0, 0, time.UTC),
+		},
+		"next hour": {
+			expr:  "0 * * * *",
+			after: after,
+			want:  time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC),
+		},
+		"daily at night": {
+			expr:  "30 2 * * *",
+			after: after,
+			want:  time.Date(2026, 10, 20, 2, 30, 0, 0, time.UTC),
+		},
+		"next month": {
+			expr:  "0 0 1 * *",
+			after: after,
+			want:  time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"next year": {
+			expr:  "0 0 1 1 *",
+			after: after,
+			want:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"day of week": {
+			expr:  "0 6 * * 6",
+			after: after,
+			want:  time.Date(2026, 10, 24, 6, 0, 0, 0, time.UTC),
+		},
+		"sunday as 7": {
+			expr:  "0 6 * * 7",
+			after: after,
+			want:  time.Date(2026, 10, 25, 6, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expr:  "0 0 1 * 3",
+			after: after,
+			want:  time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC),
+		},
+		"leap day": {
+			expr:  "0 0 29 2 *",
+			after: after,
+			want:  time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"never matching": {
+			expr:  "0 0 30 2 *",
+			after: after,
+		},
+		"evaluated in utc": {
+			expr:  "0 13 * * *",
+			after: after.In(time.FixedZone("UTC+2", 2*60*60)),
+			want:  time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cron, err := scheduler.ParseCron(tt.expr)
+			require.NoError(t, err, "shouldn't return any error")
+
+			next := cron.Next(tt.after)
+
+			assert.Equal(t, tt.want, next, "should return correct next time")
+		})
+	}
+}
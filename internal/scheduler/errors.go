@@ -0,0 +1,10 @@
+package scheduler
+
+import "errors"
+
+var (
+	// ErrInvalidCron is returned when cron expression can't be parsed.
+	ErrInvalidCron = errors.New("invalid cron expression")
+	// ErrInvalidSchedule is returned when schedule's next run time can't be calculated.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultInterval is default interval of checking due schedules.
+	DefaultInterval = time.Minute
+	// DefaultBatchSize is default maximum number of schedules processed in one transaction.
+	DefaultBatchSize = 100
+	// invalidScheduleDelay is delay of next check of schedule which next run time can't be calculated.
+	invalidScheduleDelay = time.Hour
+)
+
+//go:generate mockery --name Storage --filename storage.go
+
+// Storage stores shops parsing schedules.
+type Storage interface {
+	// ProcessDueSchedules passes up to limit due schedules to process function
+	// and sets their next run time to returned time. Returns number of processed schedules.
+	ProcessDueSchedules(
+		ctx context.Context,
+		now time.Time,
+		limit int,
+		process func(ctx context.Context, schedule models.ShopSchedule) (time.Time, error),
+	) (int, error)
+}
+
+// Scheduler periodically sends parse commands of shops which schedules are due.
+// Shops which run is still running are skipped until their next scheduled time.
+// Due schedules are locked in storage, so several schedulers can run at the same time.
+type Scheduler struct {
+	storage   Storage
+	commander commander.ParseCommander
+	logger    *zerolog.Logger
+	interval  time.Duration
+	batchSize int
+	jitter    time.Duration
+	now       func() time.Time
+}
+
+// Option is Scheduler's option.
+type Option func(s *Scheduler)
+
+// NewScheduler returns new Scheduler sending parse commands of shops scheduled in storage with provided commander.
+func NewScheduler(storage Storage, parseCommander commander.ParseCommander, logger *zerolog.Logger, ops ...Option) *Scheduler {
+	scheduler := &Scheduler{
+		storage:   storage,
+		commander: parseCommander,
+		logger:    logger,
+		interval:  DefaultInterval,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+
+	for _, op := range ops {
+		op(scheduler)
+	}
+
+	return scheduler
+}
+
+// WithInterval sets interval of checking due schedules.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = interval
+	}
+}
+
+// WithBatchSize sets maximum number of schedules processed in one transaction.
+func WithBatchSize(size int) Option {
+	return func(s *Scheduler) {
+		s.batchSize = size
+	}
+}
+
+// WithJitter sets maximum random delay added to scheduled times, so shops scheduled at the same time
+// aren't parsed all at once. It should be shorter than shops schedules.
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
+
+// WithClock sets function returning current time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// Run sends parse commands of due schedules until context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Schedule(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error().
+					Err(err).
+					Msg("can't send scheduled parse commands")
+			}
+		}
+	}
+}
+
+// Schedule sends parse commands of all due schedules, batch by batch.
+// Schedule which next run time isn't calculated yet gets it calculated without sending the command.
+func (s *Scheduler) Schedule(ctx context.Context) error {
+	for {
+		processed, err := s.storage.ProcessDueSchedules(ctx, s.now(), s.batchSize, s.process)
+		if err != nil {
+			return err
+		}
+
+		if processed < s.batchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Scheduler) process(ctx context.Context, schedule models.ShopSchedule) (time.Time, error) {
+	now := s.now()
+
+	next, err := NextRunAt(schedule, now)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Int("shopId", schedule.ShopID).
+			Msg("can't calculate next scheduled parsing")
+
+		return now.Add(invalidScheduleDelay), nil
+	}
+
+	if s.jitter > 0 {
+		next = next.Add(rand.N(s.jitter))
+	}
+
+	// new schedule isn't due yet, only its next run time is set
+	if schedule.NextRunAt == nil {
+		return next, nil
+	}
+
+	if schedule.Running {
+		s.logger.Info().
+			Int("shopId", schedule.ShopID).
+			Time("nextRunAt", next).
+			Msg("scheduled parsing skipped, shop's run is still running")
+
+		return next, nil
+	}
+
+	if err = s.commander.SendParseCommand(ctx, schedule.ShopURL); err != nil {
+		return time.Time{}, fmt.Errorf("can't send parse command of shop %d: %w", schedule.ShopID, err)
+	}
+
+	s.logger.Info().
+		Int("shopId", schedule.ShopID).
+		Time("nextRunAt", next).
+		Msg("scheduled parse command sent")
+
+	return next, nil
+}
+
+// NextRunAt returns the first time after provided time scheduled by shop's cron expression, or its interval
+// if cron expression isn't set. Returns ErrInvalidSchedule or ErrInvalidCron if the schedule is invalid.
+func NextRunAt(schedule models.ShopSchedule, after time.Time) (time.Time, error) {
+	if schedule.Cron != nil {
+		cron, err := ParseCron(*schedule.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		next := cron.Next(after)
+		if next.IsZero() {
+			return time.Time{}, fmt.Errorf("%w: cron expression %q never matches", ErrInvalidSchedule, *schedule.Cron)
+		}
+
+		return next, nil
+	}
+
+	if schedule.Interval == nil || *schedule.Interval <= 0 {
+		return time.Time{}, fmt.Errorf("%w: neither cron expression nor positive interval is set", ErrInvalidSchedule)
+	}
+
+	return after.Add(*schedule.Interval), nil
+}
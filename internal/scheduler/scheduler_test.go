@@ -0,0 +1,174 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MichalMitros/google-feed-parser/internal/platform/models"
+	"github.com/MichalMitros/google-feed-parser/internal/scheduler"
+	"github.com/MichalMitros/google-feed-parser/internal/scheduler/mocks"
+	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	cmdmocks "github.com/MichalMitros/google-feed-parser/pkg/v1/commander/mocks"
+)
+
+func TestUnitSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	due := lo.ToPtr(now.Add(-time.Minute))
+
+	tests := map[string]struct {
+		schedule      models.ShopSchedule
+		jitter        time.Duration
+		sent          bool
+		sendErr       error
+		wantNextRunAt time.Time
+		wantErr       error
+	}{
+		"due interval schedule": {
+			schedule:      models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Interval: lo.ToPtr(time.Hour), NextRunAt: due},
+			sent:          true,
+			wantNextRunAt: now.Add(time.Hour),
+		},
+		"due cron schedule": {
+			schedule:      models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Cron: lo.ToPtr("30 2 * * *"), NextRunAt: due},
+			sent:          true,
+			wantNextRunAt: time.Date(2026, 10, 20, 2, 30, 0, 0, time.UTC),
+		},
+		"cron overrides interval": {
+			schedule: models.ShopSchedule{
+				ShopID:    1,
+				ShopURL:   "shop1",
+				Cron:      lo.ToPtr("0 * * * *"),
+				Interval:  lo.ToPtr(24 * time.Hour),
+				NextRunAt: due,
+			},
+			sent:          true,
+			wantNextRunAt: now.Add(time.Hour),
+		},
+		"new schedule isn't sent": {
+			schedule:      models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Interval: lo.ToPtr(time.Hour)},
+			wantNextRunAt: now.Add(time.Hour),
+		},
+		"running shop is skipped": {
+			schedule: models.ShopSchedule{
+				ShopID:    1,
+				ShopURL:   "shop1",
+				Interval:  lo.ToPtr(time.Hour),
+				NextRunAt: due,
+				Running:   true,
+			},
+			wantNextRunAt: now.Add(time.Hour),
+		},
+		"invalid cron delays schedule": {
+			schedule:      models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Cron: lo.ToPtr("invalid"), NextRunAt: due},
+			wantNextRunAt: now.Add(time.Hour),
+		},
+		"send error": {
+			schedule: models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Interval: lo.ToPtr(time.Hour), NextRunAt: due},
+			sent:     true,
+			sendErr:  assert.AnError,
+			wantErr:  assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			sender := cmdmocks.NewSender(t)
+			logger := zerolog.Nop()
+
+			var nextRunAt time.Time
+			storage.On("ProcessDueSchedules", mock.Anything, now, scheduler.DefaultBatchSize, mock.Anything).
+				Return(func(
+					ctx context.Context,
+					_ time.Time,
+					_ int,
+					process func(context.Context, models.ShopSchedule) (time.Time, error),
+				) (int, error) {
+					var err error
+					if nextRunAt, err = process(ctx, tt.schedule); err != nil {
+						return 0, err
+					}
+					return 1, nil
+				}).
+				Once()
+
+			if tt.sent {
+				sender.On("Send", mock.Anything, []byte(`{"shopUrl":"`+tt.schedule.ShopURL+`"}`)).
+					Return(tt.sendErr).
+					Once()
+			}
+
+			sch := scheduler.NewScheduler(
+				storage,
+				commander.NewParseCommander(sender),
+				&logger,
+				scheduler.WithClock(func() time.Time { return now }),
+			)
+
+			err := sch.Schedule(context.TODO())
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+			assert.Equal(t, tt.wantNextRunAt, nextRunAt, "should return correct next run time")
+		})
+	}
+}
+
+func TestUnitScheduleJitter(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	jitter := 10 * time.Minute
+	schedule := models.ShopSchedule{ShopID: 1, ShopURL: "shop1", Interval: lo.ToPtr(time.Hour), NextRunAt: &now}
+
+	storage := mocks.NewStorage(t)
+	sender := cmdmocks.NewSender(t)
+	logger := zerolog.Nop()
+
+	var nextRunAt time.Time
+	storage.On("ProcessDueSchedules", mock.Anything, now, scheduler.DefaultBatchSize, mock.Anything).
+		Return(func(
+			ctx context.Context,
+			_ time.Time,
+			_ int,
+			process func(context.Context, models.ShopSchedule) (time.Time, error),
+		) (int, error) {
+			var err error
+			nextRunAt, err = process(ctx, schedule)
+			return 1, err
+		})
+	sender.On("Send", mock.Anything, mock.Anything).Return(nil)
+
+	sch := scheduler.NewScheduler(
+		storage,
+		commander.NewParseCommander(sender),
+		&logger,
+		scheduler.WithClock(func() time.Time { return now }),
+		scheduler.WithJitter(jitter),
+	)
+
+	err := sch.Schedule(context.TODO())
+
+	require.NoError(t, err, "shouldn't return any error")
+	assert.False(t, nextRunAt.Before(now.Add(time.Hour)), "shouldn't be earlier than scheduled time")
+	assert.True(t, nextRunAt.Before(now.Add(time.Hour+jitter)), "should be delayed less than jitter")
+}
+
+func TestUnitScheduleBatches(t *testing.T) {
+	storage := mocks.NewStorage(t)
+	sender := cmdmocks.NewSender(t)
+	logger := zerolog.Nop()
+
+	storage.On("ProcessDueSchedules", mock.Anything, mock.Anything, 2, mock.Anything).Return(2, nil).Twice()
+	storage.On("ProcessDueSchedules", mock.Anything, mock.Anything, 2, mock.Anything).Return(1, nil).Once()
+
+	sch := scheduler.NewScheduler(storage, commander.NewParseCommander(sender), &logger, scheduler.WithBatchSize(2))
+
+	err := sch.Schedule(context.TODO())
+
+	require.NoError(t, err, "shouldn't return any error")
+}
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Supplemental feeds of shops
+CREATE TABLE supplemental_feed (
+    id          SERIAL PRIMARY KEY,
+    shop_id     INT REFERENCES shop (id) NOT NULL,
+    url         VARCHAR NOT NULL,
+    position    INT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT (now()),
+
+    CONSTRAINT unique_supplemental_feed_shop_url UNIQUE ( shop_id, url )
+);
+
+COMMENT ON TABLE supplemental_feed IS 'Supplemental feeds overriding attributes of products from shop primary feed';
+COMMENT ON COLUMN supplemental_feed.url IS 'Feed url of the supplemental feed';
+COMMENT ON COLUMN supplemental_feed.position IS 'Order in which supplemental feeds are applied, later feeds override earlier ones';
+COMMENT ON COLUMN shop.url IS 'Primary feed url of the shop';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+COMMENT ON COLUMN shop.url IS 'Feed url of the shop';
+
+DROP TABLE supplemental_feed;
+
+-- +goose StatementEnd
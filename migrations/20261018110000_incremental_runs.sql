@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE run ADD COLUMN incremental BOOL NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN run.incremental IS 'True if run parsed incremental feed, which deletes only products marked as removed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE run DROP COLUMN incremental;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE run ADD COLUMN status VARCHAR NOT NULL DEFAULT 'running';
+
+UPDATE run SET status = CASE
+    WHEN success IS NULL THEN 'running'
+    WHEN success THEN 'succeeded'
+    ELSE 'failed'
+END;
+
+COMMENT ON COLUMN run.status IS 'Status of the run: running, succeeded, failed or suspicious_shrink if deletion of outdated products was skipped';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE run DROP COLUMN status;
+
+-- +goose StatementEnd
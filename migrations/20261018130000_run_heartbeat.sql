@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE run ADD COLUMN heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT (now());
+
+UPDATE run SET heartbeat_at = COALESCE(finished_at, created_at);
+
+COMMENT ON COLUMN run.heartbeat_at IS 'Time of the last lease renewal of the run, running run without renewal for too long is considered abandoned';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE run DROP COLUMN heartbeat_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+COMMENT ON COLUMN run.status IS 'Status of the run: running, succeeded, failed, cancelled or suspicious_shrink if deletion of outdated products was skipped';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+COMMENT ON COLUMN run.status IS 'Status of the run: running, succeeded, failed or suspicious_shrink if deletion of outdated products was skipped';
+
+-- +goose StatementEnd
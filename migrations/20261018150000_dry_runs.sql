@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE run ADD COLUMN dry_run BOOL NOT NULL DEFAULT false;
+ALTER TABLE run ADD COLUMN unchanged_products INT;
+ALTER TABLE run ADD COLUMN changes_sample JSONB;
+
+COMMENT ON COLUMN run.dry_run IS 'True if run only compared feed with stored products without writing them';
+COMMENT ON COLUMN run.unchanged_products IS 'Number of parsed products which did not change since previous run';
+COMMENT ON COLUMN run.changes_sample IS 'Sample of product changes found by dry run';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE run DROP COLUMN changes_sample;
+ALTER TABLE run DROP COLUMN unchanged_products;
+ALTER TABLE run DROP COLUMN dry_run;
+
+-- +goose StatementEnd
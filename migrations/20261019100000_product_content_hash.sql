@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE product ADD COLUMN content_hash VARCHAR;
+
+COMMENT ON COLUMN product.content_hash IS 'Hash of product attributes and shippings, used to skip writes of unchanged products';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE product DROP COLUMN content_hash;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Transactional outbox of events published to RabbitMQ
+CREATE TABLE outbox (
+    id          BIGSERIAL PRIMARY KEY,
+    event_type  VARCHAR NOT NULL,
+    payload     JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE outbox IS 'Events saved in the same transactions as changes they describe, removed after publishing to RabbitMQ';
+COMMENT ON COLUMN outbox.event_type IS 'Type of the event, e.g. product.created, product.updated, product.deleted';
+COMMENT ON COLUMN outbox.payload IS 'JSON message published to RabbitMQ';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE outbox;
+
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Unlogged staging tables for bulk loading of products with COPY
+CREATE UNLOGGED TABLE product_staging (
+    shop_id     INT NOT NULL,
+    version     BIGINT NOT NULL,
+    product_id  VARCHAR NOT NULL,
+    title       VARCHAR NOT NULL,
+    description VARCHAR NOT NULL,
+    url         VARCHAR NOT NULL,
+    img_url     VARCHAR NOT NULL,
+    additional_img_urls     VARCHAR NOT NULL,
+    condition   VARCHAR NOT NULL,
+    availability    VARCHAR NOT NULL,
+    price       VARCHAR NOT NULL,
+    brand       VARCHAR,
+    gtin        VARCHAR,
+    mpn         VARCHAR,
+    product_category VARCHAR,
+    product_type VARCHAR,
+    color       VARCHAR,
+    size        VARCHAR,
+    item_group_id   VARCHAR,
+    gender      VARCHAR,
+    age_group   VARCHAR,
+    content_hash    VARCHAR,
+    deleted_at  TIMESTAMPTZ
+);
+
+COMMENT ON TABLE product_staging IS 'Products copied in bulk before merging into product table, rows never outlive loading transaction';
+
+CREATE UNLOGGED TABLE shipping_staging (
+    product_id  VARCHAR NOT NULL,
+    position    INT NOT NULL,
+
+    country     VARCHAR NOT NULL,
+    service     VARCHAR NOT NULL,
+    price       VARCHAR NOT NULL
+);
+
+COMMENT ON TABLE shipping_staging IS 'Shippings copied in bulk before merging into shipping table, rows never outlive loading transaction';
+COMMENT ON COLUMN shipping_staging.product_id IS 'Product ID from feed';
+COMMENT ON COLUMN shipping_staging.position IS 'Position of shipping in loaded batch, used to keep shippings order';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE shipping_staging;
+DROP TABLE product_staging;
+
+-- +goose StatementEnd
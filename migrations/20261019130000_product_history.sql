@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- History of products updates and deletions
+CREATE TABLE product_history (
+    id          BIGSERIAL PRIMARY KEY,
+    product_id  INT REFERENCES product (id) NOT NULL,
+    run_id      INT REFERENCES run (id) ON DELETE SET NULL,
+    version     BIGINT NOT NULL,
+    change_type VARCHAR NOT NULL,
+    changes     JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE product_history IS 'History of products updates and deletions';
+COMMENT ON COLUMN product_history.run_id IS 'ID of the run which changed the product';
+COMMENT ON COLUMN product_history.version IS 'Version of updated product or last version of deleted product';
+COMMENT ON COLUMN product_history.change_type IS 'Type of the change, updated or deleted';
+COMMENT ON COLUMN product_history.changes IS 'Changed attributes with their old and new values';
+
+CREATE INDEX ix_product_history_product_id ON product_history (product_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_product_history_product_id;
+
+DROP TABLE product_history;
+
+-- +goose StatementEnd
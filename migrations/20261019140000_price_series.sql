@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE product ADD COLUMN sale_price VARCHAR;
+ALTER TABLE product_staging ADD COLUMN sale_price VARCHAR;
+
+-- Time series of products prices and availability
+CREATE TABLE price_series (
+    id          BIGSERIAL PRIMARY KEY,
+    product_id  INT REFERENCES product (id) NOT NULL,
+    run_id      INT REFERENCES run (id) ON DELETE SET NULL,
+    price       NUMERIC,
+    currency    VARCHAR,
+    sale_price  NUMERIC,
+    availability    VARCHAR NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE price_series IS 'Time series of products prices and availability, new point is saved only when any value changes';
+COMMENT ON COLUMN price_series.run_id IS 'ID of the run which changed the product';
+COMMENT ON COLUMN price_series.price IS 'Amount of product price, null if it cannot be parsed';
+COMMENT ON COLUMN price_series.sale_price IS 'Amount of product sale price, null if not set or it cannot be parsed';
+
+CREATE INDEX ix_price_series_product_id ON price_series (product_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_price_series_product_id;
+
+DROP TABLE price_series;
+
+ALTER TABLE product_staging DROP COLUMN sale_price;
+ALTER TABLE product DROP COLUMN sale_price;
+
+-- +goose StatementEnd
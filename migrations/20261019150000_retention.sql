@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE shop ADD COLUMN deleted_products_retention_days INT;
+ALTER TABLE shop ADD COLUMN runs_retention_days INT;
+ALTER TABLE shop ADD COLUMN kept_runs INT;
+
+COMMENT ON COLUMN shop.deleted_products_retention_days IS 'Days after which soft-deleted products are purged, overrides default retention if set';
+COMMENT ON COLUMN shop.runs_retention_days IS 'Days after which runs are pruned, overrides default retention if set';
+COMMENT ON COLUMN shop.kept_runs IS 'Number of the newest runs never pruned, overrides default number if set';
+
+-- speeds up finding purged products and nulling run references of pruned runs.
+CREATE INDEX ix_product_shop_id_deleted_at ON product (shop_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX ix_product_history_run_id ON product_history (run_id);
+CREATE INDEX ix_price_series_run_id ON price_series (run_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_price_series_run_id;
+DROP INDEX ix_product_history_run_id;
+DROP INDEX ix_product_shop_id_deleted_at;
+
+ALTER TABLE shop DROP COLUMN kept_runs;
+ALTER TABLE shop DROP COLUMN runs_retention_days;
+ALTER TABLE shop DROP COLUMN deleted_products_retention_days;
+
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE shop ADD COLUMN name VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE shop ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE shop ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN shop.name IS 'Display name of the shop';
+COMMENT ON COLUMN shop.disabled IS 'Parsing of disabled shop is rejected';
+COMMENT ON COLUMN shop.deleted_at IS 'Time of shop soft deletion, parsing of deleted shop is rejected';
+
+-- Previous feed urls of shops
+CREATE TABLE shop_url_history (
+    id          SERIAL PRIMARY KEY,
+    shop_id     INT REFERENCES shop (id) NOT NULL,
+    url         VARCHAR NOT NULL,
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE shop_url_history IS 'Previous feed urls of shops';
+COMMENT ON COLUMN shop_url_history.url IS 'Feed url of the shop before the change';
+COMMENT ON COLUMN shop_url_history.changed_at IS 'Time when the url was replaced';
+
+CREATE INDEX ix_shop_url_history_shop_id ON shop_url_history (shop_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_shop_url_history_shop_id;
+
+DROP TABLE shop_url_history;
+
+ALTER TABLE shop DROP COLUMN deleted_at;
+ALTER TABLE shop DROP COLUMN disabled;
+ALTER TABLE shop DROP COLUMN name;
+
+-- +goose StatementEnd
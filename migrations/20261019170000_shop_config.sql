@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Shops parsing configuration
+CREATE TABLE shop_config (
+    shop_id                 INT PRIMARY KEY REFERENCES shop (id),
+    batch_size              INT,
+    storage_workers         INT,
+    parse_timeout_seconds   INT,
+    decoder                 VARCHAR,
+    removal_availabilities  VARCHAR,
+    max_deletion_percent    DOUBLE PRECISION,
+    max_failed_products     INT,
+    max_failure_ratio       DOUBLE PRECISION,
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT (now())
+);
+
+COMMENT ON TABLE shop_config IS 'Shops parsing configuration, null values are not set and default configuration is used';
+COMMENT ON COLUMN shop_config.parse_timeout_seconds IS 'Maximum duration of shop parsing, 0 disables the timeout';
+COMMENT ON COLUMN shop_config.decoder IS 'Name of decoder used to decode shop feeds';
+COMMENT ON COLUMN shop_config.removal_availabilities IS 'New line separated availability values marking products as removed in incremental feeds';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE shop_config;
+
+-- +goose StatementEnd
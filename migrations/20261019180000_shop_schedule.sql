@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Shops parsing schedules
+CREATE TABLE shop_schedule (
+    shop_id             INT PRIMARY KEY REFERENCES shop (id),
+    cron                VARCHAR,
+    interval_seconds    INT,
+    next_run_at         TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT (now()),
+    CHECK (cron IS NOT NULL OR interval_seconds IS NOT NULL)
+);
+
+CREATE INDEX ix_shop_schedule_next_run_at ON shop_schedule (next_run_at);
+
+COMMENT ON TABLE shop_schedule IS 'Shops parsing schedules, parse commands are sent by scheduler when schedule is due';
+COMMENT ON COLUMN shop_schedule.cron IS 'Cron expression of parsing times, used instead of interval if set';
+COMMENT ON COLUMN shop_schedule.next_run_at IS 'Time of next parse command, null if it is not calculated yet';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE shop_schedule;
+
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE product ADD COLUMN price_amount DOUBLE PRECISION;
+ALTER TABLE product ADD COLUMN changed_version BIGINT;
+ALTER TABLE product_staging ADD COLUMN price_amount DOUBLE PRECISION;
+ALTER TABLE product_staging ADD COLUMN changed_version BIGINT;
+
+COMMENT ON COLUMN product.price_amount IS 'Amount of product price, null if the price can not be parsed';
+COMMENT ON COLUMN product.changed_version IS 'Products version of the run which created, updated or deleted the product, not changed by runs which only parsed the product';
+
+UPDATE product
+SET price_amount = CASE
+        WHEN split_part(btrim(price), ' ', 1) ~ '^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)$'
+            THEN split_part(btrim(price), ' ', 1)::DOUBLE PRECISION
+    END,
+    changed_version = version;
+
+-- speeds up filtering shop's products changed since a version.
+CREATE INDEX ix_product_shop_id_changed_version ON product (shop_id, changed_version);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_product_shop_id_changed_version;
+
+ALTER TABLE product_staging DROP COLUMN changed_version;
+ALTER TABLE product_staging DROP COLUMN price_amount;
+ALTER TABLE product DROP COLUMN changed_version;
+ALTER TABLE product DROP COLUMN price_amount;
+
+-- +goose StatementEnd
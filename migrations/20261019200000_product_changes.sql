@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE product ADD COLUMN created_version BIGINT;
+ALTER TABLE product_staging ADD COLUMN created_version BIGINT;
+
+COMMENT ON COLUMN product.created_version IS 'Products version of the run which created the product, null for products created before it was stored';
+
+-- speeds up reading shop's product changes in order of changed version and ID.
+DROP INDEX ix_product_shop_id_changed_version;
+CREATE INDEX ix_product_shop_id_changed_version_id ON product (shop_id, changed_version, id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_product_shop_id_changed_version_id;
+CREATE INDEX ix_product_shop_id_changed_version ON product (shop_id, changed_version);
+
+ALTER TABLE product_staging DROP COLUMN created_version;
+ALTER TABLE product DROP COLUMN created_version;
+
+-- +goose StatementEnd
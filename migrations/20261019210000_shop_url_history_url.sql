@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Parsing of previous url is rejected, so the url history is searched by url
+CREATE INDEX ix_shop_url_history_url ON shop_url_history (url);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX ix_shop_url_history_url;
+
+-- +goose StatementEnd
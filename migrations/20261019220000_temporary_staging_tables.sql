@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Staging tables only define columns of temporary staging tables created by each loading transaction
+COMMENT ON TABLE product_staging IS 'Definition of temporary product staging tables dropped on commit of loading transaction, never holds rows';
+COMMENT ON TABLE shipping_staging IS 'Definition of temporary shipping staging tables dropped on commit of loading transaction, never holds rows';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+COMMENT ON TABLE product_staging IS 'Products copied in bulk before merging into product table, rows never outlive loading transaction';
+COMMENT ON TABLE shipping_staging IS 'Shippings copied in bulk before merging into shipping table, rows never outlive loading transaction';
+
+-- +goose StatementEnd
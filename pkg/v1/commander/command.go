@@ -3,4 +3,77 @@ package commander
 // ParseCommand is command sent to Parser service.
 type ParseCommand struct {
 	ShopURL string `json:"shopUrl"`
+	// SupplementalFeedURLs replaces shop's supplemental feeds if not nil, empty list removes all of them.
+	// Supplemental feeds override attributes of products from shop's primary feed by product ID.
+	SupplementalFeedURLs *[]string `json:"supplementalFeedUrls,omitempty"`
+	// Incremental enables incremental mode, in which feed contains only changed products
+	// and only products marked as removed (by availability or RemovedProductIDs) are deleted.
+	Incremental bool `json:"incremental,omitempty"`
+	// RemovedProductIDs are IDs of products deleted in incremental mode.
+	RemovedProductIDs []string `json:"removedProductIds,omitempty"`
+	// ForceDeletion approves deletion of outdated products even if feed shrank suspiciously.
+	ForceDeletion bool `json:"forceDeletion,omitempty"`
+	// DryRun enables dry-run mode, in which feed is only compared with stored products and nothing is written
+	// except the run with its statistics and sample of changes.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+const (
+	// ParseSkipped is status of parse command skipped because shop's run was already running.
+	ParseSkipped = "skipped"
+	// ParseRejected is status of parse command rejected because the shop is disabled or deleted.
+	ParseRejected = "rejected"
+)
+
+// ParseReply is Parser service's reply to parse command sent with ParseCommander.SendParseCommandAndWait.
+type ParseReply struct {
+	// Status is final status of the run (succeeded, failed, suspicious_shrink or cancelled),
+	// ParseSkipped if shop's run was already running, ParseRejected if the shop is disabled or deleted,
+	// or failed if the run couldn't be started.
+	Status        string  `json:"status"`
+	StatusMessage *string `json:"statusMessage,omitempty"`
+	// RunID is ID of the finished run, it's not set if the run wasn't started.
+	RunID int `json:"runId,omitempty"`
+}
+
+// CancelCommand is command cancelling running run, sent to Parser service.
+type CancelCommand struct {
+	RunID int `json:"runId"`
+}
+
+// CommandOption is custom configuration of ParseCommand.
+type CommandOption func(cmd *ParseCommand)
+
+// WithSupplementalFeeds sets shop's supplemental feeds, feeds later on the list override earlier ones.
+// Without any feed urls, shop's supplemental feeds are removed.
+func WithSupplementalFeeds(feedURLs ...string) CommandOption {
+	if feedURLs == nil {
+		feedURLs = []string{}
+	}
+
+	return func(cmd *ParseCommand) {
+		cmd.SupplementalFeedURLs = &feedURLs
+	}
+}
+
+// WithIncrementalMode enables incremental mode, which upserts products and deletes only removed products.
+func WithIncrementalMode(removedProductIDs ...string) CommandOption {
+	return func(cmd *ParseCommand) {
+		cmd.Incremental = true
+		cmd.RemovedProductIDs = removedProductIDs
+	}
+}
+
+// WithForcedDeletion approves deletion of outdated products skipped by previous run because of suspicious feed shrink.
+func WithForcedDeletion() CommandOption {
+	return func(cmd *ParseCommand) {
+		cmd.ForceDeletion = true
+	}
+}
+
+// WithDryRun enables dry-run mode, which only reports how many products would be created, updated and deleted.
+func WithDryRun() CommandOption {
+	return func(cmd *ParseCommand) {
+		cmd.DryRun = true
+	}
 }
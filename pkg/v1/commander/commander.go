@@ -13,6 +13,14 @@ type Sender interface {
 	Send(context.Context, []byte) error
 }
 
+//go:generate mockery --name Requester --filename requester.go
+
+// Requester sends messages and waits for replies.
+// Senders implementing Requester can be used for awaiting results of parse commands.
+type Requester interface {
+	Request(context.Context, []byte) ([]byte, error)
+}
+
 // ParseCommander sends parse commands.
 type ParseCommander struct {
 	sender Sender
@@ -26,14 +34,84 @@ func NewParseCommander(sender Sender) ParseCommander {
 }
 
 // SendParseCommand sends parse command with provided shopURL.
-func (c ParseCommander) SendParseCommand(ctx context.Context, shopURL string) error {
+func (c ParseCommander) SendParseCommand(ctx context.Context, shopURL string, ops ...CommandOption) error {
+	cmdMsg, err := parseCommandMessage(shopURL, ops...)
+	if err != nil {
+		return err
+	}
+
+	return c.sender.Send(ctx, cmdMsg)
+}
+
+// SendParseCommandAndWait sends parse command with provided shopURL and blocks until Parser service replies
+// with the run's final status or context is done.
+// It returns ErrRepliesNotSupported if commander's sender doesn't implement Requester.
+func (c ParseCommander) SendParseCommandAndWait(
+	ctx context.Context,
+	shopURL string,
+	ops ...CommandOption,
+) (*ParseReply, error) {
+	requester, ok := c.sender.(Requester)
+	if !ok {
+		return nil, ErrRepliesNotSupported
+	}
+
+	cmdMsg, err := parseCommandMessage(shopURL, ops...)
+	if err != nil {
+		return nil, err
+	}
+
+	replyMsg, err := requester.Request(ctx, cmdMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply ParseReply
+	if err := json.Unmarshal(replyMsg, &reply); err != nil {
+		return nil, fmt.Errorf("can't unmarshal parse reply: %w", err)
+	}
+
+	return &reply, nil
+}
+
+func parseCommandMessage(shopURL string, ops ...CommandOption) ([]byte, error) {
 	cmd := ParseCommand{
 		ShopURL: shopURL,
 	}
 
+	for _, op := range ops {
+		op(&cmd)
+	}
+
 	cmdMsg, err := json.Marshal(cmd)
 	if err != nil {
-		return fmt.Errorf("can't marshal parse command: %w", err)
+		return nil, fmt.Errorf("can't marshal parse command: %w", err)
+	}
+
+	return cmdMsg, nil
+}
+
+// CancelCommander sends cancel commands.
+// Cancel commands have to be sent to Parser service's cancel routing key, not to parse commands one.
+type CancelCommander struct {
+	sender Sender
+}
+
+// NewCancelCommander returns new CancelCommander using provided sender for sending messages.
+func NewCancelCommander(sender Sender) CancelCommander {
+	return CancelCommander{
+		sender: sender,
+	}
+}
+
+// SendCancelCommand sends command cancelling running run with provided runID.
+// Cancelled run is finished with cancelled status and no products are deleted.
+func (c CancelCommander) SendCancelCommand(ctx context.Context, runID int) error {
+	cmdMsg, err := json.Marshal(CancelCommand{
+		RunID: runID,
+	})
+	if err != nil {
+		return fmt.Errorf("can't marshal cancel command: %w", err)
 	}
 
 	return c.sender.Send(ctx, cmdMsg)
@@ -8,6 +8,7 @@ import (
 	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander"
 	"github.com/MichalMitros/google-feed-parser/pkg/v1/commander/mocks"
 	"github.com/go-faker/faker/v4"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -40,3 +41,159 @@ func TestUniSendParseCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitSendParseCommandWithSupplementalFeeds(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s","supplementalFeedUrls":["first","second"]}`, shopURL))
+
+	sender := mocks.NewSender(t)
+	sender.On("Send", mock.Anything, body).Return(nil)
+
+	cmndr := commander.NewParseCommander(sender)
+	err := cmndr.SendParseCommand(context.TODO(), shopURL, commander.WithSupplementalFeeds("first", "second"))
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitSendParseCommandClearingSupplementalFeeds(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s","supplementalFeedUrls":[]}`, shopURL))
+
+	sender := mocks.NewSender(t)
+	sender.On("Send", mock.Anything, body).Return(nil)
+
+	cmndr := commander.NewParseCommander(sender)
+	err := cmndr.SendParseCommand(context.TODO(), shopURL, commander.WithSupplementalFeeds())
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitSendParseCommandWithIncrementalMode(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s","incremental":true,"removedProductIds":["1","2"]}`, shopURL))
+
+	sender := mocks.NewSender(t)
+	sender.On("Send", mock.Anything, body).Return(nil)
+
+	cmndr := commander.NewParseCommander(sender)
+	err := cmndr.SendParseCommand(context.TODO(), shopURL, commander.WithIncrementalMode("1", "2"))
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitSendParseCommandWithForcedDeletion(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s","forceDeletion":true}`, shopURL))
+
+	sender := mocks.NewSender(t)
+	sender.On("Send", mock.Anything, body).Return(nil)
+
+	cmndr := commander.NewParseCommander(sender)
+	err := cmndr.SendParseCommand(context.TODO(), shopURL, commander.WithForcedDeletion())
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitSendParseCommandWithDryRun(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s","dryRun":true}`, shopURL))
+
+	sender := mocks.NewSender(t)
+	sender.On("Send", mock.Anything, body).Return(nil)
+
+	cmndr := commander.NewParseCommander(sender)
+	err := cmndr.SendParseCommand(context.TODO(), shopURL, commander.WithDryRun())
+
+	require.NoError(t, err, "shouldn't return any error")
+}
+
+func TestUnitSendCancelCommand(t *testing.T) {
+	runID := 12
+	body := []byte(`{"runId":12}`)
+
+	tests := map[string]struct {
+		senderError error
+		wantErr     error
+	}{
+		"ok": {},
+		"sender error": {
+			senderError: assert.AnError,
+			wantErr:     assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sender := mocks.NewSender(t)
+			sender.On("Send", mock.Anything, body).Return(tt.senderError)
+
+			cmndr := commander.NewCancelCommander(sender)
+			err := cmndr.SendCancelCommand(context.TODO(), runID)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+		})
+	}
+}
+
+func TestUnitSendParseCommandAndWait(t *testing.T) {
+	shopURL := faker.Word()
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s"}`, shopURL))
+	routingKey := faker.Word()
+
+	tests := map[string]struct {
+		reply        []byte
+		requestError error
+		wantReply    *commander.ParseReply
+		wantErr      bool
+	}{
+		"ok": {
+			reply: []byte(`{"status":"succeeded","runId":12}`),
+			wantReply: &commander.ParseReply{
+				Status: "succeeded",
+				RunID:  12,
+			},
+		},
+		"skipped": {
+			reply: []byte(`{"status":"skipped","statusMessage":"parsing already running for this shop"}`),
+			wantReply: &commander.ParseReply{
+				Status:        commander.ParseSkipped,
+				StatusMessage: lo.ToPtr("parsing already running for this shop"),
+			},
+		},
+		"request error": {
+			requestError: assert.AnError,
+			wantErr:      true,
+		},
+		"invalid reply": {
+			reply:   []byte(`{`),
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			publisher := mocks.NewRabbitMQRequester(t)
+			publisher.On("Request", mock.Anything, routingKey, body).Return(tt.reply, tt.requestError)
+
+			cmndr := commander.NewParseCommander(commander.NewRabbitMQSender(publisher, routingKey))
+			reply, err := cmndr.SendParseCommandAndWait(context.TODO(), shopURL)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+			} else {
+				require.NoError(t, err, "shouldn't return any error")
+			}
+			assert.Equal(t, tt.wantReply, reply, "should return correct reply")
+		})
+	}
+}
+
+func TestUnitSendParseCommandAndWaitWithoutRequester(t *testing.T) {
+	sender := mocks.NewSender(t)
+
+	cmndr := commander.NewParseCommander(sender)
+	reply, err := cmndr.SendParseCommandAndWait(context.TODO(), faker.Word())
+
+	require.ErrorIs(t, err, commander.ErrRepliesNotSupported, "should return correct error")
+	assert.Nil(t, reply, "shouldn't return reply")
+}
@@ -0,0 +1,6 @@
+package commander
+
+import "errors"
+
+// ErrRepliesNotSupported is returned when command is sent and awaited with sender which can't receive replies.
+var ErrRepliesNotSupported = errors.New("sender doesn't support replies")
@@ -0,0 +1,61 @@
+package commander
+
+import "time"
+
+// Types of product events published by Parser service, used also as suffixes of events routing keys.
+const (
+	ProductCreated = "product.created"
+	ProductUpdated = "product.updated"
+	ProductDeleted = "product.deleted"
+)
+
+// ProductEvent is event published by Parser service for every product change made by a run.
+type ProductEvent struct {
+	Type      string `json:"type"`
+	ShopID    int    `json:"shopId"`
+	ProductID string `json:"productId"`
+	// Version is product's version after the change, deleted products keep version of the last run which parsed them.
+	Version int64 `json:"version"`
+	// ChangedFields are names of changed product attributes, set only for updated products.
+	ChangedFields []string `json:"changedFields,omitempty"`
+}
+
+// Types of run lifecycle events published by Parser service.
+const (
+	// RunStarted is type of event published when run starts.
+	RunStarted = "run.started"
+	// RunFinished is type of event published when run finishes successfully.
+	RunFinished = "run.finished"
+	// RunFailed is type of event published when run finishes with any other status than succeeded.
+	RunFailed = "run.failed"
+	// RunSkipped is type of event published when parse command is skipped because shop's run is already running.
+	RunSkipped = "run.skipped"
+)
+
+// RunEvent is event published by Parser service when shop's run starts, finishes, fails or is skipped.
+type RunEvent struct {
+	Type    string `json:"type"`
+	ShopID  int    `json:"shopId"`
+	ShopURL string `json:"shopUrl"`
+	// Run is started or finished run with its statistics, it's not set for skipped runs.
+	Run *Run `json:"run,omitempty"`
+}
+
+// Run is shop's run with its statistics.
+type Run struct {
+	ID         int        `json:"id"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Status is one of running, succeeded, failed, suspicious_shrink and cancelled.
+	Status            string  `json:"status"`
+	StatusMessage     *string `json:"statusMessage,omitempty"`
+	IsSuccess         *bool   `json:"isSuccess,omitempty"`
+	IsIncremental     bool    `json:"isIncremental"`
+	IsDryRun          bool    `json:"isDryRun"`
+	ProductsVersion   int64   `json:"productsVersion"`
+	CreatedProducts   *int32  `json:"createdProducts,omitempty"`
+	UpdatedProducts   *int32  `json:"updatedProducts,omitempty"`
+	UnchangedProducts *int32  `json:"unchangedProducts,omitempty"`
+	DeletedProducts   *int32  `json:"deletedProducts,omitempty"`
+	FailedProducts    *int32  `json:"failedProducts,omitempty"`
+}
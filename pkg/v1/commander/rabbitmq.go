@@ -9,6 +9,16 @@ type RabbitMQPublisher interface {
 	Publish(context.Context, string, []byte) error
 }
 
+//go:generate mockery --name RabbitMQRequester --filename rabbitmqrequester.go
+
+// RabbitMQRequester is RabbitMQ messages publisher which can also wait for replies.
+// RabbitMQSender supports replies only if its publisher implements RabbitMQRequester.
+type RabbitMQRequester interface {
+	RabbitMQPublisher
+	// Request publishes message and waits for reply.
+	Request(context.Context, string, []byte) ([]byte, error)
+}
+
 // RabbitMQSender sends RMQ messages to routing key.
 type RabbitMQSender struct {
 	publisher     RabbitMQPublisher
@@ -27,3 +37,14 @@ func NewRabbitMQSender(publisher RabbitMQPublisher, cmdRoutingKey string) Rabbit
 func (s RabbitMQSender) Send(ctx context.Context, msg []byte) error {
 	return s.publisher.Publish(ctx, s.cmdRoutingKey, msg)
 }
+
+// Request sends message to RabbitMQSender's routing key and waits for reply.
+// It returns ErrRepliesNotSupported if sender's publisher doesn't implement RabbitMQRequester.
+func (s RabbitMQSender) Request(ctx context.Context, msg []byte) ([]byte, error) {
+	requester, ok := s.publisher.(RabbitMQRequester)
+	if !ok {
+		return nil, ErrRepliesNotSupported
+	}
+
+	return requester.Request(ctx, s.cmdRoutingKey, msg)
+}
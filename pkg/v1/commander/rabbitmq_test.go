@@ -41,3 +41,46 @@ func TestUniRabbitMQSenderSend(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitRabbitMQSenderRequest(t *testing.T) {
+	body := []byte(fmt.Sprintf(`{"shopUrl":"%s"}`, faker.Word()))
+	reply := []byte(`{"status":"succeeded"}`)
+	routingKey := faker.Word()
+
+	tests := map[string]struct {
+		publisherError error
+		wantReply      []byte
+		wantErr        error
+	}{
+		"ok": {
+			wantReply: reply,
+		},
+		"publisher error": {
+			publisherError: assert.AnError,
+			wantErr:        assert.AnError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			publisher := mocks.NewRabbitMQRequester(t)
+			publisher.On("Request", mock.Anything, routingKey, body).Return(tt.wantReply, tt.publisherError)
+
+			sender := commander.NewRabbitMQSender(publisher, routingKey)
+			got, err := sender.Request(context.TODO(), body)
+
+			require.ErrorIs(t, err, tt.wantErr, "should return correct error")
+			assert.Equal(t, tt.wantReply, got, "should return correct reply")
+		})
+	}
+}
+
+func TestUnitRabbitMQSenderRequestWithoutRequester(t *testing.T) {
+	publisher := mocks.NewRabbitMQPublisher(t)
+
+	sender := commander.NewRabbitMQSender(publisher, faker.Word())
+	reply, err := sender.Request(context.TODO(), []byte(faker.Word()))
+
+	require.ErrorIs(t, err, commander.ErrRepliesNotSupported, "should return correct error")
+	assert.Nil(t, reply, "shouldn't return reply")
+}